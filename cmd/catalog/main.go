@@ -0,0 +1,120 @@
+// Command catalog imports and exports the product catalog from the command
+// line, using the same formats as the admin endpoints.
+//
+//	catalog import [-dry-run] [-format csv|json] <file>
+//	catalog export [-format csv|json] [-out file]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	config "github.com/wafi04/backend/config/development"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	productRepository "github.com/wafi04/backend/services/product/repository"
+	productservice "github.com/wafi04/backend/services/product/service"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	if err := config.LoadConfig("development"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+	}
+	db, err := config.NewDB()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Database connection failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	service := productservice.NewProductService(productRepository.NewProductRepository(db.DB))
+	ctx := context.Background()
+
+	switch os.Args[1] {
+	case "import":
+		err = runImport(ctx, service, os.Args[2:])
+	case "export":
+		err = runExport(ctx, service, os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: catalog import [-dry-run] [-format csv|json] <file>")
+	fmt.Fprintln(os.Stderr, "       catalog export [-format csv|json] [-out file]")
+	os.Exit(2)
+}
+
+func runImport(ctx context.Context, service *productservice.ProductService, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "validate without writing")
+	format := fs.String("format", "", "csv or json (default: from file extension)")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		usage()
+	}
+
+	path := fs.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	job, err := service.ImportCatalog(ctx, &request.ImportCatalogRequest{
+		Format: *format,
+		DryRun: *dryRun,
+		Wait:   true,
+	}, file)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range job.Errors {
+		fmt.Fprintf(os.Stderr, "row %d %s: %s\n", e.Row, e.SKU, e.Message)
+	}
+	fmt.Printf("import %s (%s): %d products, %d created, %d updated, %d failed\n",
+		job.ID, job.Status, job.Total, job.Created, job.Updated, job.Failed)
+	if job.Status != types.ImportStatusCompleted {
+		return fmt.Errorf("import failed")
+	}
+	return nil
+}
+
+func runExport(ctx context.Context, service *productservice.ProductService, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", types.CatalogFormatCSV, "csv or json")
+	out := fs.String("out", "", "output file (default: stdout)")
+	fs.Parse(args)
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	return service.ExportCatalog(ctx, *format, w)
+}
@@ -1,24 +1,37 @@
 package main
 
 import (
-	"github.com/cloudinary/cloudinary-go/v2"
+	"context"
+	"strconv"
+	"time"
+
 	_ "github.com/jackc/pgx/v5/stdlib"
 	config "github.com/wafi04/backend/config/development"
 	authhandler "github.com/wafi04/backend/services/auth/handler"
 	authrepo "github.com/wafi04/backend/services/auth/repository"
 	authservice "github.com/wafi04/backend/services/auth/service"
+	brandhandler "github.com/wafi04/backend/services/brand/handler"
+	brand "github.com/wafi04/backend/services/brand/repository"
+	brandservice "github.com/wafi04/backend/services/brand/service"
 	"github.com/wafi04/backend/services/cart"
 	categoryhandler "github.com/wafi04/backend/services/category/handler"
 	category "github.com/wafi04/backend/services/category/repository"
 	"github.com/wafi04/backend/services/category/service"
+	collectionhandler "github.com/wafi04/backend/services/collection/handler"
+	collection "github.com/wafi04/backend/services/collection/repository"
+	collectionservice "github.com/wafi04/backend/services/collection/service"
 	"github.com/wafi04/backend/services/files"
 	"github.com/wafi04/backend/services/inventory"
+	"github.com/wafi04/backend/services/media"
 	producthandler "github.com/wafi04/backend/services/product/handler"
 	productRepository "github.com/wafi04/backend/services/product/repository"
 	productservice "github.com/wafi04/backend/services/product/service"
+	"github.com/wafi04/backend/services/upload"
 	"github.com/wafi04/backend/services/user"
 
 	"github.com/wafi04/backend/pkg/logger"
+	"github.com/wafi04/backend/pkg/middleware"
+	"github.com/wafi04/backend/pkg/scheduler"
 
 	"github.com/wafi04/backend/pkg/server"
 )
@@ -36,15 +49,22 @@ func main() {
 	}
 	defer db.Close()
 
-	cld, err := cloudinary.NewFromParams(
-		config.LoadEnv("CLOUDINARY_CLOUD_NAME"),
-		config.LoadEnv("CLOUDINARY_API_KEY"),
-		config.LoadEnv("CLOUDINARY_API_SECRET"),
-	)
+	jwtKeys, err := middleware.LoadKeySetFromEnv()
+	if err != nil {
+		log.Log(logger.ErrorLevel, "Failed to load JWT keys: %v", err)
+		return
+	}
+	middleware.SetKeySet(jwtKeys)
+
+	storageConfig := files.ConfigFromEnv()
+	storageDriver, err := files.NewDriver(storageConfig)
 	if err != nil {
-		log.Log(logger.ErrorLevel, "Failed to initialize Cloudinary: %v", err)
+		log.Log(logger.ErrorLevel, "Failed to initialize file storage: %v", err)
 		return
 	}
+	mediaRepo := media.NewMediaRepository(db.DB)
+	assetStorage := media.NewDedupStorage(storageDriver, mediaRepo)
+	filesService := files.WithValidation(assetStorage, files.DefaultUploadRules)
 
 	// Check database health
 	health := db.Health()
@@ -54,6 +74,10 @@ func main() {
 	userService := authservice.NewAuthService(userRepo)
 	categoryRepo := category.NewCategoryRepository(db.DB)
 	categoryService := service.NewCategoryService(categoryRepo)
+	brandRepo := brand.NewBrandRepository(db.DB)
+	brandService := brandservice.NewBrandService(brandRepo)
+	collectionRepo := collection.NewCollectionRepository(db.DB)
+	collectionService := collectionservice.NewCollectionService(collectionRepo)
 	productrepo := productRepository.NewProductRepository(db.DB)
 	productservice := productservice.NewProductService(productrepo)
 	inventoryrepo := inventory.NewInventoryRepository(db.DB)
@@ -62,20 +86,80 @@ func main() {
 	cartService := cart.NewCartService(cartrepo)
 	userrepos := user.NewUserRepository(db.DB)
 	shipAddrrepo := user.NewShippingAddressRepo(db.DB)
+	notificationRepo := user.NewNotificationRepo(db.DB)
 
 	userHandler := user.NewUserHandler(userrepos)
-	filesService := files.NewCloudinaryService(cld)
 	authHandler := authhandler.NewAuthHandler(userService)
 	categoryhandler := categoryhandler.NewCategoryHandler(categoryService, filesService)
-	producthandler := producthandler.NewProductHandler(productservice, filesService)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cloudinary resizes on delivery; other drivers get derivatives built
+	// at upload time.
+	var derivativePool *files.DerivativePool
+	if storageConfig.Driver != files.DriverCloudinary {
+		derivativePool, err = files.NewDerivativePool(assetStorage, files.DerivativeConfigFromEnv(), productservice.SaveImageDerivatives)
+		if err != nil {
+			log.Log(logger.ErrorLevel, "Failed to initialize image derivatives: %v", err)
+			return
+		}
+		go derivativePool.Run(ctx)
+	}
+
+	producthandler := producthandler.NewProductHandler(productservice, filesService, derivativePool)
 	inventoryHandler := inventory.NewInventoryHandler(inventoryService)
 	cartHandler := cart.NewCartHandler(cartService)
 	shiphnadler := user.NewShippingHandler(shipAddrrepo)
+	notificationHandler := user.NewNotificationHandler(notificationRepo)
+	brandHandler := brandhandler.NewBrandHandler(brandService, filesService)
+	collectionHandler := collectionhandler.NewCollectionHandler(collectionService, filesService)
+	uploadService := upload.NewUploadService(upload.NewUploadRepository(db.DB), mediaRepo, storageDriver)
+	uploadHandler := upload.NewUploadHandler(uploadService)
+
+	go scheduler.Every(ctx, time.Minute, "product-schedule", productservice.ApplySchedule)
+	go scheduler.Every(ctx, time.Minute, "price-schedule", productservice.ApplyPriceSchedule)
+
+	go scheduler.Every(ctx, 6*time.Hour, "recommendations", productservice.RecomputeSimilarities)
 
-	router := server.Allroutes(authHandler, userHandler, categoryhandler, producthandler, inventoryHandler, cartHandler, shiphnadler)
+	retention := trashRetention()
+	go scheduler.Every(ctx, time.Hour, "trash-purge", func(ctx context.Context) error {
+		if err := productservice.PurgeTrash(ctx, retention); err != nil {
+			return err
+		}
+		return categoryService.PurgeTrash(ctx, retention)
+	})
+
+	collector := media.NewCollector(mediaRepo, storageDriver, mediaGracePeriod())
+	go scheduler.Every(ctx, time.Hour, "media-gc", collector.Collect)
+	go scheduler.Every(ctx, time.Hour, "upload-intents", uploadService.ExpireIntents)
+
+	router := server.Allroutes(authHandler, userHandler, categoryhandler, producthandler, inventoryHandler, cartHandler, shiphnadler, notificationHandler, brandHandler, collectionHandler, uploadHandler)
+
+	if storageConfig.Driver == files.DriverLocal {
+		router.Static(files.StaticPrefix, storageConfig.LocalDir)
+	}
 
 	log.Info("Starting server on : %s", config.LoadEnv("PORT"))
 	if err := router.Run(":8080"); err != nil {
 		log.Log(logger.ErrorLevel, "Failed to start server: %s", err)
 	}
 }
+
+// mediaGracePeriod reads MEDIA_GRACE_DAYS, defaulting to 7 days. Files
+// that have been unreferenced for this long are deleted from storage.
+func mediaGracePeriod() time.Duration {
+	days, err := strconv.Atoi(config.LoadEnv("MEDIA_GRACE_DAYS"))
+	if err != nil || days <= 0 {
+		days = 7
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// trashRetention reads TRASH_RETENTION_DAYS, defaulting to 30 days.
+func trashRetention() time.Duration {
+	days, err := strconv.Atoi(config.LoadEnv("TRASH_RETENTION_DAYS"))
+	if err != nil || days <= 0 {
+		days = 30
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
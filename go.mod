@@ -1,8 +1,9 @@
 module github.com/wafi04/backend
 
-go 1.22.0
+go 1.22.2
 
 require (
+	github.com/HugoSmits86/nativewebp v1.2.1
 	github.com/cloudinary/cloudinary-go/v2 v2.9.1
 	github.com/gin-contrib/cors v1.7.3
 	github.com/gin-gonic/gin v1.10.0
@@ -14,6 +15,7 @@ require (
 	github.com/lib/pq v1.10.9
 	github.com/wafi04/shared v0.0.0-20250116124558-f6dedf29cbd0
 	golang.org/x/crypto v0.32.0
+	golang.org/x/image v0.24.0
 )
 
 require (
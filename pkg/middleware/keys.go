@@ -0,0 +1,259 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	config "github.com/wafi04/backend/config/development"
+)
+
+// Key is one JWT key, identified by the kid header of the tokens it signs.
+// Private is nil for a key that only verifies tokens issued before a
+// rotation.
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.PrivateKey
+	Public  crypto.PublicKey
+}
+
+// KeySet signs new tokens with one key and verifies tokens signed by any of
+// its keys, so a retired key keeps working until its tokens expire.
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+func NewKeySet(signingID string, keys ...*Key) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*Key, len(keys))}
+	for _, k := range keys {
+		if _, dup := ks.keys[k.ID]; dup {
+			return nil, fmt.Errorf("duplicate jwt key id %q", k.ID)
+		}
+		ks.keys[k.ID] = k
+	}
+
+	signing, ok := ks.keys[signingID]
+	if !ok {
+		return nil, fmt.Errorf("jwt signing key %q not found", signingID)
+	}
+	if signing.Private == nil {
+		return nil, fmt.Errorf("jwt signing key %q has no private key", signingID)
+	}
+	ks.signing = signing
+	return ks, nil
+}
+
+// ParseKey reads a PEM encoded RSA or Ed25519 key. Private keys (PKCS#1 or
+// PKCS#8) can sign; public keys (PKIX) only verify. RSA keys sign RS256 and
+// must be at least 2048 bits; Ed25519 keys sign EdDSA.
+func ParseKey(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("jwt key %q is not PEM encoded", id)
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("jwt key %q has unsupported PEM type %s", id, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse jwt key %q: %v", id, err)
+	}
+
+	key := &Key{ID: id}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.Private, key.Public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.Public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.Private, key.Public = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.Public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("jwt key %q must be RSA or Ed25519, got %T", id, parsed)
+	}
+
+	if pub, ok := key.Public.(*rsa.PublicKey); ok && pub.N.BitLen() < 2048 {
+		return nil, fmt.Errorf("jwt key %q is %d bits, RSA keys must be at least 2048", id, pub.N.BitLen())
+	}
+	return key, nil
+}
+
+// LoadKeySetFromEnv loads every *.pem file in JWT_KEYS_DIR, using the file
+// name without extension as the kid, and signs with JWT_SIGNING_KEY_ID.
+// Without JWT_KEYS_DIR it falls back to a key generated at startup, which
+// is only good for development: tokens stop verifying on restart.
+func LoadKeySetFromEnv() (*KeySet, error) {
+	dir := config.LoadEnv("JWT_KEYS_DIR")
+	if dir == "" {
+		log.Printf("Warning: JWT_KEYS_DIR not set, signing tokens with a temporary key")
+		return NewEphemeralKeySet()
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jwt keys: %v", err)
+	}
+	keys := make([]*Key, 0, len(paths))
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jwt key: %v", err)
+		}
+		key, err := ParseKey(strings.TrimSuffix(filepath.Base(p), ".pem"), data)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return NewKeySet(config.LoadEnv("JWT_SIGNING_KEY_ID"), keys...)
+}
+
+// NewEphemeralKeySet returns a key set with one freshly generated Ed25519
+// key.
+func NewEphemeralKeySet() (*KeySet, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate jwt key: %v", err)
+	}
+	id := fmt.Sprintf("dev-%x", priv.Public().(ed25519.PublicKey)[:4])
+	return NewKeySet(id, &Key{
+		ID:      id,
+		Method:  jwt.SigningMethodEdDSA,
+		Private: priv,
+		Public:  priv.Public(),
+	})
+}
+
+var (
+	keySetMu sync.RWMutex
+	keySet   *KeySet
+)
+
+// SetKeySet replaces the keys GenerateToken and ValidateToken use.
+func SetKeySet(ks *KeySet) {
+	keySetMu.Lock()
+	defer keySetMu.Unlock()
+	keySet = ks
+}
+
+// currentKeySet returns the configured keys, generating temporary ones on
+// first use when SetKeySet was never called.
+func currentKeySet() (*KeySet, error) {
+	keySetMu.RLock()
+	ks := keySet
+	keySetMu.RUnlock()
+	if ks != nil {
+		return ks, nil
+	}
+
+	keySetMu.Lock()
+	defer keySetMu.Unlock()
+	if keySet == nil {
+		generated, err := NewEphemeralKeySet()
+		if err != nil {
+			return nil, err
+		}
+		keySet = generated
+	}
+	return keySet, nil
+}
+
+// Sign signs claims with the signing key and sets its kid header.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	token.Header["kid"] = ks.signing.ID
+	return token.SignedString(ks.signing.Private)
+}
+
+// Keyfunc picks the verification key named by the token's kid. The
+// token's alg must be the one that key signs with, so a public key can
+// never be used as an HMAC secret.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.Public, nil
+}
+
+// JWK is the public part of one key, as published in the JWKS document.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS lists the public keys of every key in the set, ordered by kid.
+func (ks *KeySet) JWKS() *JWKS {
+	set := &JWKS{Keys: make([]JWK, 0, len(ks.keys))}
+	for _, key := range ks.keys {
+		jwk := JWK{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+		switch pub := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+// JWKSHandler serves /.well-known/jwks.json for services that verify our
+// tokens.
+func JWKSHandler(c *gin.Context) {
+	ks, err := currentKeySet()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "keys unavailable"})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, ks.JWKS())
+}
@@ -12,8 +12,6 @@ import (
 	"github.com/wafi04/backend/pkg/types"
 )
 
-var jwtSecretKey = []byte("jsjxakabxjaigisyqyg189")
-
 type JWTClaims struct {
 	UserID          string `json:"user_id"`
 	Email           string `json:"email"`
@@ -25,13 +23,12 @@ type JWTClaims struct {
 }
 
 func ValidateToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return jwtSecretKey, nil
-	})
+	ks, err := currentKeySet()
+	if err != nil {
+		return nil, err
+	}
 
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, ks.Keyfunc)
 	if err != nil {
 		return nil, err
 	}
@@ -44,6 +41,11 @@ func ValidateToken(tokenString string) (*JWTClaims, error) {
 	return claims, nil
 }
 func GenerateToken(user *types.UserInfo, day int64) (string, error) {
+	ks, err := currentKeySet()
+	if err != nil {
+		return "", err
+	}
+
 	claims := JWTClaims{
 		UserID:          user.UserID,
 		Email:           user.Email,
@@ -57,8 +59,7 @@ func GenerateToken(user *types.UserInfo, day int64) (string, error) {
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString(jwtSecretKey)
+	signedToken, err := ks.Sign(claims)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -106,42 +107,12 @@ func AuthMiddleware() gin.HandlerFunc {
 			}
 		}
 
-		refreshToken, err := c.Cookie("refresh_token")
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "No valid tokens found",
-			})
-			return
-		}
-
-		claims, err := ValidateToken(refreshToken)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid refresh token",
-			})
-			return
-		}
-
-		user := &types.UserInfo{
-			UserID:          claims.UserID,
-			Email:           claims.Email,
-			Name:            claims.Name,
-			Role:            claims.Role,
-			IsEmailVerified: claims.IsEmailVerified,
-		}
-
-		newAccessToken, err := GenerateToken(user, 24)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to generate new access token",
-			})
-			return
-		}
-
-		c.Header("New-Access-Token", newAccessToken)
-
-		c.Set(string(UserContextKey), user)
-		c.Next()
+		// Refresh tokens are opaque and checked against the database, so
+		// they cannot stand in for an access token here. Clients exchange
+		// them at /user/refresh-token.
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": "No valid tokens found",
+		})
 	}
 }
 
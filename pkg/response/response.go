@@ -1,10 +1,13 @@
 package httpresponse
 
 import (
+	"errors"
 	"log"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	response "github.com/wafi04/backend/pkg/types/res"
 )
 
 type Response struct {
@@ -112,3 +115,24 @@ func ResponseTimeMiddleware() gin.HandlerFunc {
 		log.Printf("Response time for %s %s: %v\n", c.Request.Method, c.Request.URL.Path, duration)
 	}
 }
+
+type FileErrorResponse struct {
+	Status  int                           `json:"status"`
+	Message string                        `json:"message"`
+	Error   *response.FileValidationError `json:"error"`
+}
+
+// SendUploadError reports a rejected upload as 422 with the validation
+// details, and any other upload failure with the given status and message.
+func SendUploadError(c *gin.Context, err error, statusCode int, message string) {
+	var validationErr *response.FileValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusUnprocessableEntity, FileErrorResponse{
+			Status:  http.StatusUnprocessableEntity,
+			Message: "Invalid File",
+			Error:   validationErr,
+		})
+		return
+	}
+	SendErrorResponse(c, statusCode, message)
+}
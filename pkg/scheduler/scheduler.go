@@ -0,0 +1,30 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/wafi04/backend/pkg/logger"
+)
+
+type Job func(ctx context.Context) error
+
+// Every runs job once per interval until ctx is cancelled. Failures are
+// logged and the job is retried on the next tick.
+func Every(ctx context.Context, interval time.Duration, name string, job Job) {
+	log := logger.NewLogger()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Log(logger.InfoLevel, "Stopping job %s", name)
+			return
+		case <-ticker.C:
+			if err := job(ctx); err != nil {
+				log.Log(logger.ErrorLevel, "Job %s failed: %v", name, err)
+			}
+		}
+	}
+}
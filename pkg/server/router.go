@@ -4,10 +4,13 @@ import (
 	"github.com/gin-gonic/gin"
 	config "github.com/wafi04/backend/config/development"
 	authhandler "github.com/wafi04/backend/services/auth/handler"
+	brandhandler "github.com/wafi04/backend/services/brand/handler"
 	"github.com/wafi04/backend/services/cart"
 	categoryhandler "github.com/wafi04/backend/services/category/handler"
+	collectionhandler "github.com/wafi04/backend/services/collection/handler"
 	"github.com/wafi04/backend/services/inventory"
 	producthandler "github.com/wafi04/backend/services/product/handler"
+	"github.com/wafi04/backend/services/upload"
 	"github.com/wafi04/backend/services/user"
 
 	"github.com/wafi04/backend/pkg/middleware"
@@ -24,6 +27,10 @@ func Allroutes(
 	inventoryhandler *inventory.InventoryHandler,
 	carthandler *cart.CartHandler,
 	shippingHandler *user.ShippingHandler,
+	notificationHandler *user.NotificationHandler,
+	brandHandler *brandhandler.BrandHandler,
+	collectionHandler *collectionhandler.CollectionHandler,
+	uploadHandler *upload.UploadHandler,
 ) *gin.Engine {
 	gin.SetMode(gin.DebugMode)
 
@@ -37,6 +44,7 @@ func Allroutes(
 	types.Broadcast <- "Hello, WebSocket clients!"
 
 	r.GET("/health", utils.ConnectionHealthy)
+	r.GET("/.well-known/jwks.json", middleware.JWKSHandler)
 
 	public := r.Group("/api/v1")
 	{
@@ -44,8 +52,25 @@ func Allroutes(
 		{
 			auth.POST("/register", authHandler.CreateUser)
 			auth.POST("/login", authHandler.Login)
-
+			auth.POST("/forgot-password", authHandler.ForgotPassword)
+			auth.POST("/reset-password", authHandler.ResetPassword)
 		}
+		// Public so that it still works once the access token has expired.
+		public.POST("/user/refresh-token", authHandler.RefreshToken)
+		public.GET("/product/by-slug/:slug", producthandler.HandleGetProductBySlug)
+		public.GET("/category/by-slug/:slug", categoryHandler.HandleGetCategoryBySlug)
+		public.GET("/category/:id/breadcrumb", categoryHandler.HandleGetBreadcrumb)
+		public.GET("/category/:id/products", categoryHandler.HandleListCategoryProducts)
+		public.GET("/product/:id/reviews", producthandler.HandleListProductReviews)
+		public.GET("/product/:id/questions", producthandler.HandleListProductQuestions)
+		public.GET("/product/:id/related", producthandler.HandleGetRelatedProducts)
+		public.GET("/product/:id/price-history", producthandler.HandleGetPriceHistory)
+		public.GET("/brands", brandHandler.HandleListBrands)
+		public.GET("/brands/:slug", brandHandler.HandleGetBrandBySlug)
+		public.GET("/collections", collectionHandler.HandleListCollections)
+		public.GET("/collections/:slug", collectionHandler.HandleGetCollectionBySlug)
+		public.GET("/tags", producthandler.HandleListTags)
+		public.PUT("/uploads/local/*key", uploadHandler.HandleLocalUpload)
 	}
 
 	protected := r.Group("/api/v1")
@@ -59,7 +84,6 @@ func Allroutes(
 			user.POST("/resend-verification", authHandler.ResendVerification)
 			user.POST("/logout", authHandler.Logout)
 			user.POST("/revoke-session", authHandler.RevokeSession)
-			user.POST("/refresh-token", authHandler.RefreshToken)
 			user.GET("/sessions", authHandler.ListSessions)
 			user.GET("/details", userhandler.GetUserDetails)
 			user.POST("/details", userhandler.HandleCreateUserDetails)
@@ -68,6 +92,9 @@ func Allroutes(
 			user.GET("/address", shippingHandler.GetAll)
 			user.PATCH("/address/:id", shippingHandler.UpdateShipping)
 			user.DELETE("/address/:id", shippingHandler.DeleteShipping)
+			user.GET("/notifications", notificationHandler.GetAll)
+			user.POST("/notifications/read", notificationHandler.MarkAllRead)
+			user.POST("/notifications/:id/read", notificationHandler.MarkRead)
 
 		}
 		category := protected.Group("/category")
@@ -76,6 +103,9 @@ func Allroutes(
 			category.POST("", categoryHandler.HandleCreateCategory)
 			category.PUT("/update/:id", categoryHandler.HandleUpdateCategory)
 			category.DELETE("/:id", categoryHandler.HandleDeleteCategory)
+			category.GET("/:id/delete-preview", categoryHandler.HandleDeleteCategoryPreview)
+			category.POST("/:id/move", categoryHandler.HandleMoveCategory)
+			category.POST("/reorder", categoryHandler.HandleReorderCategories)
 		}
 		product := protected.Group("/product")
 		{
@@ -95,6 +125,61 @@ func Allroutes(
 			// images
 			product.POST("/:id/variant/images", producthandler.HandleAddProductImage)
 			product.DELETE("/:id/variant/images", producthandler.HandleDeleteProductImage)
+			product.POST("/:id/variant/images/bulk", producthandler.HandleBulkAddProductImages)
+			product.PUT("/:id/variant/images/order", producthandler.HandleReorderProductImages)
+			product.PATCH("/:id/variant/images/:imageId", producthandler.HandleUpdateProductImage)
+			product.PUT("/:id/variant/images/:imageId/main", producthandler.HandleSetMainProductImage)
+
+			// videos and documents
+			product.POST("/:id/videos", producthandler.HandleAddProductVideo)
+			product.DELETE("/:id/videos/:videoId", producthandler.HandleDeleteProductVideo)
+			product.POST("/:id/documents", producthandler.HandleAddProductDocument)
+			product.DELETE("/:id/documents/:documentId", producthandler.HandleDeleteProductDocument)
+
+			// reviews
+			product.POST("/:id/reviews", producthandler.HandleCreateReview)
+			product.POST("/reviews/:id/helpful", producthandler.HandleMarkReviewHelpful)
+
+			// questions and answers
+			product.POST("/:id/questions", producthandler.HandleCreateQuestion)
+			product.POST("/questions/:id/answers", producthandler.HandleCreateAnswer)
+			product.POST("/answers/:id/upvote", producthandler.HandleUpvoteAnswer)
+		}
+		admin := protected.Group("/admin")
+		admin.Use(middleware.RoleMiddleware("admin"))
+		{
+			admin.GET("/product/all", producthandler.HandleListAllProducts)
+			admin.PATCH("/product/:id/status", producthandler.HandleUpdateProductStatus)
+			admin.GET("/product/trash", producthandler.HandleListTrash)
+			admin.POST("/product/trash/:id/restore", producthandler.HandleRestoreFromTrash)
+			admin.GET("/category/trash", categoryHandler.HandleListTrash)
+			admin.POST("/category/trash/:id/restore", categoryHandler.HandleRestoreCategory)
+			admin.POST("/catalog/import", producthandler.HandleImportCatalog)
+			admin.GET("/catalog/import/:id", producthandler.HandleGetImportJob)
+			admin.GET("/catalog/export", producthandler.HandleExportCatalog)
+			admin.GET("/reviews", producthandler.HandleListReviewQueue)
+			admin.PATCH("/reviews/:id", producthandler.HandleModerateReview)
+			admin.GET("/questions", producthandler.HandleListQuestionQueue)
+			admin.PATCH("/questions/:id", producthandler.HandleModerateQuestion)
+			admin.GET("/answers", producthandler.HandleListAnswerQueue)
+			admin.PATCH("/answers/:id", producthandler.HandleModerateAnswer)
+			admin.GET("/product/:id/related/overrides", producthandler.HandleListRecommendationOverrides)
+			admin.PUT("/product/:id/related/overrides", producthandler.HandleSetRecommendationOverride)
+			admin.DELETE("/product/:id/related/overrides/:relatedId", producthandler.HandleDeleteRecommendationOverride)
+			admin.POST("/recommendations/recompute", producthandler.HandleRecomputeSimilarities)
+			admin.GET("/product/:id/price-changes", producthandler.HandleListPriceChanges)
+			admin.POST("/product/:id/price-changes", producthandler.HandleSchedulePriceChange)
+			admin.DELETE("/price-changes/:id", producthandler.HandleCancelPriceChange)
+			admin.PUT("/product/:id/bundle-items", producthandler.HandleSetBundleItems)
+			admin.POST("/product/:id/tags", producthandler.HandleAddProductTags)
+			admin.DELETE("/product/:id/tags/:slug", producthandler.HandleRemoveProductTag)
+			admin.POST("/brands", brandHandler.HandleCreateBrand)
+			admin.PUT("/brands/:id", brandHandler.HandleUpdateBrand)
+			admin.DELETE("/brands/:id", brandHandler.HandleDeleteBrand)
+			admin.POST("/collections", collectionHandler.HandleCreateCollection)
+			admin.PUT("/collections/:id", collectionHandler.HandleUpdateCollection)
+			admin.DELETE("/collections/:id", collectionHandler.HandleDeleteCollection)
+			admin.PUT("/collections/:id/products", collectionHandler.HandleSetCollectionProducts)
 		}
 		inv := protected.Group("/stock")
 		{
@@ -105,11 +190,18 @@ func Allroutes(
 		{
 			cart.POST("", carthandler.HandleAddToCart)
 			cart.GET("", carthandler.HandleGetCart)
+			cart.GET("/recommendations", producthandler.HandleGetCartRecommendations)
 			cart.DELETE("/clear", carthandler.ClearCart)
 			cart.PATCH("/items/:id", carthandler.UpdateQuantity)
 			cart.DELETE("/items/:id", carthandler.RemoveFromCart)
 		}
 
+		uploads := protected.Group("/uploads")
+		{
+			uploads.POST("/intents", uploadHandler.HandleCreateUploadIntent)
+			uploads.POST("/intents/:id/confirm", uploadHandler.HandleConfirmUpload)
+		}
+
 	}
 
 	return r
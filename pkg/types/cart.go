@@ -15,6 +15,7 @@ type CartItem struct {
 	CartItemID  string  `db:"cart_item_id" json:"cart_item_id"`
 	CartID      string  `db:"cart_id" json:"cart_id"`
 	VariantID   string  `db:"variant_id" json:"variant_id"`
+	BundleID    *string `db:"bundle_id" json:"bundle_id,omitempty"`
 	Size        string  `db:"size" json:"size"`
 	Quantity    int64   `db:"quantity" json:"quantity"`
 	SubTotal    float64 `db:"sub_total" json:"sub_total"`
@@ -24,4 +25,5 @@ type CartItem struct {
 	Color       *string `json:"color,omitempty"`
 	SKU         *string `json:"sku,omitempty"`
 	ProductName *string `json:"product_name"`
+	Unavailable bool    `json:"unavailable"`
 }
@@ -0,0 +1,61 @@
+package types
+
+import "time"
+
+const (
+	CatalogFormatCSV  = "csv"
+	CatalogFormatJSON = "json"
+
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+)
+
+// CatalogProduct is the import/export representation of a product. Products,
+// variants and inventory lines are matched by SKU and size, not by ID.
+type CatalogProduct struct {
+	SKU         string            `json:"sku"`
+	Name        string            `json:"name"`
+	SubTitle    string            `json:"sub_title,omitempty"`
+	Description string            `json:"description"`
+	Price       float64           `json:"price"`
+	CategoryID  string            `json:"category_id"`
+	Status      string            `json:"status,omitempty"`
+	PublishAt   *int64            `json:"publish_at,omitempty"`
+	UnpublishAt *int64            `json:"unpublish_at,omitempty"`
+	Variants    []*CatalogVariant `json:"variants,omitempty"`
+	Row         int               `json:"-"`
+}
+
+type CatalogVariant struct {
+	SKU       string              `json:"sku"`
+	Color     string              `json:"color"`
+	Images    []string            `json:"images,omitempty"`
+	Inventory []*CatalogInventory `json:"inventory,omitempty"`
+}
+
+type CatalogInventory struct {
+	Size  string `json:"size"`
+	Stock int    `json:"stock"`
+}
+
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	SKU     string `json:"sku,omitempty"`
+	Message string `json:"message"`
+}
+
+type ImportJob struct {
+	ID         string           `json:"id"`
+	Format     string           `json:"format"`
+	Status     string           `json:"status"`
+	DryRun     bool             `json:"dry_run"`
+	Total      int              `json:"total"`
+	Processed  int              `json:"processed"`
+	Created    int              `json:"created"`
+	Updated    int              `json:"updated"`
+	Failed     int              `json:"failed"`
+	Errors     []ImportRowError `json:"errors"`
+	CreatedAt  time.Time        `json:"created_at"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+}
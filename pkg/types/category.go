@@ -6,14 +6,29 @@ import (
 	"github.com/lib/pq"
 )
 
+// Strategies for what DeleteCategory does with a category's children and
+// products.
+const (
+	CategoryDeleteReject         = "reject"
+	CategoryDeleteCascade        = "cascade"
+	CategoryDeleteReassignParent = "reassign_parent"
+	CategoryDeleteReassignTarget = "reassign_target"
+)
+
 type Category struct {
-	ID          string         `json:"id"`
-	Name        string         `json:"name"`
-	Description string         `json:"description"`
-	Image       *string        `json:"image,omitempty"`
-	Depth       int32          `json:"depth"`
-	ParentID    *string        `json:"parent_id,omitempty"`
-	Children    []*Category    `json:"children"`
-	Path        pq.StringArray `json:"-"`
-	CreatedAt   time.Time      `json:"created_at"`
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Slug        string  `json:"slug"`
+	Description string  `json:"description"`
+	Image       *string `json:"image,omitempty"`
+	Depth       int32   `json:"depth"`
+	Position    int32   `json:"position"`
+	ParentID    *string `json:"parent_id,omitempty"`
+	// ProductCount counts published products directly in the category;
+	// TotalProductCount adds those of every descendant.
+	ProductCount      int64          `json:"product_count"`
+	TotalProductCount int64          `json:"total_product_count"`
+	Children          []*Category    `json:"children"`
+	Path              pq.StringArray `json:"-"`
+	CreatedAt         time.Time      `json:"created_at"`
 }
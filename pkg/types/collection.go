@@ -0,0 +1,31 @@
+package types
+
+import "time"
+
+const (
+	CollectionTypeManual = "manual"
+	CollectionTypeRule   = "rule"
+)
+
+// CollectionRules select the members of a rule-based collection. Every rule
+// that is set must match; a product matches the tag rule when it carries any
+// of the tags.
+type CollectionRules struct {
+	Tags        []string `json:"tags,omitempty"`
+	CategoryIDs []string `json:"category_ids,omitempty"`
+	MinPrice    *float64 `json:"min_price,omitempty"`
+	MaxPrice    *float64 `json:"max_price,omitempty"`
+}
+
+type Collection struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Slug        string           `json:"slug"`
+	Description string           `json:"description"`
+	Image       *string          `json:"image,omitempty"`
+	Type        string           `json:"type"`
+	Rules       *CollectionRules `json:"rules,omitempty"`
+	ProductIDs  []string         `json:"product_ids,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
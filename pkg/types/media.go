@@ -0,0 +1,19 @@
+package types
+
+import "time"
+
+// MediaAsset is one stored file, shared by every entity that uploaded the
+// same content.
+type MediaAsset struct {
+	ID          string `json:"id"`
+	Hash        string `json:"hash"`
+	PublicID    string `json:"public_id"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	RefCount    int    `json:"ref_count"`
+	// UnreferencedAt is when the asset was last seen without owners; the
+	// garbage collector deletes it once the grace period has passed.
+	UnreferencedAt *time.Time `json:"unreferenced_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
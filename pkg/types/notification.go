@@ -0,0 +1,21 @@
+package types
+
+const (
+	NotificationQuestionAnswered = "question_answered"
+)
+
+type Notification struct {
+	ID        string `json:"id"`
+	UserID    string `json:"user_id"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Message   string `json:"message"`
+	EntityID  string `json:"entity_id,omitempty"`
+	IsRead    bool   `json:"is_read"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type ListNotifications struct {
+	Notifications []*Notification `json:"notifications"`
+	UnreadCount   int             `json:"unread_count"`
+}
@@ -0,0 +1,43 @@
+package types
+
+const (
+	PriceChangeCreated  = "created"
+	PriceChangeManual   = "manual"
+	PriceChangeImport   = "import"
+	PriceChangeSchedule = "scheduled"
+	PriceChangeRevert   = "schedule_end"
+
+	PriceScheduleActive    = "active"
+	PriceSchedulePending   = "pending"
+	PriceScheduleCompleted = "completed"
+	PriceScheduleCancelled = "cancelled"
+)
+
+// PriceHistoryEntry is one price a product or variant had. EffectiveTo is
+// nil for the current price.
+type PriceHistoryEntry struct {
+	ID            string   `json:"id"`
+	ProductID     string   `json:"product_id"`
+	VariantID     *string  `json:"variant_id,omitempty"`
+	OldPrice      *float64 `json:"old_price,omitempty"`
+	NewPrice      float64  `json:"new_price"`
+	Reason        string   `json:"reason"`
+	ActorID       string   `json:"actor_id,omitempty"`
+	EffectiveFrom int64    `json:"effective_from"`
+	EffectiveTo   *int64   `json:"effective_to,omitempty"`
+}
+
+// ScheduledPriceChange sets a price at StartsAt and, when EndsAt is set,
+// restores the price it replaced at EndsAt.
+type ScheduledPriceChange struct {
+	ID          string   `json:"id"`
+	ProductID   string   `json:"product_id"`
+	VariantID   *string  `json:"variant_id,omitempty"`
+	Price       float64  `json:"price"`
+	StartsAt    int64    `json:"starts_at"`
+	EndsAt      *int64   `json:"ends_at,omitempty"`
+	RevertPrice *float64 `json:"revert_price,omitempty"`
+	Status      string   `json:"status"`
+	CreatedBy   string   `json:"created_by"`
+	CreatedAt   int64    `json:"created_at"`
+}
@@ -1,16 +1,54 @@
 package types
 
+const (
+	ProductStatusDraft     = "draft"
+	ProductStatusReview    = "review"
+	ProductStatusScheduled = "scheduled"
+	ProductStatusPublished = "published"
+	ProductStatusArchived  = "archived"
+)
+
+const (
+	ProductTypeStandard = "standard"
+	ProductTypeBundle   = "bundle"
+)
+
+// Sort keys accepted by ListProducts.
+const (
+	ProductSortNewest    = "newest"
+	ProductSortRating    = "rating"
+	ProductSortReviews   = "reviews"
+	ProductSortPriceAsc  = "price_asc"
+	ProductSortPriceDesc = "price_desc"
+	// ProductSortPosition orders by the manual position within the
+	// collection being listed.
+	ProductSortPosition = "position"
+)
+
 type Product struct {
-	ID          string            `json:"id"`
-	Name        string            `json:"name"`
-	SubTitle    string            `json:"sub_title"`
-	Description string            `json:"description"`
-	SKU         string            `json:"sku"`
-	Price       float64           `json:"price"`
-	Variants    []*ProductVariant `json:"variants,omitempty"`
-	CategoryID  string            `json:"category_id"`
-	CreatedAt   int64             `json:"created_at,omitempty"`
-	UpdatedAt   int64             `json:"updated_at,omitempty"`
+	ID            string             `json:"id"`
+	Name          string             `json:"name"`
+	Slug          string             `json:"slug"`
+	SubTitle      string             `json:"sub_title"`
+	Description   string             `json:"description"`
+	SKU           string             `json:"sku"`
+	Price         float64            `json:"price"`
+	Variants      []*ProductVariant  `json:"variants,omitempty"`
+	Videos        []*ProductVideo    `json:"videos,omitempty"`
+	Documents     []*ProductDocument `json:"documents,omitempty"`
+	CategoryID    string             `json:"category_id"`
+	BrandID       *string            `json:"brand_id,omitempty"`
+	Tags          []*Tag             `json:"tags,omitempty"`
+	Type          string             `json:"type"`
+	BundleItems   []*BundleItem      `json:"bundle_items,omitempty"`
+	Available     *int               `json:"available,omitempty"`
+	Status        string             `json:"status"`
+	PublishAt     *int64             `json:"publish_at,omitempty"`
+	UnpublishAt   *int64             `json:"unpublish_at,omitempty"`
+	CreatedAt     int64              `json:"created_at,omitempty"`
+	UpdatedAt     int64              `json:"updated_at,omitempty"`
+	RatingAverage float64            `json:"rating_average"`
+	RatingCount   int                `json:"rating_count"`
 }
 type Inventory struct {
 	VariantID      string `json:"variant_id" db:"variant_id"`
@@ -23,17 +61,91 @@ type Inventory struct {
 	UpdatedAt      int64  `json:"updated_at" db:"updated_at"`
 }
 type ProductVariant struct {
-	ID        string          `json:"id,omitempty"`
-	Color     string          `json:"color,omitempty"`
-	SKU       string          `json:"sku,omitempty"`
-	Images    []*ProductImage `json:"images,omitempty"`
-	Inventory []*Inventory    `json:"inventory,omitempty"`
-	ProductID string          `json:"product_id,omitempty"`
+	ID        string             `json:"id,omitempty"`
+	Color     string             `json:"color,omitempty"`
+	SKU       string             `json:"sku,omitempty"`
+	Price     *float64           `json:"price,omitempty"`
+	Images    []*ProductImage    `json:"images,omitempty"`
+	Inventory []*Inventory       `json:"inventory,omitempty"`
+	Videos    []*ProductVideo    `json:"videos,omitempty"`
+	Documents []*ProductDocument `json:"documents,omitempty"`
+	ProductID string             `json:"product_id,omitempty"`
 }
 
 type ProductImage struct {
-	ID        string `json:"id,omitempty"`
-	URL       string `json:"url,omitempty"`
-	VariantID string `json:"variant_id,omitempty"`
-	IsMain    bool   `json:"is_main,omitempty"`
+	ID          string             `json:"id,omitempty"`
+	URL         string             `json:"url,omitempty"`
+	VariantID   string             `json:"variant_id,omitempty"`
+	IsMain      bool               `json:"is_main,omitempty"`
+	Position    int32              `json:"position"`
+	AltText     string             `json:"alt_text,omitempty"`
+	Derivatives []*ImageDerivative `json:"derivatives,omitempty"`
+	// Sources groups the derivatives by format, one entry per <source> of a
+	// <picture> element.
+	Sources []*ImageSource `json:"sources,omitempty"`
+}
+
+// ImageDerivative is a resized encode of a product image, such as the
+// thumbnail in JPEG.
+type ImageDerivative struct {
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
+}
+
+type ImageSource struct {
+	Type   string `json:"type"`
+	SrcSet string `json:"srcset"`
+}
+
+// ProductVideo is a video of a product, or of one variant when VariantID
+// is set. Duration is in seconds and zero when the file does not record it.
+type ProductVideo struct {
+	ID        string  `json:"id"`
+	ProductID string  `json:"product_id"`
+	VariantID *string `json:"variant_id,omitempty"`
+	URL       string  `json:"url"`
+	PosterURL string  `json:"poster_url,omitempty"`
+	Title     string  `json:"title,omitempty"`
+	Duration  float64 `json:"duration"`
+	Width     int     `json:"width"`
+	Height    int     `json:"height"`
+	Position  int32   `json:"position"`
+	CreatedAt int64   `json:"created_at"`
+}
+
+const (
+	DocumentSizeGuide        = "size_guide"
+	DocumentCareInstructions = "care_instructions"
+	DocumentOther            = "other"
+)
+
+// ProductDocument is a downloadable file such as a size guide, attached to
+// a product or to one variant.
+type ProductDocument struct {
+	ID        string  `json:"id"`
+	ProductID string  `json:"product_id"`
+	VariantID *string `json:"variant_id,omitempty"`
+	Kind      string  `json:"kind"`
+	Title     string  `json:"title"`
+	URL       string  `json:"url"`
+	Size      int64   `json:"size"`
+	Position  int32   `json:"position"`
+	CreatedAt int64   `json:"created_at"`
+}
+
+// BundleItem is one component line of a bundle: a variant in a size and the
+// quantity of it that goes into one bundle.
+type BundleItem struct {
+	ID          string `json:"id"`
+	VariantID   string `json:"variant_id"`
+	Size        string `json:"size"`
+	Quantity    int    `json:"quantity"`
+	ProductID   string `json:"product_id,omitempty"`
+	ProductName string `json:"product_name,omitempty"`
+	Color       string `json:"color,omitempty"`
+	SKU         string `json:"sku,omitempty"`
+	Available   int    `json:"available"`
 }
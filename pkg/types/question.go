@@ -0,0 +1,33 @@
+package types
+
+const (
+	QAStatusPending  = "pending"
+	QAStatusApproved = "approved"
+	QAStatusRejected = "rejected"
+)
+
+type Question struct {
+	ID        string    `json:"id"`
+	ProductID string    `json:"product_id"`
+	UserID    string    `json:"user_id"`
+	UserName  string    `json:"user_name,omitempty"`
+	Body      string    `json:"body"`
+	Status    string    `json:"status"`
+	Answers   []*Answer `json:"answers,omitempty"`
+	CreatedAt int64     `json:"created_at"`
+	UpdatedAt int64     `json:"updated_at"`
+}
+
+type Answer struct {
+	ID            string `json:"id"`
+	QuestionID    string `json:"question_id"`
+	UserID        string `json:"user_id"`
+	UserName      string `json:"user_name,omitempty"`
+	Body          string `json:"body"`
+	IsAdmin       bool   `json:"is_admin"`
+	VerifiedBuyer bool   `json:"verified_buyer"`
+	Status        string `json:"status"`
+	Upvotes       int    `json:"upvotes"`
+	CreatedAt     int64  `json:"created_at"`
+	UpdatedAt     int64  `json:"updated_at"`
+}
@@ -0,0 +1,27 @@
+package types
+
+const (
+	RecommendationOverridePinned   = "pinned"
+	RecommendationOverrideExcluded = "excluded"
+)
+
+// RelatedProduct is a product card shown next to another product or the
+// cart, with the score and reason it was picked for.
+type RelatedProduct struct {
+	ProductID     string  `json:"product_id"`
+	Name          string  `json:"name"`
+	Slug          string  `json:"slug"`
+	Price         float64 `json:"price"`
+	ImageURL      string  `json:"image_url,omitempty"`
+	RatingAverage float64 `json:"rating_average"`
+	Score         float64 `json:"score"`
+	Pinned        bool    `json:"pinned,omitempty"`
+}
+
+type RecommendationOverride struct {
+	ProductID string `json:"product_id"`
+	RelatedID string `json:"related_id"`
+	Type      string `json:"type"`
+	Position  int    `json:"position"`
+	CreatedAt int64  `json:"created_at"`
+}
@@ -29,19 +29,14 @@ type GetUserRequest struct {
 }
 
 type RequestPasswordResetRequest struct {
-	Email string `json:"email"`
-}
-
-type RequestPasswordResetResponse struct {
-	Success    bool   `json:"success"`
-	ResetToken string `json:"reset_token"`
-	ExpiresAt  int64  `json:"expires_at"`
+	Email string `json:"email" binding:"required,email"`
 }
 
+// ResetPasswordRequest carries the token from the reset email. It is never
+// stored; only its SHA-256 hash is.
 type ResetPasswordRequest struct {
-	ResetToken  string `json:"reset_token"`
-	OldPassword string `json:"old_password"`
-	NewPassword string `json:"new_password"`
+	ResetToken  string `json:"reset_token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
 }
 
 type LogoutRequest struct {
@@ -0,0 +1,14 @@
+package request
+
+type CreateBrandRequest struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Image       *string `json:"image,omitempty"`
+}
+
+type UpdateBrandRequest struct {
+	ID          string  `json:"id"`
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Image       *string `json:"image,omitempty"`
+}
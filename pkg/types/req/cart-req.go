@@ -2,6 +2,7 @@ package request
 
 type CartRequest struct {
 	VariantID string  `json:"variant_id"`
+	BundleID  string  `json:"bundle_id,omitempty"`
 	Size      string  `json:"size"`
 	Quantity  int64   `json:"quantity"`
 	UserID    string  `json:"user_id"`
@@ -5,12 +5,18 @@ type CreateCategoryRequest struct {
 	Description string  `json:"description"`
 	Image       *string `json:"image,omitempty"`
 	ParentID    *string `json:"parent_id,omitempty"`
+	// Position among the new category's siblings; nil appends it last.
+	Position *int32 `json:"position,omitempty"`
 }
 
 type GetCategoryRequest struct {
 	ID string `json:"id"`
 }
 
+type GetCategoryBySlugRequest struct {
+	Slug string `json:"slug"`
+}
+
 type UpdateCategoryRequest struct {
 	ID          string  `json:"id"`
 	Name        *string `json:"name,omitempty"`
@@ -20,9 +26,32 @@ type UpdateCategoryRequest struct {
 	ParentID    *string `json:"parent_id,omitempty"`
 }
 
+// MoveCategoryRequest re-parents a category and its subtree. A nil
+// ParentID moves it to the root.
+type MoveCategoryRequest struct {
+	ID       string  `json:"id"`
+	ParentID *string `json:"parent_id"`
+}
+
+// ReorderCategoriesRequest sets the order of a parent's children. IDs must
+// list every child exactly once; a nil ParentID orders the roots.
+type ReorderCategoriesRequest struct {
+	ParentID *string  `json:"parent_id"`
+	IDs      []string `json:"ids"`
+}
+
+type ListCategoryProductsRequest struct {
+	ID        string `json:"id"`
+	PageSize  int32  `json:"page_size,omitempty"`
+	PageToken string `json:"page_token,omitempty"`
+}
+
 type DeleteCategoryRequest struct {
-	ID             string `json:"id"`
-	DeleteChildren bool   `json:"delete_children"`
+	ID       string `json:"id"`
+	Strategy string `json:"strategy"`
+	// TargetID receives the children and products for reassign_target.
+	TargetID string `json:"target_id,omitempty"`
+	DryRun   bool   `json:"dry_run"`
 }
 
 type ListCategoriesRequest struct {
@@ -0,0 +1,26 @@
+package request
+
+import "github.com/wafi04/backend/pkg/types"
+
+type CreateCollectionRequest struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Image       *string                `json:"image,omitempty"`
+	Type        string                 `json:"type"`
+	Rules       *types.CollectionRules `json:"rules,omitempty"`
+}
+
+type UpdateCollectionRequest struct {
+	ID          string                 `json:"id"`
+	Name        *string                `json:"name,omitempty"`
+	Description *string                `json:"description,omitempty"`
+	Image       *string                `json:"image,omitempty"`
+	Rules       *types.CollectionRules `json:"rules,omitempty"`
+}
+
+// SetCollectionProductsRequest replaces the members of a manual collection;
+// ProductIDs is in display order.
+type SetCollectionProductsRequest struct {
+	CollectionID string   `json:"collection_id"`
+	ProductIDs   []string `json:"product_ids"`
+}
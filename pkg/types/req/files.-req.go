@@ -8,6 +8,9 @@ type FileUploadRequest struct {
 	Folder   string         `json:"folder"`
 	PublicID string         `json:"public_id"`
 	FileType FileType       `json:"file_type"`
+	// Overwrite lets the upload replace a file already stored under the
+	// same key. Without it drivers refuse to overwrite.
+	Overwrite bool `json:"overwrite"`
 }
 
 type FileType int32
@@ -17,3 +20,14 @@ const (
 	VIDEO    FileType = 1
 	DOCUMENT FileType = 2
 )
+
+// CreateUploadIntentRequest describes a file a client wants to upload
+// directly to storage. Size and ContentType are what the client declares;
+// both are checked again against the stored file on confirmation.
+type CreateUploadIntentRequest struct {
+	FileType    FileType `json:"file_type"`
+	FileName    string   `json:"file_name"`
+	ContentType string   `json:"content_type" binding:"required"`
+	Size        int64    `json:"size" binding:"required"`
+	Folder      string   `json:"folder" binding:"required"`
+}
@@ -0,0 +1,19 @@
+package request
+
+type GetPriceHistoryRequest struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id,omitempty"`
+}
+
+type SchedulePriceChangeRequest struct {
+	ProductID string  `json:"product_id"`
+	VariantID *string `json:"variant_id,omitempty"`
+	Price     float64 `json:"price"`
+	StartsAt  int64   `json:"starts_at"`
+	EndsAt    *int64  `json:"ends_at,omitempty"`
+	CreatedBy string  `json:"created_by"`
+}
+
+type CancelPriceChangeRequest struct {
+	ID string `json:"id"`
+}
@@ -8,14 +8,26 @@ type CreateProductRequest struct {
 	Description string  `json:"description"`
 	Price       float64 `json:"price"`
 	CategoryID  string  `json:"category_id"`
+	BrandID     *string `json:"brand_id,omitempty"`
+	Type        string  `json:"type,omitempty"`
+	Status      string  `json:"status,omitempty"`
+	PublishAt   *int64  `json:"publish_at,omitempty"`
+	UnpublishAt *int64  `json:"unpublish_at,omitempty"`
 }
 
 type GetProductRequest struct {
-	ID string `json:"id"`
+	ID            string `json:"id"`
+	PublishedOnly bool   `json:"published_only,omitempty"`
+}
+
+type GetProductBySlugRequest struct {
+	Slug          string `json:"slug"`
+	PublishedOnly bool   `json:"published_only,omitempty"`
 }
 
 type UpdateProductRequest struct {
 	Product *types.Product `json:"product,omitempty"`
+	ActorID string         `json:"actor_id,omitempty"`
 }
 
 type DeleteProductRequest struct {
@@ -23,8 +35,23 @@ type DeleteProductRequest struct {
 }
 
 type ListProductsRequest struct {
-	PageSize  int32  `json:"page_size,omitempty"`
-	PageToken string `json:"page_token,omitempty"`
+	PageSize     int32  `json:"page_size,omitempty"`
+	PageToken    string `json:"page_token,omitempty"`
+	Status       string `json:"status,omitempty"`
+	SortBy       string `json:"sort_by,omitempty"`
+	BrandID      string `json:"brand_id,omitempty"`
+	CollectionID string `json:"collection_id,omitempty"`
+	// Tags keeps products carrying any of the given tag slugs.
+	Tags []string `json:"tags,omitempty"`
+	// Query matches the product name, description or tag names.
+	Query string `json:"query,omitempty"`
+}
+
+type UpdateProductStatusRequest struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	PublishAt   *int64 `json:"publish_at,omitempty"`
+	UnpublishAt *int64 `json:"unpublish_at,omitempty"`
 }
 
 type CreateProductVariantRequest struct {
@@ -46,10 +73,27 @@ type UpdateProductVariantRequest struct {
 type DeleteProductVariantRequest struct {
 	ID string `json:"id,omitempty"`
 }
+
+// AddProductImageRequest adds an image to a variant. The first image of a
+// variant always becomes its main image; a nil Position appends the image.
 type AddProductImageRequest struct {
 	VariantID string `json:"variant_id,omitempty"`
 	URL       string `json:"url,omitempty"`
 	IsMain    bool   `json:"is_main,omitempty"`
+	AltText   string `json:"alt_text,omitempty"`
+	Position  *int32 `json:"position,omitempty"`
+}
+
+// ReorderProductImagesRequest sets the order of a variant's images. IDs must
+// list every image of the variant exactly once.
+type ReorderProductImagesRequest struct {
+	VariantID string   `json:"variant_id"`
+	IDs       []string `json:"ids"`
+}
+
+type SetMainProductImageRequest struct {
+	VariantID string `json:"variant_id"`
+	ImageID   string `json:"image_id"`
 }
 
 type UpdateProductImageRequest struct {
@@ -59,3 +103,49 @@ type UpdateProductImageRequest struct {
 type DeleteProductImageRequest struct {
 	ID string `json:"id,omitempty"`
 }
+
+// AddProductVideoRequest attaches an uploaded video to a product, or to one
+// of its variants when VariantID is set.
+type AddProductVideoRequest struct {
+	ProductID string  `json:"product_id"`
+	VariantID string  `json:"variant_id,omitempty"`
+	URL       string  `json:"url"`
+	PosterURL string  `json:"poster_url,omitempty"`
+	Title     string  `json:"title,omitempty"`
+	Duration  float64 `json:"duration"`
+	Width     int     `json:"width"`
+	Height    int     `json:"height"`
+}
+
+// AddProductDocumentRequest attaches an uploaded PDF to a product, or to one
+// of its variants when VariantID is set.
+type AddProductDocumentRequest struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id,omitempty"`
+	Kind      string `json:"kind"`
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	Size      int64  `json:"size"`
+}
+
+type DeleteProductMediaRequest struct {
+	ProductID string `json:"product_id"`
+	ID        string `json:"id"`
+}
+
+type ImportCatalogRequest struct {
+	Format string `json:"format"`
+	DryRun bool   `json:"dry_run"`
+	Wait   bool   `json:"-"`
+}
+
+type BundleItemInput struct {
+	VariantID string `json:"variant_id"`
+	Size      string `json:"size"`
+	Quantity  int    `json:"quantity"`
+}
+
+type SetBundleItemsRequest struct {
+	BundleID string             `json:"bundle_id"`
+	Items    []*BundleItemInput `json:"items"`
+}
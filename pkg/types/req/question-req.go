@@ -0,0 +1,37 @@
+package request
+
+type CreateQuestionRequest struct {
+	ProductID string `json:"product_id"`
+	UserID    string `json:"user_id"`
+	Body      string `json:"body"`
+}
+
+type ListQuestionsRequest struct {
+	ProductID string `json:"product_id,omitempty"`
+	Status    string `json:"status,omitempty"`
+	PageSize  int32  `json:"page_size,omitempty"`
+	PageToken string `json:"page_token,omitempty"`
+}
+
+type CreateAnswerRequest struct {
+	QuestionID string `json:"question_id"`
+	UserID     string `json:"user_id"`
+	IsAdmin    bool   `json:"is_admin"`
+	Body       string `json:"body"`
+}
+
+type ListAnswersRequest struct {
+	Status    string `json:"status,omitempty"`
+	PageSize  int32  `json:"page_size,omitempty"`
+	PageToken string `json:"page_token,omitempty"`
+}
+
+type ModerateQARequest struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+type UpvoteAnswerRequest struct {
+	AnswerID string `json:"answer_id"`
+	UserID   string `json:"user_id"`
+}
@@ -0,0 +1,23 @@
+package request
+
+type GetRelatedProductsRequest struct {
+	ProductID string `json:"product_id"`
+	Limit     int    `json:"limit,omitempty"`
+}
+
+type GetCartRecommendationsRequest struct {
+	UserID string `json:"user_id"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+type SetRecommendationOverrideRequest struct {
+	ProductID string `json:"product_id"`
+	RelatedID string `json:"related_id"`
+	Type      string `json:"type"`
+	Position  int    `json:"position"`
+}
+
+type DeleteRecommendationOverrideRequest struct {
+	ProductID string `json:"product_id"`
+	RelatedID string `json:"related_id"`
+}
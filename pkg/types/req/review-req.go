@@ -0,0 +1,30 @@
+package request
+
+type CreateReviewRequest struct {
+	ProductID string   `json:"product_id"`
+	UserID    string   `json:"user_id"`
+	Rating    int      `json:"rating"`
+	Title     string   `json:"title"`
+	Body      string   `json:"body"`
+	Photos    []string `json:"photos,omitempty"`
+}
+
+type ListReviewsRequest struct {
+	ProductID string `json:"product_id,omitempty"`
+	Status    string `json:"status,omitempty"`
+	SortBy    string `json:"sort_by,omitempty"`
+	PageSize  int32  `json:"page_size,omitempty"`
+	PageToken string `json:"page_token,omitempty"`
+}
+
+type ModerateReviewRequest struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	Note          string `json:"note,omitempty"`
+	VerifiedBuyer *bool  `json:"verified_buyer,omitempty"`
+}
+
+type MarkReviewHelpfulRequest struct {
+	ReviewID string `json:"review_id"`
+	UserID   string `json:"user_id"`
+}
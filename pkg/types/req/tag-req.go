@@ -0,0 +1,15 @@
+package request
+
+type AddProductTagsRequest struct {
+	ProductID string   `json:"product_id"`
+	Tags      []string `json:"tags"`
+}
+
+type RemoveProductTagRequest struct {
+	ProductID string `json:"product_id"`
+	Slug      string `json:"slug"`
+}
+
+type ListTagsRequest struct {
+	PublishedOnly bool `json:"published_only"`
+}
@@ -0,0 +1,10 @@
+package request
+
+type ListTrashRequest struct {
+	Type string `json:"type"`
+}
+
+type RestoreTrashRequest struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
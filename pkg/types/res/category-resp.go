@@ -14,6 +14,28 @@ type CategoryHierarchyResponse struct {
 }
 
 type DeleteCategoryResponse struct {
-	Success      bool  `json:"success"`
-	DeletedCount int64 `json:"deleted_count"`
+	Success            bool   `json:"success"`
+	Strategy           string `json:"strategy"`
+	DryRun             bool   `json:"dry_run"`
+	DeletedCount       int64  `json:"deleted_count"`
+	DeletedProducts    int64  `json:"deleted_products"`
+	ReassignedChildren int64  `json:"reassigned_children"`
+	ReassignedProducts int64  `json:"reassigned_products"`
+}
+
+type MoveCategoryResponse struct {
+	Category     *types.Category `json:"category"`
+	UpdatedCount int64           `json:"updated_count"`
+}
+
+// CategoryBreadcrumbResponse lists the ancestors of a category from the
+// root down to the category itself.
+type CategoryBreadcrumbResponse struct {
+	Items []*types.Category `json:"items"`
+}
+
+type CategoryBySlugResponse struct {
+	Category      *types.Category `json:"category"`
+	CanonicalSlug string          `json:"canonical_slug"`
+	Redirected    bool            `json:"redirected"`
 }
@@ -0,0 +1,11 @@
+package response
+
+import "github.com/wafi04/backend/pkg/types"
+
+type ListBrandsResponse struct {
+	Brands []*types.Brand `json:"brands"`
+}
+
+type ListCollectionsResponse struct {
+	Collections []*types.Collection `json:"collections"`
+}
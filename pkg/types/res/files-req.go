@@ -1,7 +1,55 @@
 package response
 
+import "github.com/wafi04/backend/pkg/types"
+
 type FileUploadResponse struct {
 	URL      string `json:"url"`
 	PublicID string `json:"public_id"`
 	Error    string `json:"error,omitempty"`
 }
+
+// FileValidationError is returned when an upload is rejected before it
+// reaches the storage driver.
+type FileValidationError struct {
+	Code        string `json:"code"`
+	Message     string `json:"message"`
+	ContentType string `json:"content_type,omitempty"`
+	Width       int    `json:"width,omitempty"`
+	Height      int    `json:"height,omitempty"`
+}
+
+func (e *FileValidationError) Error() string {
+	return "invalid file: " + e.Message
+}
+
+const (
+	FileErrorTooLarge    = "file_too_large"
+	FileErrorUnsupported = "unsupported_type"
+	FileErrorUnreadable  = "unreadable_image"
+	FileErrorDimensions  = "invalid_dimensions"
+	FileErrorAspectRatio = "invalid_aspect_ratio"
+	FileErrorVideo       = "invalid_video"
+	FileErrorDuration    = "invalid_duration"
+	FileErrorDocument    = "invalid_document"
+)
+
+// UploadTarget tells a client where to send a file directly to storage.
+type UploadTarget struct {
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	ExpiresAt int64             `json:"expires_at"`
+}
+
+type UploadIntentResponse struct {
+	Intent *types.UploadIntent `json:"intent"`
+	Upload *UploadTarget       `json:"upload"`
+}
+
+type ConfirmUploadResponse struct {
+	URL      string `json:"url"`
+	PublicID string `json:"public_id"`
+	// Deduplicated is set when the content was already stored and the
+	// existing file is returned instead.
+	Deduplicated bool `json:"deduplicated"`
+}
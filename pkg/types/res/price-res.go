@@ -0,0 +1,20 @@
+package response
+
+import "github.com/wafi04/backend/pkg/types"
+
+type PriceHistoryResponse struct {
+	ProductID         string                     `json:"product_id"`
+	VariantID         string                     `json:"variant_id,omitempty"`
+	CurrentPrice      float64                    `json:"current_price"`
+	LowestPrice30Days float64                    `json:"lowest_price_30_days"`
+	History           []*types.PriceHistoryEntry `json:"history"`
+}
+
+type ListPriceChangesResponse struct {
+	Changes []*types.ScheduledPriceChange `json:"changes"`
+}
+
+type ApplyPriceScheduleResponse struct {
+	Started int64 `json:"started"`
+	Ended   int64 `json:"ended"`
+}
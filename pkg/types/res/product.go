@@ -14,3 +14,14 @@ type ListProductsResponse struct {
 type GetProductVariantsResponse struct {
 	Variants []*types.ProductVariant `json:"variants,omitempty"`
 }
+
+type ApplyProductScheduleResponse struct {
+	Published   int64 `json:"published"`
+	Unpublished int64 `json:"unpublished"`
+}
+
+type ProductBySlugResponse struct {
+	Product       *types.Product `json:"product"`
+	CanonicalSlug string         `json:"canonical_slug"`
+	Redirected    bool           `json:"redirected"`
+}
@@ -0,0 +1,18 @@
+package response
+
+import "github.com/wafi04/backend/pkg/types"
+
+type ListQuestionsResponse struct {
+	Questions     []*types.Question `json:"questions"`
+	NextPageToken string            `json:"next_page_token,omitempty"`
+}
+
+type ListAnswersResponse struct {
+	Answers       []*types.Answer `json:"answers"`
+	NextPageToken string          `json:"next_page_token,omitempty"`
+}
+
+type UpvoteAnswerResponse struct {
+	AnswerID string `json:"answer_id"`
+	Upvotes  int    `json:"upvotes"`
+}
@@ -0,0 +1,17 @@
+package response
+
+import "github.com/wafi04/backend/pkg/types"
+
+type RelatedProductsResponse struct {
+	Products []*types.RelatedProduct `json:"products"`
+}
+
+type ListRecommendationOverridesResponse struct {
+	Overrides []*types.RecommendationOverride `json:"overrides"`
+}
+
+type RecomputeSimilaritiesResponse struct {
+	Similarities   int64 `json:"similarities"`
+	CoOccurrences  int64 `json:"co_occurrences"`
+	DurationMillis int64 `json:"duration_ms"`
+}
@@ -0,0 +1,13 @@
+package response
+
+import "github.com/wafi04/backend/pkg/types"
+
+type ListReviewsResponse struct {
+	Reviews       []*types.Review `json:"reviews"`
+	NextPageToken string          `json:"next_page_token,omitempty"`
+}
+
+type MarkReviewHelpfulResponse struct {
+	ReviewID     string `json:"review_id"`
+	HelpfulCount int    `json:"helpful_count"`
+}
@@ -0,0 +1,12 @@
+package response
+
+import "github.com/wafi04/backend/pkg/types"
+
+type ListTagsResponse struct {
+	Tags []*types.Tag `json:"tags"`
+}
+
+type ProductTagsResponse struct {
+	ProductID string       `json:"product_id"`
+	Tags      []*types.Tag `json:"tags"`
+}
@@ -0,0 +1,12 @@
+package response
+
+import "github.com/wafi04/backend/pkg/types"
+
+type ListTrashResponse struct {
+	Items []*types.TrashItem `json:"items"`
+}
+
+type RestoreTrashResponse struct {
+	Success       bool  `json:"success"`
+	RestoredCount int64 `json:"restored_count"`
+}
@@ -0,0 +1,24 @@
+package types
+
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+)
+
+type Review struct {
+	ID             string   `json:"id"`
+	ProductID      string   `json:"product_id"`
+	UserID         string   `json:"user_id"`
+	UserName       string   `json:"user_name,omitempty"`
+	Rating         int      `json:"rating"`
+	Title          string   `json:"title,omitempty"`
+	Body           string   `json:"body"`
+	Photos         []string `json:"photos,omitempty"`
+	VerifiedBuyer  bool     `json:"verified_buyer"`
+	Status         string   `json:"status"`
+	ModerationNote string   `json:"moderation_note,omitempty"`
+	HelpfulCount   int      `json:"helpful_count"`
+	CreatedAt      int64    `json:"created_at"`
+	UpdatedAt      int64    `json:"updated_at"`
+}
@@ -0,0 +1,6 @@
+package types
+
+const (
+	SlugEntityProduct  = "product"
+	SlugEntityCategory = "category"
+)
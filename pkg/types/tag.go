@@ -0,0 +1,10 @@
+package types
+
+// Tag is a normalized product tag. Slug is the canonical key; Name keeps the
+// spelling the tag was first created with.
+type Tag struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	ProductCount int    `json:"product_count,omitempty"`
+}
@@ -0,0 +1,18 @@
+package types
+
+import "time"
+
+const (
+	TrashTypeProduct  = "product"
+	TrashTypeVariant  = "variant"
+	TrashTypeImage    = "image"
+	TrashTypeCategory = "category"
+)
+
+type TrashItem struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Name      string    `json:"name"`
+	ParentID  *string   `json:"parent_id,omitempty"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
@@ -0,0 +1,25 @@
+package types
+
+import "time"
+
+const (
+	UploadStatusPending   = "pending"
+	UploadStatusConfirmed = "confirmed"
+	UploadStatusRejected  = "rejected"
+	UploadStatusExpired   = "expired"
+)
+
+// UploadIntent is a permission for one client to upload one file straight
+// to storage. The file only becomes usable once the intent is confirmed.
+type UploadIntent struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	FileType    int32      `json:"file_type"`
+	ObjectKey   string     `json:"object_key"`
+	ContentType string     `json:"content_type"`
+	MaxBytes    int64      `json:"max_bytes"`
+	Status      string     `json:"status"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
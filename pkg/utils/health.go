@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 )
+
 func ConnectionHealthy(c *gin.Context) {
 	serviceReady := true
 	timestamp := time.Now().Format(time.RFC3339)
@@ -22,7 +23,6 @@ func ConnectionHealthy(c *gin.Context) {
 			"status":  "oke",
 			"message": "Service Ready",
 			"data":    data,
-	
 		})
 	} else {
 		c.JSON(http.StatusServiceUnavailable, gin.H{
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const maxSlugLength = 80
+
+var slugInvalidChars = regexp.MustCompile("[^a-z0-9]+")
+
+// Slugify turns a display name into a lowercase, hyphen separated slug.
+func Slugify(name string) string {
+	slug := slugInvalidChars.ReplaceAllString(strings.ToLower(name), "-")
+	slug = strings.Trim(slug, "-")
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	if slug == "" {
+		slug = "item"
+	}
+	return slug
+}
+
+// NextSlug returns base if it is not taken, otherwise the first free
+// base-2, base-3, ... variant.
+func NextSlug(base string, taken []string) string {
+	used := make(map[string]bool, len(taken))
+	for _, t := range taken {
+		used[t] = true
+	}
+	if !used[base] {
+		return base
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", base, i)
+		if !used[candidate] {
+			return candidate
+		}
+	}
+}
+
+// GenerateSlug builds a unique slug from name for the row id of table.
+// Slugs held by other rows are skipped, and when entityType is set so are
+// the slug_redirects kept for other entities of that type.
+func GenerateSlug(ctx context.Context, tx *sql.Tx, table, entityType, name, id string) (string, error) {
+	base := Slugify(name)
+
+	query := `
+        SELECT slug FROM ` + table + `
+        WHERE id <> $2 AND (slug = $1 OR slug LIKE $1 || '-%')`
+	args := []interface{}{base, id}
+	if entityType != "" {
+		query += `
+        UNION
+        SELECT slug FROM slug_redirects
+        WHERE entity_type = $3 AND entity_id <> $2 AND (slug = $1 OR slug LIKE $1 || '-%')`
+		args = append(args, entityType)
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to check slug: %v", err)
+	}
+	defer rows.Close()
+
+	var taken []string
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return "", fmt.Errorf("failed to scan slug: %v", err)
+		}
+		taken = append(taken, slug)
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error iterating slugs: %v", err)
+	}
+
+	return NextSlug(base, taken), nil
+}
@@ -1,9 +1,10 @@
 package authhandler
 
 import (
-	"fmt"
+	"errors"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -34,17 +35,6 @@ func (s *AuthHandler) Verify(c *gin.Context) {
 		})
 		return
 	}
-	log.Printf("Received refresh token: %s", refreshToken)
-	log.Printf("Validating refresh token...")
-	claims, err := middleware.ValidateToken(refreshToken)
-	if err != nil {
-		log.Printf("Validation failed: %v", err)
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-			"error": fmt.Sprintf("Invalid refresh token: %v", err),
-		})
-		return
-	}
-	log.Printf("Refresh token validated successfully: %+v", claims)
 	sessionID, err := c.Cookie("session")
 	if err != nil {
 		httpresponse.SendErrorResponse(c, http.StatusUnauthorized, "No session found")
@@ -59,6 +49,11 @@ func (s *AuthHandler) Verify(c *gin.Context) {
 		return
 	}
 
+	if session.RefreshToken != authrepo.HashRefreshToken(refreshToken) {
+		httpresponse.SendErrorResponse(c, http.StatusUnauthorized, "Invalid refresh token")
+		return
+	}
+
 	// Validasi session
 	if !session.IsActive {
 		httpresponse.SendErrorResponse(c, http.StatusUnauthorized, "Session inactive")
@@ -280,6 +275,9 @@ func (s *AuthHandler) RevokeSession(c *gin.Context) {
 	httpresponse.SendSuccessResponse(c, http.StatusOK, "Session revoked successfully", resp)
 }
 
+// RefreshToken swaps the refresh_token cookie for a new access token and a
+// new refresh token. It needs no access token, since it is how a client
+// gets one after the old one expired.
 func (s *AuthHandler) RefreshToken(c *gin.Context) {
 	cookie, err := c.Cookie("refresh_token")
 	if err != nil {
@@ -287,30 +285,24 @@ func (s *AuthHandler) RefreshToken(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
 		return
 	}
-	session, err := c.Cookie("session")
-	if err != nil {
-		s.log.Log(logger.DebugLevel, "err : %v", err)
-
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid session token"})
-		return
-	}
-	_, err = middleware.ValidateToken(cookie)
-	if err != nil {
-		s.log.Log(logger.DebugLevel, "err : %v", err)
+	session, _ := c.Cookie("session")
 
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
-		return
-	}
 	resp, err := s.AuthService.RefreshToken(c.Request.Context(), &request.RefreshTokenRequest{
 		RefreshToken: cookie,
 		SessionID:    session,
 	})
+	if errors.Is(err, authrepo.ErrRefreshTokenReuse) {
+		middleware.ClearTokens(c)
+		httpresponse.SendErrorResponse(c, http.StatusUnauthorized, "Refresh token was already used, please sign in again")
+		return
+	}
 	if err != nil {
 		log.Printf("Failed to refresh token: %v", err)
 		httpresponse.SendErrorResponse(c, http.StatusUnauthorized, "Token refresh failed")
 		return
 	}
 
+	middleware.SetRefreshTokenCookie(c, resp.RefreshToken)
 	httpresponse.SendSuccessResponse(c, http.StatusOK, "Token refreshed successfully", resp)
 }
 
@@ -332,3 +324,47 @@ func (s *AuthHandler) ListSessions(c *gin.Context) {
 
 	httpresponse.SendSuccessResponse(c, http.StatusOK, "Sessions listed successfully", resp)
 }
+
+// ForgotPassword always answers the same way, so it cannot be used to find
+// out which emails have accounts.
+func (s *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req request.RequestPasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	if err := s.AuthService.RequestPasswordReset(c.Request.Context(), &req); err != nil {
+		log.Printf("Failed to request password reset: %v", err)
+		httpresponse.SendErrorResponse(c, http.StatusInternalServerError, "Failed to request password reset")
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "If an account exists for this email, a reset link has been sent", nil)
+}
+
+func (s *AuthHandler) ResetPassword(c *gin.Context) {
+	var req request.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	resp, err := s.AuthService.ResetPassword(c.Request.Context(), &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to reset password", err.Error())
+			return
+		}
+		log.Printf("Failed to reset password: %v", err)
+		httpresponse.SendErrorResponse(c, http.StatusInternalServerError, "Failed to reset password")
+		return
+	}
+	if !resp.Success {
+		httpresponse.SendErrorResponse(c, http.StatusBadRequest, resp.Message)
+		return
+	}
+
+	middleware.ClearTokens(c)
+	httpresponse.SendSuccessResponse(c, http.StatusOK, resp.Message, resp)
+}
@@ -10,13 +10,11 @@ import (
 	config "github.com/wafi04/backend/config/development"
 
 	"github.com/wafi04/backend/pkg/logger"
-	"github.com/wafi04/backend/pkg/middleware"
 	"github.com/wafi04/backend/pkg/types"
 	request "github.com/wafi04/backend/pkg/types/req"
 	response "github.com/wafi04/backend/pkg/types/res"
 	"github.com/wafi04/backend/pkg/utils"
 	"github.com/wafi04/shared/pkg/mailer"
-	"golang.org/x/crypto/bcrypt"
 )
 
 func (s *AuthRepository) ResendVerification(ctx context.Context, req *request.ResendVerificationRequest) (*response.ResendVerificationResponse, error) {
@@ -134,101 +132,106 @@ func (s *AuthRepository) VerifyEmail(ctx context.Context, req *request.VerifyEma
 		Message: "Email verified successfully",
 	}, nil
 }
-func (s *AuthRepository) RequestPasswordReset(ctx context.Context, req *request.RequestPasswordResetRequest) (*request.RequestPasswordResetResponse, error) {
-	var user types.User
-	checkUserQuery := `SELECT user_id,name,email,Role,is_email_verified,is_active  FROM users WHERE email = $1`
-	err := s.DB.QueryRowContext(ctx, checkUserQuery, req.Email).Scan(&user.UserID, user.Name, user.Email, user.Role, user.IsEmailVerified, user.IsActive)
+
+// TokenTypePasswordReset marks reset tokens in verification_tokens. The
+// token column holds the SHA-256 hash of the emailed token.
+const TokenTypePasswordReset = "PASSWORD_RESET"
+
+// CreatePasswordResetToken stores tokenHash for the active user with email
+// and invalidates the user's earlier reset tokens, so only the newest link
+// works. It returns nil when there is no such user.
+func (s *AuthRepository) CreatePasswordResetToken(ctx context.Context, email, tokenHash string, expiresAt time.Time) (*types.UserInfo, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return &request.RequestPasswordResetResponse{
-				Success: false,
-			}, nil
-		}
-		return nil, fmt.Errorf("failed to check user: %v", err)
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
 	}
+	defer tx.Rollback()
 
-	token, err := middleware.GenerateToken(&types.UserInfo{
-		UserID:          user.UserID,
-		Name:            user.Name,
-		Email:           user.Email,
-		Role:            user.Role,
-		IsEmailVerified: user.IsEmailVerified,
-	}, 24)
-
+	var user types.UserInfo
+	err = tx.QueryRowContext(ctx, `
+        SELECT user_id, name, email
+        FROM users
+        WHERE LOWER(email) = LOWER($1) AND is_active = true`,
+		email,
+	).Scan(&user.UserID, &user.Name, &user.Email)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to check user: %v", err)
 	}
 
-	insertTokenQuery := `
-        INSERT INTO verification_tokens 
-        (token, user_id, token_type, expires_at) 
-        VALUES ($1, $2, 'PASSWORD', CURRENT_TIMESTAMP + INTERVAL '1 hour')`
+	_, err = tx.ExecContext(ctx, `
+        UPDATE verification_tokens
+        SET is_used = true
+        WHERE user_id = $1 AND token_type = $2 AND is_used = false`,
+		user.UserID, TokenTypePasswordReset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to invalidate reset tokens: %v", err)
+	}
 
-	_, err = s.DB.ExecContext(ctx, insertTokenQuery, token, user.UserID)
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO verification_tokens (token, user_id, token_type, expires_at)
+        VALUES ($1, $2, $3, $4)`,
+		tokenHash, user.UserID, TokenTypePasswordReset, expiresAt,
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create reset token: %v", err)
 	}
 
-	return &request.RequestPasswordResetResponse{
-		Success:    true,
-		ResetToken: token,
-		ExpiresAt:  time.Now().Add(1 * time.Hour).Unix(),
-	}, nil
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return &user, nil
 }
 
-func (s *AuthRepository) ResetPassword(ctx context.Context, req *request.ResetPasswordRequest) (*response.ResetPasswordResponse, error) {
+// ResetPassword spends the reset token with tokenHash, sets the new
+// password and ends every session of the user.
+func (s *AuthRepository) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (*response.ResetPasswordResponse, error) {
 	tx, err := s.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed torequestegin transaction: %v", err)
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
 	}
 	defer tx.Rollback()
 
-	verifyTokenQuery := `
-        SELECT user_id 
-        FROM verification_tokens 
-        WHERE token = $1 
-        AND token_type = 'password_reset'
-        AND expires_at > CURRENT_TIMESTAMP 
-        AND is_used = false`
-
+	// Marking the token used in the same statement that finds it keeps two
+	// concurrent requests from both spending it.
 	var userID string
-	err = tx.QueryRowContext(ctx, verifyTokenQuery, req.ResetToken).Scan(&userID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return &response.ResetPasswordResponse{
-				Success: false,
-				Message: "Invalid or expired reset token",
-			}, nil
-		}
-		return nil, fmt.Errorf("failed to verify reset token: %v", err)
+	err = tx.QueryRowContext(ctx, `
+        UPDATE verification_tokens
+        SET is_used = true
+        WHERE token = $1
+        AND token_type = $2
+        AND is_used = false
+        AND expires_at > CURRENT_TIMESTAMP
+        RETURNING user_id`,
+		tokenHash, TokenTypePasswordReset,
+	).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return &response.ResetPasswordResponse{
+			Success: false,
+			Message: "Invalid or expired reset token",
+		}, nil
 	}
-
-	updatePasswordQuery := `
-        UPDATE users 
-        SET password_hash = $1, 
-            updated_at = CURRENT_TIMESTAMP 
-        WHERE user_id = $2 
-        RETURNING extract(epoch from updated_at):requestigint`
-
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), 10)
 	if err != nil {
-		return nil, fmt.Errorf("failed to hash password: %v", err)
+		return nil, fmt.Errorf("failed to verify reset token: %v", err)
 	}
 
-	var updatedAt int64
-	err = tx.QueryRowContext(ctx, updatePasswordQuery, hashedPassword, userID).Scan(&updatedAt)
+	var updatedAt time.Time
+	err = tx.QueryRowContext(ctx, `
+        UPDATE users
+        SET password_hash = $1, updated_at = CURRENT_TIMESTAMP
+        WHERE user_id = $2
+        RETURNING updated_at`,
+		passwordHash, userID,
+	).Scan(&updatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update password: %v", err)
 	}
 
-	markTokenUsedQuery := `
-        UPDATE verification_tokens 
-        SET is_used = true 
-        WHERE token = $1`
-
-	_, err = tx.ExecContext(ctx, markTokenUsedQuery, req.ResetToken)
-	if err != nil {
-		return nil, fmt.Errorf("failed to mark token as used: %v", err)
+	if _, err := tx.ExecContext(ctx, "DELETE FROM sessions WHERE user_id = $1", userID); err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %v", err)
 	}
 
 	if err = tx.Commit(); err != nil {
@@ -238,6 +241,6 @@ func (s *AuthRepository) ResetPassword(ctx context.Context, req *request.ResetPa
 	return &response.ResetPasswordResponse{
 		Success:   true,
 		Message:   "Password successfully reset",
-		UpdatedAt: updatedAt,
+		UpdatedAt: updatedAt.Unix(),
 	}, nil
 }
@@ -0,0 +1,226 @@
+package authrepo
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wafi04/backend/pkg/logger"
+	"github.com/wafi04/backend/pkg/middleware"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+)
+
+// RefreshTokenTTL is how long one refresh token can be used. Every refresh
+// replaces it, so an active session never reaches this.
+const RefreshTokenTTL = 7 * 24 * time.Hour
+
+// ErrRefreshTokenReuse is returned when a refresh token that was already
+// rotated is presented again. Either the client or a thief holds a stale
+// copy, so the whole family is revoked.
+var ErrRefreshTokenReuse = errors.New("refresh token reuse detected")
+
+// NewRefreshToken returns an opaque refresh token and the hash to store for
+// it. The token itself is never written to the database.
+func NewRefreshToken() (token, hash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %v", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(raw)
+	return token, HashRefreshToken(token), nil
+}
+
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// StartRefreshFamily makes tokenHash the only valid refresh token of the
+// session, as the first of a new family. Tokens of earlier logins on the
+// same session are revoked.
+func (D *AuthRepository) StartRefreshFamily(ctx context.Context, sessionID, userID, accessToken, tokenHash string) error {
+	tx, err := D.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE refresh_tokens
+        SET revoked_at = NOW()
+        WHERE session_id = $1 AND revoked_at IS NULL`,
+		sessionID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke old refresh tokens: %v", err)
+	}
+
+	if err := insertRefreshToken(ctx, tx, tokenHash, uuid.New().String(), sessionID, userID); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE sessions
+        SET access_token = $1, refresh_token = $2, is_active = true
+        WHERE session_id = $3`,
+		accessToken, tokenHash, sessionID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update session: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+func insertRefreshToken(ctx context.Context, tx *sql.Tx, tokenHash, familyID, sessionID, userID string) error {
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO refresh_tokens (token_hash, family_id, session_id, user_id, expires_at)
+        VALUES ($1, $2, $3, $4, $5)`,
+		tokenHash, familyID, sessionID, userID, time.Now().Add(RefreshTokenTTL),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store refresh token: %v", err)
+	}
+	return nil
+}
+
+// RefreshToken exchanges a refresh token for a new access token and a new
+// refresh token in the same family. The presented token stops working. If
+// it had already been exchanged, the family is revoked, the session ends
+// and ErrRefreshTokenReuse is returned.
+func (D *AuthRepository) RefreshToken(ctx context.Context, req *request.RefreshTokenRequest) (*response.RefreshTokenResponse, error) {
+	D.logger.Log(logger.InfoLevel, "Refresh Token Incoming")
+
+	tx, err := D.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	oldHash := HashRefreshToken(req.RefreshToken)
+
+	var (
+		familyID, sessionID, userID string
+		expiresAt                   time.Time
+		rotated, revoked, active    bool
+	)
+	err = tx.QueryRowContext(ctx, `
+        SELECT t.family_id, t.session_id, t.user_id, t.expires_at,
+               t.rotated_at IS NOT NULL, t.revoked_at IS NOT NULL, s.is_active
+        FROM refresh_tokens t
+        JOIN sessions s ON s.session_id = t.session_id
+        WHERE t.token_hash = $1
+        FOR UPDATE OF t`,
+		oldHash,
+	).Scan(&familyID, &sessionID, &userID, &expiresAt, &rotated, &revoked, &active)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("invalid refresh token")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %v", err)
+	}
+
+	if req.SessionID != "" && req.SessionID != sessionID {
+		return nil, errors.New("invalid refresh token")
+	}
+	if revoked || !active {
+		return nil, errors.New("invalid refresh token")
+	}
+	if rotated {
+		if err := revokeRefreshFamily(ctx, tx, familyID, sessionID); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %v", err)
+		}
+		D.logger.Log(logger.ErrorLevel, "Refresh token reuse for session %s, family %s revoked", sessionID, familyID)
+		return nil, ErrRefreshTokenReuse
+	}
+	if time.Now().After(expiresAt) {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	var user types.UserInfo
+	err = tx.QueryRowContext(ctx, `
+        SELECT user_id, name, email, role, is_email_verified
+        FROM users
+        WHERE user_id = $1 AND is_active = true`,
+		userID,
+	).Scan(&user.UserID, &user.Name, &user.Email, &user.Role, &user.IsEmailVerified)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("invalid refresh token")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %v", err)
+	}
+
+	accessToken, err := middleware.GenerateToken(&user, 24)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	newToken, newHash, err := NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := insertRefreshToken(ctx, tx, newHash, familyID, sessionID, userID); err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, `
+        UPDATE refresh_tokens
+        SET rotated_at = NOW(), replaced_by = $1
+        WHERE token_hash = $2`,
+		newHash, oldHash,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %v", err)
+	}
+	_, err = tx.ExecContext(ctx, `
+        UPDATE sessions
+        SET access_token = $1, refresh_token = $2, last_activity_at = CURRENT_TIMESTAMP
+        WHERE session_id = $3`,
+		accessToken, newHash, sessionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update session: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return &response.RefreshTokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: newToken,
+		ExpiresAt:    time.Now().Add(24 * time.Hour).Unix(),
+	}, nil
+}
+
+func revokeRefreshFamily(ctx context.Context, tx *sql.Tx, familyID, sessionID string) error {
+	_, err := tx.ExecContext(ctx, `
+        UPDATE refresh_tokens
+        SET revoked_at = NOW()
+        WHERE family_id = $1 AND revoked_at IS NULL`,
+		familyID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %v", err)
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE sessions SET is_active = false WHERE session_id = $1", sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to end session: %v", err)
+	}
+	return nil
+}
@@ -2,14 +2,12 @@ package authrepo
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/wafi04/backend/pkg/logger"
-	"github.com/wafi04/backend/pkg/middleware"
 	"github.com/wafi04/backend/pkg/types"
 	request "github.com/wafi04/backend/pkg/types/req"
 	response "github.com/wafi04/backend/pkg/types/res"
@@ -32,8 +30,12 @@ func (D *AuthRepository) RevokeSession(ctx context.Context, req *request.RevokeS
 	return &response.RevokeSessionResponse{
 		Success: true}, nil
 }
+
+// CreateSession stores session, or reuses the row of the same user and
+// device, and sets session.SessionID to the stored row. RefreshToken must
+// already be a hash.
 func (D *AuthRepository) CreateSession(ctx context.Context, session *types.Session) error {
-	insertQuery := `
+	query := `
        INSERT INTO sessions (
            session_id, 
            user_id, 
@@ -46,16 +48,14 @@ func (D *AuthRepository) CreateSession(ctx context.Context, session *types.Sessi
            last_activity_at, 
            created_at
        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-   `
-
-	updateQuery := `
-       UPDATE sessions 
-       SET 
-           access_token = $1, 
-           refresh_token = $2, 
-           ip_address = $3, 
-           last_activity_at = $4
-       WHERE user_id = $5 AND device_info = $6
+       ON CONFLICT (user_id, device_info) DO UPDATE
+       SET access_token = EXCLUDED.access_token,
+           refresh_token = EXCLUDED.refresh_token,
+           ip_address = EXCLUDED.ip_address,
+           is_active = true,
+           expires_at = EXCLUDED.expires_at,
+           last_activity_at = EXCLUDED.last_activity_at
+       RETURNING session_id
    `
 
 	if session.SessionID == "" {
@@ -63,11 +63,11 @@ func (D *AuthRepository) CreateSession(ctx context.Context, session *types.Sessi
 	}
 
 	now := time.Now()
-	expiresAt := now.Add(24 * time.Hour)
+	expiresAt := now.Add(RefreshTokenTTL)
 
-	_, err := D.DB.ExecContext(
+	err := D.DB.QueryRowContext(
 		ctx,
-		insertQuery,
+		query,
 		session.SessionID,
 		session.UserID,
 		session.AccessToken,
@@ -78,20 +78,7 @@ func (D *AuthRepository) CreateSession(ctx context.Context, session *types.Sessi
 		expiresAt,
 		now,
 		now,
-	)
-
-	if err != nil {
-		_, err = D.DB.ExecContext(
-			ctx,
-			updateQuery,
-			session.AccessToken,
-			session.RefreshToken,
-			session.IPAddress,
-			now,
-			session.UserID,
-			session.DeviceInfo,
-		)
-	}
+	).Scan(&session.SessionID)
 
 	if err != nil {
 		D.logger.WithError(err).WithFields(map[string]interface{}{
@@ -104,63 +91,6 @@ func (D *AuthRepository) CreateSession(ctx context.Context, session *types.Sessi
 	return nil
 
 }
-func (D *AuthRepository) RefreshToken(ctx context.Context, req *request.RefreshTokenRequest) (*response.RefreshTokenResponse, error) {
-	D.logger.Log(logger.InfoLevel, "Refresh Token Incoming")
-
-	var storedRefreshToken string
-	query := `
-        SELECT refresh_token FROM sessions WHERE session_id = $1
-    `
-	err := D.DB.QueryRowContext(ctx, query, req.SessionID).Scan(&storedRefreshToken)
-	if err != nil {
-		D.logger.Log(logger.ErrorLevel, "Failed to retrieve refresh token: %v", err)
-		return nil, err
-	}
-
-	if storedRefreshToken != req.RefreshToken {
-		D.logger.Log(logger.ErrorLevel, "Refresh token mismatch")
-		return nil, errors.New("invalid refresh token")
-	}
-
-	query = `
-        SELECT u.user_id, u.email, u.role, u.is_email_verified
-        FROM sessions s
-        JOIN users u ON s.user_id = u.user_id
-        WHERE s.session_id = $1
-    `
-	var user types.UserInfo
-	err = D.DB.QueryRowContext(ctx, query, req.SessionID).Scan(
-		&user.UserID,
-		&user.Email,
-		&user.Role,
-		&user.IsEmailVerified,
-	)
-	if err != nil {
-		D.logger.Log(logger.ErrorLevel, "Failed to retrieve user: %v", err)
-		return nil, err
-	}
-
-	accces_token, err := middleware.GenerateToken(&user, 24)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
-	}
-
-	updateQuery := `
-        UPDATE sessions SET access_token = $1 WHERE session_id = $2
-    `
-	_, err = D.DB.ExecContext(ctx, updateQuery, accces_token, req.SessionID)
-	if err != nil {
-		D.logger.Log(logger.ErrorLevel, "Failed to update session: %v", err)
-		return nil, err
-	}
-
-	return &response.RefreshTokenResponse{
-		AccessToken:  accces_token,
-		RefreshToken: req.RefreshToken,
-		ExpiresAt:    time.Now().Add(24 * time.Hour).Unix(),
-	}, nil
-}
-
 func (D *AuthRepository) ListSessions(ctx context.Context, req *request.ListSessionRequest) (*response.ListSessionResponse, error) {
 	query := `
         SELECT 
@@ -64,22 +64,18 @@ func (D *AuthRepository) CreateUser(ctx context.Context, req *request.CreateUser
 	if err != nil {
 		return response.CreateUserResponse{}, fmt.Errorf("failed to generate tokens: %w", err)
 	}
-	refresh_token, err := middleware.GenerateToken(&types.UserInfo{
-		UserID:          userID,
-		Name:            req.Name,
-		Email:           req.Email,
-		Role:            role,
-		IsEmailVerified: false,
-	}, 168)
+	// Registration does not hand out a refresh token; the session only
+	// needs a placeholder hash until the first login starts a family.
+	_, refreshHash, err := NewRefreshToken()
 	if err != nil {
-		return response.CreateUserResponse{}, fmt.Errorf("failed to generate tokens: %w", err)
+		return response.CreateUserResponse{}, err
 	}
 
 	session := types.Session{
 		SessionID:      uuid.New().String(),
 		UserID:         userID,
 		AccessToken:    accces_token,
-		RefreshToken:   refresh_token,
+		RefreshToken:   refreshHash,
 		IPAddress:      req.IPAddress,
 		DeviceInfo:     req.DeviceInfo,
 		CreatedAt:      time.Now().Unix(),
@@ -186,9 +182,9 @@ func (r *AuthRepository) Login(ctx context.Context, login *request.LoginRequest)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate tokens: %w", err)
 	}
-	refresh_token, err := middleware.GenerateToken(userInfo, 168)
+	refresh_token, refreshHash, err := NewRefreshToken()
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate tokens: %w", err)
+		return nil, err
 	}
 
 	// Check for existing session
@@ -220,7 +216,7 @@ func (r *AuthRepository) Login(ctx context.Context, login *request.LoginRequest)
 			SessionID:      uuid.New().String(),
 			UserID:         userInfo.UserID,
 			AccessToken:    access_token,
-			RefreshToken:   refresh_token,
+			RefreshToken:   refreshHash,
 			IPAddress:      login.IPAddress,
 			DeviceInfo:     login.DeviceInfo,
 			CreatedAt:      time.Now().Unix(),
@@ -239,6 +235,12 @@ func (r *AuthRepository) Login(ctx context.Context, login *request.LoginRequest)
 		}
 	}
 
+	// Every login starts a new refresh token family for the session.
+	err = r.StartRefreshFamily(ctx, existingSession.SessionID, userInfo.UserID, access_token, refreshHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
 	_, err = r.DB.ExecContext(
 		ctx,
 		"UPDATE users SET last_login_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE user_id = $1",
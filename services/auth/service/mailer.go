@@ -0,0 +1,68 @@
+package authservice
+
+import (
+	"fmt"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	config "github.com/wafi04/backend/config/development"
+)
+
+// Mailer delivers account emails.
+type Mailer interface {
+	SendPasswordReset(to, name, link string) error
+}
+
+type smtpMailer struct {
+	host     string
+	port     int
+	user     string
+	password string
+}
+
+// NewSMTPMailerFromEnv reads SMTP_HOST, SMTP_PORT and SMTP_USER, defaulting
+// to Gmail, and APP_PASSWORD, the same password the verification email uses.
+func NewSMTPMailerFromEnv() Mailer {
+	host := config.LoadEnv("SMTP_HOST")
+	if host == "" {
+		host = "smtp.gmail.com"
+	}
+	port, err := strconv.Atoi(config.LoadEnv("SMTP_PORT"))
+	if err != nil || port <= 0 {
+		port = 587
+	}
+	return &smtpMailer{
+		host:     host,
+		port:     port,
+		user:     config.LoadEnv("SMTP_USER"),
+		password: strings.ReplaceAll(config.LoadEnv("APP_PASSWORD"), " ", ""),
+	}
+}
+
+func (m *smtpMailer) SendPasswordReset(to, name, link string) error {
+	if strings.ContainsAny(to, "\r\n") {
+		return fmt.Errorf("invalid recipient address")
+	}
+	body := fmt.Sprintf("Hi %s,\r\n\r\n"+
+		"We received a request to reset your password. Open the link below to choose a new one. "+
+		"It expires in %d minutes and can be used once.\r\n\r\n%s\r\n\r\n"+
+		"If you did not ask for this, you can ignore this email; your password stays the same.\r\n",
+		name, int(PasswordResetTTL.Minutes()), link)
+
+	msg := strings.Join([]string{
+		"From: " + m.user,
+		"To: " + to,
+		"Subject: Reset your password",
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+
+	auth := smtp.PlainAuth("", m.user, m.password, m.host)
+	if err := smtp.SendMail(fmt.Sprintf("%s:%d", m.host, m.port), auth, m.user, []string{to}, []byte(msg)); err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package authservice
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	config "github.com/wafi04/backend/config/development"
+	"github.com/wafi04/backend/pkg/logger"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordResetTTL is how long a reset link stays valid.
+const PasswordResetTTL = time.Hour
+
+// MinPasswordLength is the shortest password a reset accepts.
+const MinPasswordLength = 8
+
+// RequestPasswordReset emails a reset link when email belongs to an active
+// user. It behaves the same whether or not the user exists: the email is
+// sent in the background, so neither the result nor the response time
+// tells the caller which emails are registered.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, req *request.RequestPasswordResetRequest) error {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return fmt.Errorf("failed to generate reset token: %v", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	user, err := s.AuthRepository.CreatePasswordResetToken(ctx, req.Email, hashResetToken(token), time.Now().Add(PasswordResetTTL))
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	link := passwordResetURL() + "?token=" + url.QueryEscape(token)
+	go func() {
+		if err := s.mailer.SendPasswordReset(user.Email, user.Name, link); err != nil {
+			s.log.Log(logger.ErrorLevel, "Failed to send password reset email to %s: %v", user.UserID, err)
+		}
+	}()
+	return nil
+}
+
+// ResetPassword sets a new password with a token from RequestPasswordReset
+// and signs the user out everywhere.
+func (s *AuthService) ResetPassword(ctx context.Context, req *request.ResetPasswordRequest) (*response.ResetPasswordResponse, error) {
+	if len(req.NewPassword) < MinPasswordLength {
+		return nil, fmt.Errorf("invalid password: must be at least %d characters", MinPasswordLength)
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), 10)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %v", err)
+	}
+	return s.AuthRepository.ResetPassword(ctx, hashResetToken(req.ResetToken), string(hashed))
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// passwordResetURL reads PASSWORD_RESET_URL, the frontend page that takes
+// the token and asks for the new password.
+func passwordResetURL() string {
+	if u := config.LoadEnv("PASSWORD_RESET_URL"); u != "" {
+		return u
+	}
+	return "http://localhost:3000/reset-password"
+}
@@ -13,12 +13,14 @@ import (
 
 type AuthService struct {
 	AuthRepository *authrepo.AuthRepository
+	mailer         Mailer
 	log            logger.Logger
 }
 
 func NewAuthService(authrepo *authrepo.AuthRepository) *AuthService {
 	return &AuthService{
 		AuthRepository: authrepo,
+		mailer:         NewSMTPMailerFromEnv(),
 	}
 }
 
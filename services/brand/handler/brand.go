@@ -0,0 +1,149 @@
+package brandhandler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/backend/pkg/logger"
+	httpresponse "github.com/wafi04/backend/pkg/response"
+	request "github.com/wafi04/backend/pkg/types/req"
+	"github.com/wafi04/backend/pkg/utils"
+	brandservice "github.com/wafi04/backend/services/brand/service"
+	"github.com/wafi04/backend/services/files"
+)
+
+type BrandHandler struct {
+	brandService *brandservice.BrandService
+	log          logger.Logger
+	filesclient  files.FileStorage
+}
+
+func NewBrandHandler(service *brandservice.BrandService, files files.FileStorage) *BrandHandler {
+	return &BrandHandler{
+		brandService: service,
+		filesclient:  files,
+	}
+}
+
+// uploadImage uploads the optional "file" form field and returns its URL,
+// or nil when no file was sent.
+func (h *BrandHandler) uploadImage(c *gin.Context) (*string, error) {
+	file, _, err := c.Request.FormFile("file")
+	if err != nil {
+		return nil, nil
+	}
+	defer file.Close()
+
+	uploadResponse, err := h.filesclient.UploadFile(c, &request.FileUploadRequest{
+		FileData: file,
+		Folder:   "brands",
+		PublicID: utils.GenerateRandomId("BRD"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &uploadResponse.URL, nil
+}
+
+func brandErrorStatus(err error) int {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		return http.StatusNotFound
+	case strings.Contains(err.Error(), "invalid"):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *BrandHandler) HandleCreateBrand(c *gin.Context) {
+	if err := c.Request.ParseMultipartForm(10 << 20); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to parse form data", err.Error())
+		return
+	}
+
+	name := c.PostForm("name")
+	if name == "" {
+		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Name is Required")
+		return
+	}
+
+	image, err := h.uploadImage(c)
+	if err != nil {
+		httpresponse.SendUploadError(c, err, http.StatusInternalServerError, "Failed to upload image")
+		return
+	}
+
+	resp, err := h.brandService.CreateBrand(c, &request.CreateBrandRequest{
+		Name:        name,
+		Description: c.PostForm("description"),
+		Image:       image,
+	})
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, brandErrorStatus(err), "Failed to create brand", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusCreated, "Brand Created", resp)
+}
+
+func (h *BrandHandler) HandleUpdateBrand(c *gin.Context) {
+	if err := c.Request.ParseMultipartForm(10 << 20); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to parse form data", err.Error())
+		return
+	}
+
+	req := &request.UpdateBrandRequest{ID: c.Param("id")}
+	if name, ok := c.GetPostForm("name"); ok {
+		req.Name = &name
+	}
+	if description, ok := c.GetPostForm("description"); ok {
+		req.Description = &description
+	}
+
+	image, err := h.uploadImage(c)
+	if err != nil {
+		httpresponse.SendUploadError(c, err, http.StatusInternalServerError, "Failed to upload image")
+		return
+	}
+	req.Image = image
+
+	resp, err := h.brandService.UpdateBrand(c, req)
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, brandErrorStatus(err), "Failed to update brand", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Brand Updated", resp)
+}
+
+func (h *BrandHandler) HandleListBrands(c *gin.Context) {
+	resp, err := h.brandService.ListBrands(c)
+	if err != nil {
+		h.log.Log(logger.ErrorLevel, "Error listing brands: %v", err)
+		httpresponse.SendErrorResponse(c, http.StatusInternalServerError, "Error retrieving brands")
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Brands Retrieved Successfully", resp)
+}
+
+func (h *BrandHandler) HandleGetBrandBySlug(c *gin.Context) {
+	resp, err := h.brandService.GetBrandBySlug(c, c.Param("slug"))
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, brandErrorStatus(err), "Failed to get brand", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Brand Retrieved Successfully", resp)
+}
+
+func (h *BrandHandler) HandleDeleteBrand(c *gin.Context) {
+	if err := h.brandService.DeleteBrand(c, c.Param("id")); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, brandErrorStatus(err), "Failed to delete brand", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Brand Deleted", nil)
+}
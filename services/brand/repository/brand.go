@@ -0,0 +1,203 @@
+package brand
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+	"github.com/wafi04/backend/pkg/utils"
+)
+
+type BrandRepository interface {
+	Create(ctx context.Context, brand *types.Brand) (*types.Brand, error)
+	GetBySlug(ctx context.Context, slug string) (*types.Brand, error)
+	List(ctx context.Context) (*response.ListBrandsResponse, error)
+	Update(ctx context.Context, req *request.UpdateBrandRequest) (*types.Brand, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type brandRepository struct {
+	db *sqlx.DB
+}
+
+func NewBrandRepository(db *sqlx.DB) BrandRepository {
+	return &brandRepository{db: db}
+}
+
+const brandColumns = `id, name, slug, description, image, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBrand(row rowScanner) (*types.Brand, error) {
+	var brand types.Brand
+	var image sql.NullString
+	if err := row.Scan(
+		&brand.ID,
+		&brand.Name,
+		&brand.Slug,
+		&brand.Description,
+		&image,
+		&brand.CreatedAt,
+		&brand.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if image.Valid {
+		brand.Image = &image.String
+	}
+	return &brand, nil
+}
+
+func (r *brandRepository) Create(ctx context.Context, brand *types.Brand) (*types.Brand, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	slug, err := utils.GenerateSlug(ctx, tx, "brands", "", brand.Name, brand.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := scanBrand(tx.QueryRowContext(ctx, `
+        INSERT INTO brands (id, name, slug, description, image, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+        RETURNING `+brandColumns,
+		brand.ID, brand.Name, slug, brand.Description, brand.Image,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert brand: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return created, nil
+}
+
+func (r *brandRepository) GetBySlug(ctx context.Context, slug string) (*types.Brand, error) {
+	brand, err := scanBrand(r.db.QueryRowContext(ctx, `
+        SELECT `+brandColumns+` FROM brands WHERE slug = $1`, slug))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("brand not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get brand: %v", err)
+	}
+	return brand, nil
+}
+
+func (r *brandRepository) List(ctx context.Context) (*response.ListBrandsResponse, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT `+brandColumns+` FROM brands ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query brands: %v", err)
+	}
+	defer rows.Close()
+
+	brands := make([]*types.Brand, 0)
+	for rows.Next() {
+		brand, err := scanBrand(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan brand: %v", err)
+		}
+		brands = append(brands, brand)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating brands: %v", err)
+	}
+
+	return &response.ListBrandsResponse{Brands: brands}, nil
+}
+
+func (r *brandRepository) Update(ctx context.Context, req *request.UpdateBrandRequest) (*types.Brand, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	updates := []string{"updated_at = CURRENT_TIMESTAMP"}
+	args := []interface{}{}
+	argCount := 1
+
+	if req.Name != nil {
+		var currentName string
+		err = tx.QueryRowContext(ctx,
+			"SELECT name FROM brands WHERE id = $1 FOR UPDATE", req.ID,
+		).Scan(&currentName)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("brand not found")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get brand: %v", err)
+		}
+
+		updates = append(updates, fmt.Sprintf("name = $%d", argCount))
+		args = append(args, *req.Name)
+		argCount++
+
+		if *req.Name != currentName {
+			slug, err := utils.GenerateSlug(ctx, tx, "brands", "", *req.Name, req.ID)
+			if err != nil {
+				return nil, err
+			}
+			updates = append(updates, fmt.Sprintf("slug = $%d", argCount))
+			args = append(args, slug)
+			argCount++
+		}
+	}
+
+	if req.Description != nil {
+		updates = append(updates, fmt.Sprintf("description = $%d", argCount))
+		args = append(args, *req.Description)
+		argCount++
+	}
+
+	if req.Image != nil {
+		updates = append(updates, fmt.Sprintf("image = $%d", argCount))
+		args = append(args, *req.Image)
+		argCount++
+	}
+
+	query := "UPDATE brands SET " + strings.Join(updates, ", ") +
+		fmt.Sprintf(" WHERE id = $%d RETURNING ", argCount) + brandColumns
+	args = append(args, req.ID)
+
+	brand, err := scanBrand(tx.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("brand not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update brand: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return brand, nil
+}
+
+// Delete removes a brand. Its products keep existing without a brand.
+func (r *brandRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM brands WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete brand: %v", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("brand not found")
+	}
+	return nil
+}
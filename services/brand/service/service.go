@@ -0,0 +1,55 @@
+package brandservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+	brand "github.com/wafi04/backend/services/brand/repository"
+)
+
+type BrandService struct {
+	brandRepo brand.BrandRepository
+}
+
+func NewBrandService(brandRepo brand.BrandRepository) *BrandService {
+	return &BrandService{
+		brandRepo: brandRepo,
+	}
+}
+
+func (s *BrandService) CreateBrand(ctx context.Context, req *request.CreateBrandRequest) (*types.Brand, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("invalid brand: name is required")
+	}
+
+	return s.brandRepo.Create(ctx, &types.Brand{
+		ID:          uuid.New().String(),
+		Name:        req.Name,
+		Description: req.Description,
+		Image:       req.Image,
+	})
+}
+
+func (s *BrandService) GetBrandBySlug(ctx context.Context, slug string) (*types.Brand, error) {
+	return s.brandRepo.GetBySlug(ctx, slug)
+}
+
+func (s *BrandService) ListBrands(ctx context.Context) (*response.ListBrandsResponse, error) {
+	return s.brandRepo.List(ctx)
+}
+
+func (s *BrandService) UpdateBrand(ctx context.Context, req *request.UpdateBrandRequest) (*types.Brand, error) {
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		return nil, fmt.Errorf("invalid brand: name cannot be empty")
+	}
+	return s.brandRepo.Update(ctx, req)
+}
+
+func (s *BrandService) DeleteBrand(ctx context.Context, id string) error {
+	return s.brandRepo.Delete(ctx, id)
+}
@@ -0,0 +1,193 @@
+package cart
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+)
+
+func (d *Database) getBundlePrice(ctx context.Context, tx *sql.Tx, bundleID string) (float64, error) {
+	var price float64
+	err := tx.QueryRowContext(ctx, `
+		SELECT price
+		FROM products
+		WHERE id = $1 AND product_type = $2 AND deleted_at IS NULL
+		AND status = 'published'
+		AND (unpublish_at IS NULL OR unpublish_at > NOW())
+	`, bundleID, types.ProductTypeBundle).Scan(&price)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("bundle not found")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get bundle price: %w", err)
+	}
+	return price, nil
+}
+
+// addBundle adds a bundle line to the cart at the bundle price and reserves
+// the inventory of each of its components.
+func (d *Database) addBundle(ctx context.Context, tx *sql.Tx, cartID string, req *request.CartRequest) error {
+	price, err := d.getBundlePrice(ctx, tx, req.BundleID)
+	if err != nil {
+		return err
+	}
+
+	var itemID string
+	var existingQuantity int64
+	err = tx.QueryRowContext(ctx, `
+        SELECT cart_item_id, quantity
+        FROM cart_items
+        WHERE cart_id = $1
+        AND bundle_id = $2
+    `, cartID, req.BundleID).Scan(&itemID, &existingQuantity)
+
+	if err == sql.ErrNoRows {
+		itemID = uuid.New().String()
+		_, err = tx.ExecContext(ctx, `
+            INSERT INTO cart_items (
+                cart_item_id, cart_id, bundle_id,
+                size, quantity, sub_total
+            )
+            VALUES ($1, $2, $3, '', $4, $5)
+        `, itemID, cartID, req.BundleID, req.Quantity, float64(req.Quantity)*price)
+		if err != nil {
+			return fmt.Errorf("failed to insert cart item: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to check existing item: %w", err)
+	} else {
+		newQuantity := existingQuantity + req.Quantity
+		_, err = tx.ExecContext(ctx, `
+            UPDATE cart_items
+            SET quantity = $1,
+                sub_total = $2,
+                updated_at = CURRENT_TIMESTAMP
+            WHERE cart_item_id = $3
+        `, newQuantity, float64(newQuantity)*price, itemID)
+		if err != nil {
+			return fmt.Errorf("failed to update cart item: %w", err)
+		}
+	}
+
+	return d.reserveBundle(ctx, tx, itemID, req.BundleID, req.Quantity)
+}
+
+type componentReservation struct {
+	inventoryID string
+	quantity    int64
+	available   int64
+}
+
+// reserveBundle moves the component stock for quantity bundles from
+// available to reserved and records it against the cart item, failing when
+// any component is short.
+func (d *Database) reserveBundle(ctx context.Context, tx *sql.Tx, cartItemID, bundleID string, quantity int64) error {
+	rows, err := tx.QueryContext(ctx, `
+        SELECT i.id, b.quantity * $2, COALESCE(i.available_stock, 0)
+        FROM bundle_items b
+        JOIN inventory i ON i.variant_id = b.variant_id AND i.size = b.size
+        WHERE b.bundle_id = $1
+        ORDER BY i.id
+        FOR UPDATE OF i
+    `, bundleID, quantity)
+	if err != nil {
+		return fmt.Errorf("failed to lock bundle inventory: %w", err)
+	}
+
+	var components []componentReservation
+	for rows.Next() {
+		var c componentReservation
+		if err := rows.Scan(&c.inventoryID, &c.quantity, &c.available); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan bundle inventory: %w", err)
+		}
+		components = append(components, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to lock bundle inventory: %w", err)
+	}
+
+	// The inventory join drops components that have no inventory row, so
+	// check every bundle item was locked before reserving any of them.
+	var itemCount int
+	err = tx.QueryRowContext(ctx, `
+        SELECT COUNT(*) FROM bundle_items WHERE bundle_id = $1
+    `, bundleID).Scan(&itemCount)
+	if err != nil {
+		return fmt.Errorf("failed to count bundle items: %w", err)
+	}
+	if itemCount == 0 {
+		return fmt.Errorf("bundle has no items")
+	}
+	if len(components) != itemCount {
+		return fmt.Errorf("not enough stock for bundle: %d of %d components have no inventory", itemCount-len(components), itemCount)
+	}
+
+	for _, c := range components {
+		if c.available < c.quantity {
+			return fmt.Errorf("not enough stock for bundle")
+		}
+	}
+
+	for _, c := range components {
+		_, err := tx.ExecContext(ctx, `
+            UPDATE inventory
+            SET reserved_stock = COALESCE(reserved_stock, 0) + $1,
+                available_stock = available_stock - $1
+            WHERE id = $2
+        `, c.quantity, c.inventoryID)
+		if err != nil {
+			return fmt.Errorf("failed to reserve inventory: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+            INSERT INTO inventory_reservations (id, cart_item_id, inventory_id, quantity)
+            VALUES ($1, $2, $3, $4)
+            ON CONFLICT (cart_item_id, inventory_id)
+            DO UPDATE SET quantity = inventory_reservations.quantity + EXCLUDED.quantity
+        `, uuid.New().String(), cartItemID, c.inventoryID, c.quantity)
+		if err != nil {
+			return fmt.Errorf("failed to record reservation: %w", err)
+		}
+	}
+	return nil
+}
+
+// releaseReservations returns the stock reserved for the given cart items to
+// the available pool and drops the reservation rows.
+func (d *Database) releaseReservations(ctx context.Context, tx *sql.Tx, cartItemIDs []string) error {
+	if len(cartItemIDs) == 0 {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, `
+        UPDATE inventory i
+        SET reserved_stock = i.reserved_stock - r.quantity,
+            available_stock = i.available_stock + r.quantity
+        FROM (
+            SELECT inventory_id, SUM(quantity) AS quantity
+            FROM inventory_reservations
+            WHERE cart_item_id = ANY($1)
+            GROUP BY inventory_id
+        ) r
+        WHERE i.id = r.inventory_id
+    `, pq.Array(cartItemIDs))
+	if err != nil {
+		return fmt.Errorf("failed to release inventory: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        DELETE FROM inventory_reservations WHERE cart_item_id = ANY($1)
+    `, pq.Array(cartItemIDs))
+	if err != nil {
+		return fmt.Errorf("failed to delete reservations: %w", err)
+	}
+	return nil
+}
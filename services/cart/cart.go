@@ -50,6 +50,10 @@ func (d *Database) RemoveFromCart(ctx context.Context, req *request.ReqRemoveCar
 		return nil, fmt.Errorf("failed to get cart_id: %w", err)
 	}
 
+	if err := d.releaseReservations(ctx, tx, []string{req.CartItemID}); err != nil {
+		return nil, err
+	}
+
 	queryDelete := `
         DELETE FROM cart_items 
         WHERE cart_item_id = $1
@@ -77,15 +81,49 @@ func (d *Database) RemoveFromCart(ctx context.Context, req *request.ReqRemoveCar
 }
 
 func (d *Database) ClearCart(ctx context.Context, req *request.ClearCart) (*response.ResRemoveCartItem, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var itemIDs []string
+	rows, err := tx.QueryContext(ctx, `
+		SELECT ci.cart_item_id
+		FROM cart_items ci
+		JOIN carts c ON c.cart_id = ci.cart_id
+		WHERE c.user_id = $1
+	`, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart items: %w", err)
+	}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan cart item: %w", err)
+		}
+		itemIDs = append(itemIDs, id)
+	}
+	rows.Close()
+
+	if err := d.releaseReservations(ctx, tx, itemIDs); err != nil {
+		return nil, err
+	}
+
 	query := `
 		DELETE FROM carts
 		WHERE user_id = $1 
 	`
-	_, err := d.db.ExecContext(ctx, query, req.UserID)
+	_, err = tx.ExecContext(ctx, query, req.UserID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to clear cart : %v", err)
 	}
 
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return &response.ResRemoveCartItem{
 		Success: true,
 	}, nil
@@ -99,11 +137,13 @@ func (d *Database) UpdateQuantity(ctx context.Context, req *request.UpdateQuanti
 	defer tx.Rollback()
 
 	var cart types.CartItem
+	var bundleID sql.NullString
 	queryGet := `
 		SELECT 
 			cart_item_id,
 			cart_id,
-			product_variant_id,
+			COALESCE(product_variant_id, ''),
+			bundle_id,
 			quantity,
 			size,
 			sub_total,
@@ -116,6 +156,7 @@ func (d *Database) UpdateQuantity(ctx context.Context, req *request.UpdateQuanti
 		&cart.CartItemID,
 		&cart.CartID,
 		&cart.VariantID,
+		&bundleID,
 		&cart.Quantity,
 		&cart.Size,
 		&cart.SubTotal,
@@ -129,9 +170,25 @@ func (d *Database) UpdateQuantity(ctx context.Context, req *request.UpdateQuanti
 		return nil, fmt.Errorf("failed to get cart item: %w", err)
 	}
 
-	price, err := d.getProductPrice(ctx, tx, cart.VariantID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get price from product : %v", err)
+	var price float64
+	size := req.Size
+	if bundleID.Valid {
+		price, err = d.getBundlePrice(ctx, tx, bundleID.String)
+		if err != nil {
+			return nil, err
+		}
+		if err := d.releaseReservations(ctx, tx, []string{cart.CartItemID}); err != nil {
+			return nil, err
+		}
+		if err := d.reserveBundle(ctx, tx, cart.CartItemID, bundleID.String, req.Quantity); err != nil {
+			return nil, err
+		}
+		size = cart.Size
+	} else {
+		price, err = d.getProductPrice(ctx, tx, cart.VariantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get price from product : %v", err)
+		}
 	}
 
 	newSubTotal := float64(req.Quantity) * price
@@ -147,7 +204,7 @@ func (d *Database) UpdateQuantity(ctx context.Context, req *request.UpdateQuanti
 		RETURNING 
 			cart_item_id,
 			cart_id,
-			product_variant_id,
+			COALESCE(product_variant_id, ''),
 			quantity,
 			size,
 			sub_total,
@@ -158,7 +215,7 @@ func (d *Database) UpdateQuantity(ctx context.Context, req *request.UpdateQuanti
 	err = tx.QueryRowContext(ctx, queryUpdate,
 		req.Quantity,
 		newSubTotal,
-		req.Size,
+		size,
 		now,
 		req.CartItemID,
 	).Scan(
@@ -174,6 +231,9 @@ func (d *Database) UpdateQuantity(ctx context.Context, req *request.UpdateQuanti
 	if err != nil {
 		return nil, fmt.Errorf("failed to update cart item: %w", err)
 	}
+	if bundleID.Valid {
+		cart.BundleID = &bundleID.String
+	}
 
 	err = d.UpdateCartTotal(ctx, tx, cart.CartID)
 	if err != nil {
@@ -217,7 +277,8 @@ func (d *Database) GetCart(ctx context.Context, userID string) (*types.Cart, err
     SELECT 
         ci.cart_item_id,
         ci.cart_id,
-        ci.product_variant_id,
+        COALESCE(ci.product_variant_id, ''),
+        ci.bundle_id,
         ci.size,
         ci.quantity,
         ci.sub_total,
@@ -226,11 +287,14 @@ func (d *Database) GetCart(ctx context.Context, userID string) (*types.Cart, err
         pi.url AS image_url,
         pv.color,           
         pv.sku,            
-        p.name AS product_name 
+        COALESCE(p.name, bp.name) AS product_name,
+        (pv.deleted_at IS NOT NULL OR p.deleted_at IS NOT NULL
+            OR bp.deleted_at IS NOT NULL) AS unavailable
     FROM cart_items ci
     LEFT JOIN product_variants pv ON ci.product_variant_id = pv.id
     LEFT JOIN products p ON pv.product_id = p.id
-    LEFT JOIN product_images pi ON pv.id = pi.variant_id AND pi.is_main = TRUE
+    LEFT JOIN products bp ON ci.bundle_id = bp.id
+    LEFT JOIN product_images pi ON pv.id = pi.variant_id AND pi.is_main = TRUE AND pi.deleted_at IS NULL
     WHERE ci.cart_id = $1
     `
 	rows, err := d.db.QueryContext(ctx, queryItems, cart.CartID)
@@ -246,6 +310,7 @@ func (d *Database) GetCart(ctx context.Context, userID string) (*types.Cart, err
 			&item.CartItemID,
 			&item.CartID,
 			&item.VariantID,
+			&item.BundleID,
 			&item.Size,
 			&item.Quantity,
 			&item.SubTotal,
@@ -255,10 +320,17 @@ func (d *Database) GetCart(ctx context.Context, userID string) (*types.Cart, err
 			&item.Color,
 			&item.SKU,
 			&item.ProductName,
+			&item.Unavailable,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan cart item: %w", err)
 		}
+		// Lines whose product has been trashed stay in the cart so the
+		// shopper can see what was removed, but no longer count towards
+		// the total.
+		if item.Unavailable {
+			cart.Total -= item.SubTotal
+		}
 		items = append(items, item)
 	}
 
@@ -29,9 +29,19 @@ func (d *Database) getProductPrice(ctx context.Context, tx *sql.Tx, variantID st
 	var price float64
 	d.logger.Log(logger.InfoLevel, "VariantId : %s", variantID)
 	getProductPriceQuery := `
-		SELECT price FROM product_variants WHERE id = $1`
+		SELECT COALESCE(v.price, p.price)
+		FROM product_variants v
+		JOIN products p ON p.id = v.product_id
+		WHERE v.id = $1
+		AND v.deleted_at IS NULL
+		AND p.deleted_at IS NULL
+		AND p.status = 'published'
+		AND (p.unpublish_at IS NULL OR p.unpublish_at > NOW())`
 
 	err := tx.QueryRowContext(ctx, getProductPriceQuery, variantID).Scan(&price)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("product variant not found")
+	}
 	if err != nil {
 		return 0, fmt.Errorf("failed to get product price: %w", err)
 	}
@@ -60,7 +70,28 @@ func (d *Database) AddCart(ctx context.Context, req *request.CartRequest) (*resp
 		return nil, fmt.Errorf("failed to query cart: %w", err)
 	}
 
-	subTotal := float64(req.Quantity) * req.Price
+	if req.BundleID != "" {
+		if err := d.addBundle(ctx, tx, cartID, req); err != nil {
+			return nil, err
+		}
+		if err := d.UpdateCartTotal(ctx, tx, cartID); err != nil {
+			return nil, fmt.Errorf("failed to update cart total: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return &response.CartResponse{
+			BundleID: req.BundleID,
+			Quantity: req.Quantity,
+		}, nil
+	}
+
+	price, err := d.getProductPrice(ctx, tx, req.VariantID)
+	if err != nil {
+		return nil, err
+	}
+
+	subTotal := float64(req.Quantity) * price
 	var existingItemID string
 	var existingQuantity int
 
@@ -89,7 +120,7 @@ func (d *Database) AddCart(ctx context.Context, req *request.CartRequest) (*resp
 		return nil, fmt.Errorf("failed to check existing item: %w", err)
 	} else {
 		newQuantity := existingQuantity + int(req.Quantity)
-		newSubTotal := float64(newQuantity) * req.Price
+		newSubTotal := float64(newQuantity) * price
 
 		_, err = tx.ExecContext(ctx, `
             UPDATE cart_items 
@@ -26,6 +26,7 @@ func NewCartHandler(service *CartService) *CartHandler {
 func (h *CartHandler) HandleAddToCart(c *gin.Context) {
 	var req struct {
 		VariantID string  `json:"variant_id"`
+		BundleID  string  `json:"bundle_id"`
 		Size      string  `json:"size"`
 		Quantity  int64   `json:"quantity"`
 		Total     float64 `json:"total"`
@@ -45,6 +46,7 @@ func (h *CartHandler) HandleAddToCart(c *gin.Context) {
 	}
 	cart, err := h.cartservice.AddToCart(c, &request.CartRequest{
 		VariantID: req.VariantID,
+		BundleID:  req.BundleID,
 		Size:      req.Size,
 		Quantity:  req.Quantity,
 		UserID:    user.UserID,
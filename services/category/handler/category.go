@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/wafi04/backend/pkg/logger"
 	httpresponse "github.com/wafi04/backend/pkg/response"
+	"github.com/wafi04/backend/pkg/types"
 	request "github.com/wafi04/backend/pkg/types/req"
 	"github.com/wafi04/backend/pkg/utils"
 	"github.com/wafi04/backend/services/category/service"
@@ -17,10 +18,10 @@ import (
 type CategoryHandler struct {
 	categoryService *service.CategoryService
 	log             logger.Logger
-	filesclient     *files.Cloudinary
+	filesclient     files.FileStorage
 }
 
-func NewCategoryHandler(service *service.CategoryService, files *files.Cloudinary) *CategoryHandler {
+func NewCategoryHandler(service *service.CategoryService, files files.FileStorage) *CategoryHandler {
 	return &CategoryHandler{
 		categoryService: service,
 		filesclient:     files,
@@ -67,7 +68,7 @@ func (h *CategoryHandler) HandleCreateCategory(c *gin.Context) {
 
 		uploadResponse, err := h.filesclient.UploadFile(c, uploadRequest)
 		if err != nil {
-			httpresponse.SendErrorResponse(c, http.StatusInternalServerError, "Failed to read file")
+			httpresponse.SendUploadError(c, err, http.StatusInternalServerError, "Failed to read file")
 			return
 		}
 
@@ -84,11 +85,23 @@ func (h *CategoryHandler) HandleCreateCategory(c *gin.Context) {
 		imageUrlPtr = &imageUrl
 	}
 
+	var positionPtr *int32
+	if raw := c.PostForm("position"); raw != "" {
+		position, err := strconv.Atoi(raw)
+		if err != nil {
+			httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Position must be a number")
+			return
+		}
+		pos := int32(position)
+		positionPtr = &pos
+	}
+
 	resp, err := h.categoryService.CreateCategory(c, &request.CreateCategoryRequest{
 		Name:        name,
 		Description: description,
 		Image:       imageUrlPtr,
 		ParentID:    parentIDPtr,
+		Position:    positionPtr,
 	})
 
 	if err != nil {
@@ -144,7 +157,7 @@ func (h *CategoryHandler) HandleUpdateCategory(c *gin.Context) {
 
 		uploadResponse, err := h.filesclient.UploadFile(c, uploadRequest)
 		if err != nil {
-			httpresponse.SendErrorResponse(c, http.StatusInternalServerError, "Failed to read file")
+			httpresponse.SendUploadError(c, err, http.StatusInternalServerError, "Failed to read file")
 			return
 		}
 
@@ -208,6 +221,30 @@ func (h *CategoryHandler) HandleGetCategory(c *gin.Context) {
 	httpresponse.SendSuccessResponse(c, http.StatusOK, "Category  Retrieved Successfully", resp)
 }
 
+func (h *CategoryHandler) HandleGetCategoryBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+
+	if slug == "" {
+		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Slug is required")
+		return
+	}
+
+	resp, err := h.categoryService.GetCategoryBySlug(c, &request.GetCategoryBySlugRequest{
+		Slug: slug,
+	})
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			httpresponse.SendErrorResponse(c, http.StatusNotFound, "Category Not Found")
+		default:
+			httpresponse.SendErrorResponse(c, http.StatusInternalServerError, "Internal Server Error")
+		}
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Category Retrieved Successfully", resp)
+}
+
 func (h *CategoryHandler) HandleDeleteCategory(c *gin.Context) {
 	id := c.Param("id")
 
@@ -222,23 +259,165 @@ func (h *CategoryHandler) HandleDeleteCategory(c *gin.Context) {
 		return
 	}
 	updateReq := &request.DeleteCategoryRequest{
-		ID:             id,
-		DeleteChildren: false,
+		ID:       id,
+		Strategy: c.Query("strategy"),
+		TargetID: c.Query("target_id"),
 	}
 
 	category, err := h.categoryService.DeleteCategory(c, updateReq)
+	if err != nil {
+		h.sendDeleteError(c, err)
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Delete Category Succesfully", category)
+}
+
+// HandleDeleteCategoryPreview runs a delete with the same ?strategy= and
+// ?target_id= as HandleDeleteCategory and reports the counts it would
+// affect without committing anything.
+func (h *CategoryHandler) HandleDeleteCategoryPreview(c *gin.Context) {
+	preview, err := h.categoryService.DeleteCategory(c, &request.DeleteCategoryRequest{
+		ID:       c.Param("id"),
+		Strategy: c.Query("strategy"),
+		TargetID: c.Query("target_id"),
+		DryRun:   true,
+	})
+	if err != nil {
+		h.sendDeleteError(c, err)
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Delete Preview", preview)
+}
+
+func (h *CategoryHandler) sendDeleteError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusNotFound, "Category Not Found", err.Error())
+	case strings.Contains(err.Error(), "invalid"):
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid Request", err.Error())
+	default:
+		httpresponse.SendErrorResponse(c, http.StatusInternalServerError, "Internal Server Error")
+	}
+}
+
+func (h *CategoryHandler) HandleGetBreadcrumb(c *gin.Context) {
+	resp, err := h.categoryService.GetBreadcrumb(c, c.Param("id"))
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			httpresponse.SendErrorResponse(c, http.StatusNotFound, "Category Not Found")
+		default:
+			httpresponse.SendErrorResponse(c, http.StatusInternalServerError, "Internal Server Error")
+		}
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Breadcrumb Retrieved Successfully", resp)
+}
+
+// HandleListCategoryProducts lists the products of a category and its
+// descendants, paged with ?limit= and ?page=.
+func (h *CategoryHandler) HandleListCategoryProducts(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	resp, err := h.categoryService.ListCategoryProducts(c, &request.ListCategoryProductsRequest{
+		ID:        c.Param("id"),
+		PageSize:  int32(limit),
+		PageToken: c.Query("page"),
+	})
 	if err != nil {
 		switch {
 		case strings.Contains(err.Error(), "not found"):
 			httpresponse.SendErrorResponse(c, http.StatusNotFound, "Category Not Found")
+		default:
+			httpresponse.SendErrorResponse(c, http.StatusInternalServerError, "Internal Server Error")
+		}
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Category Products Retrieved Successfully", resp)
+}
+
+func (h *CategoryHandler) HandleMoveCategory(c *gin.Context) {
+	var req request.MoveCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+	req.ID = c.Param("id")
+
+	resp, err := h.categoryService.MoveCategory(c, &req)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			httpresponse.SendErrorResponseWithDetails(c, http.StatusNotFound, "Category Not Found", err.Error())
+		case strings.Contains(err.Error(), "invalid"):
+			httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid Move", err.Error())
+		default:
+			httpresponse.SendErrorResponse(c, http.StatusInternalServerError, "Internal Server Error")
+		}
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Move Category Succesfully", resp)
+}
+
+// HandleReorderCategories sets the order of a parent's children; the body
+// lists every child id in the new order.
+func (h *CategoryHandler) HandleReorderCategories(c *gin.Context) {
+	var req request.ReorderCategoriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	if err := h.categoryService.ReorderCategories(c, &req); err != nil {
+		switch {
 		case strings.Contains(err.Error(), "invalid"):
-			httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Invalid Request")
+			httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid Order", err.Error())
 		default:
 			httpresponse.SendErrorResponse(c, http.StatusInternalServerError, "Internal Server Error")
+		}
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Reorder Categories Succesfully", nil)
+}
+
+func (h *CategoryHandler) HandleListTrash(c *gin.Context) {
+	resp, err := h.categoryService.ListTrash(c)
+	if err != nil {
+		h.log.Log(logger.ErrorLevel, "Error listing category trash: %v", err)
+		httpresponse.SendErrorResponse(c, http.StatusInternalServerError, "Error retrieving trash")
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Category Trash Retrieved Successfully", resp)
+}
+
+func (h *CategoryHandler) HandleRestoreCategory(c *gin.Context) {
+	id := c.Param("id")
 
+	if id == "" {
+		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Category ID is required")
+		return
+	}
+
+	resp, err := h.categoryService.RestoreCategory(c, &request.RestoreTrashRequest{
+		ID:   id,
+		Type: types.TrashTypeCategory,
+	})
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			httpresponse.SendErrorResponse(c, http.StatusNotFound, "Category Not Found")
+		default:
+			httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to restore category", err.Error())
 		}
 		return
 	}
 
-	httpresponse.SendSuccessResponse(c, http.StatusOK, "Delete Category Succesfully", category)
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Restore Category Succesfully", resp)
 }
@@ -3,22 +3,33 @@ package category
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 	"github.com/wafi04/backend/pkg/types"
 	request "github.com/wafi04/backend/pkg/types/req"
 	response "github.com/wafi04/backend/pkg/types/res"
+	"github.com/wafi04/backend/pkg/utils"
 )
 
 type CategoryRepository interface {
-	Create(ctx context.Context, category *types.Category, depth int32) (*types.Category, error)
+	Create(ctx context.Context, category *types.Category, depth int32, position *int32) (*types.Category, error)
 	GetParentDepth(ctx context.Context, parentID string) (int32, error)
 	GetCategoryTree(ctx context.Context) (map[string]*types.Category, []*types.Category, error)
 	DeleteCategory(ctx context.Context, req *request.DeleteCategoryRequest) (*response.DeleteCategoryResponse, error)
 	UpdateCategory(ctx context.Context, req *request.UpdateCategoryRequest) (*types.Category, error)
+	GetCategoryBySlug(ctx context.Context, req *request.GetCategoryBySlugRequest) (*response.CategoryBySlugResponse, error)
+	ListDeletedCategories(ctx context.Context) (*response.ListTrashResponse, error)
+	RestoreCategory(ctx context.Context, req *request.RestoreTrashRequest) (*response.RestoreTrashResponse, error)
+	GetBreadcrumb(ctx context.Context, categoryID string) (*response.CategoryBreadcrumbResponse, error)
+	ListCategoryProducts(ctx context.Context, req *request.ListCategoryProductsRequest) (*response.ListProductsResponse, error)
+	MoveCategory(ctx context.Context, req *request.MoveCategoryRequest) (*response.MoveCategoryResponse, error)
+	ReorderCategories(ctx context.Context, req *request.ReorderCategoriesRequest) error
+	PurgeDeletedCategories(ctx context.Context, before time.Time) (int64, error)
 }
 
 type categoryRepository struct {
@@ -29,26 +40,38 @@ func NewCategoryRepository(db *sqlx.DB) CategoryRepository {
 	return &categoryRepository{db: db}
 }
 
-func (r *categoryRepository) Create(ctx context.Context, category *types.Category, depth int32) (*types.Category, error) {
+func (r *categoryRepository) Create(ctx context.Context, category *types.Category, depth int32, position *int32) (*types.Category, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %v", err)
 	}
 	defer tx.Rollback()
 
+	slug, err := utils.GenerateSlug(ctx, tx, "categories", types.SlugEntityCategory, category.Name, category.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	pos, err := insertPosition(ctx, tx, category.ParentID, position)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
         INSERT INTO categories (
             id,
             name,
+            slug,
             description,
             image,
             parent_id,
             depth,
+            position,
             created_at
         ) VALUES (
-            $1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP
+            $1, $2, $3, $4, $5, $6, $7, $8, CURRENT_TIMESTAMP
         )
-        RETURNING id, name, description, image, parent_id, depth, created_at`
+        RETURNING id, name, slug, description, image, parent_id, depth, position, created_at`
 
 	var createdAt sql.NullTime
 	var parentID, image sql.NullString
@@ -56,23 +79,28 @@ func (r *categoryRepository) Create(ctx context.Context, category *types.Categor
 	err = tx.QueryRowContext(ctx, query,
 		category.ID,
 		category.Name,
+		slug,
 		category.Description,
 		category.Image,
 		category.ParentID,
 		depth,
+		pos,
 	).Scan(
 		&category.ID,
 		&category.Name,
+		&category.Slug,
 		&category.Description,
 		&image,
 		&parentID,
 		&depth,
+		&category.Position,
 		&createdAt,
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert category: %v", err)
 	}
+	category.Depth = depth
 
 	if err = tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %v", err)
@@ -93,7 +121,7 @@ func (r *categoryRepository) Create(ctx context.Context, category *types.Categor
 
 func (r *categoryRepository) GetParentDepth(ctx context.Context, parentID string) (int32, error) {
 	var depth int32
-	err := r.db.QueryRowContext(ctx, "SELECT depth FROM categories WHERE id = $1", parentID).Scan(&depth)
+	err := r.db.QueryRowContext(ctx, "SELECT depth FROM categories WHERE id = $1 AND deleted_at IS NULL", parentID).Scan(&depth)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return 0, fmt.Errorf("parent category not found")
@@ -103,31 +131,47 @@ func (r *categoryRepository) GetParentDepth(ctx context.Context, parentID string
 	return depth, nil
 }
 
-func (r *categoryRepository) GetCategoryTree(ctx context.Context) (map[string]*types.Category, []*types.Category, error) {
-	query := `
+// categoryTreeCTE is the recursive walk down the category tree shared by
+// GetCategoryTree and the descendant queries. anchor is the WHERE condition
+// on categories c that picks the rows the walk starts from.
+func categoryTreeCTE(anchor string) string {
+	return `
         WITH RECURSIVE category_tree AS (
             SELECT 
-                c.id, c.name, c.description, c.image, 
-                c.parent_id, c.depth, c.created_at,
+                c.id, c.name, c.slug, c.description, c.image, 
+                c.parent_id, c.depth, c.position, c.created_at,
                 ARRAY[]::VARCHAR[] AS path,
                 0 as level
             FROM categories c
-            WHERE c.parent_id IS NULL
+            WHERE ` + anchor + `
+            AND c.deleted_at IS NULL
             UNION ALL
             SELECT 
-                c.id, c.name, c.description, c.image,
-                c.parent_id, c.depth, c.created_at,
+                c.id, c.name, c.slug, c.description, c.image,
+                c.parent_id, c.depth, c.position, c.created_at,
                 path || c.parent_id,
                 ct.level + 1
             FROM categories c
             INNER JOIN category_tree ct ON ct.id = c.parent_id
-        )
+            WHERE c.deleted_at IS NULL
+        )`
+}
+
+func (r *categoryRepository) GetCategoryTree(ctx context.Context) (map[string]*types.Category, []*types.Category, error) {
+	query := categoryTreeCTE("c.parent_id IS NULL") + `
         SELECT 
-            id, name, description, image,
-            parent_id, depth, created_at,
-            path
-        FROM category_tree
-        ORDER BY path, level`
+            ct.id, ct.name, ct.slug, ct.description, ct.image,
+            ct.parent_id, ct.depth, ct.position, ct.created_at,
+            ct.path, COALESCE(pc.product_count, 0)
+        FROM category_tree ct
+        LEFT JOIN (
+            SELECT category_id, COUNT(*) AS product_count
+            FROM products
+            WHERE deleted_at IS NULL AND status = 'published'
+            AND (unpublish_at IS NULL OR unpublish_at > NOW())
+            GROUP BY category_id
+        ) pc ON pc.category_id = ct.id
+        ORDER BY ct.level, ct.position, ct.name`
 
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
@@ -137,6 +181,7 @@ func (r *categoryRepository) GetCategoryTree(ctx context.Context) (map[string]*t
 
 	categoryMap := make(map[string]*types.Category)
 	var rootCategories []*types.Category
+	var ordered []*types.Category
 	for rows.Next() {
 		var cat types.Category
 		var createdAt sql.NullTime
@@ -146,12 +191,15 @@ func (r *categoryRepository) GetCategoryTree(ctx context.Context) (map[string]*t
 		err := rows.Scan(
 			&cat.ID,
 			&cat.Name,
+			&cat.Slug,
 			&cat.Description,
 			&image,
 			&parentID,
 			&cat.Depth,
+			&cat.Position,
 			&createdAt,
 			&cat.Path,
+			&cat.ProductCount,
 		)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to scan category: %v", err)
@@ -168,7 +216,9 @@ func (r *categoryRepository) GetCategoryTree(ctx context.Context) (map[string]*t
 			cat.CreatedAt = createdAt.Time
 		}
 
+		cat.TotalProductCount = cat.ProductCount
 		categoryMap[cat.ID] = &cat
+		ordered = append(ordered, &cat)
 
 		if !parentID.Valid {
 			rootCategories = append(rootCategories, &cat)
@@ -184,10 +234,28 @@ func (r *categoryRepository) GetCategoryTree(ctx context.Context) (map[string]*t
 		return nil, nil, fmt.Errorf("error iterating categories: %v", err)
 	}
 
+	// Rows come ordered by level, so walking them backwards rolls every
+	// subtree's total up before its parent is reached.
+	for i := len(ordered) - 1; i >= 0; i-- {
+		cat := ordered[i]
+		if cat.ParentID == nil {
+			continue
+		}
+		if parent := categoryMap[*cat.ParentID]; parent != nil {
+			parent.TotalProductCount += cat.TotalProductCount
+		}
+	}
+
 	return categoryMap, rootCategories, nil
 }
 
 func (r *categoryRepository) UpdateCategory(ctx context.Context, req *request.UpdateCategoryRequest) (*types.Category, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
 	query := `UPDATE categories SET `
 
 	updates := []string{}
@@ -198,6 +266,33 @@ func (r *categoryRepository) UpdateCategory(ctx context.Context, req *request.Up
 		updates = append(updates, fmt.Sprintf("name = $%d", argCount))
 		args = append(args, *req.Name)
 		argCount++
+
+		var currentName, currentSlug string
+		err = tx.QueryRowContext(ctx,
+			"SELECT name, slug FROM categories WHERE id = $1 AND deleted_at IS NULL FOR UPDATE",
+			req.ID,
+		).Scan(&currentName, &currentSlug)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("category not found")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get category: %v", err)
+		}
+
+		if *req.Name != currentName {
+			slug, err := utils.GenerateSlug(ctx, tx, "categories", types.SlugEntityCategory, *req.Name, req.ID)
+			if err != nil {
+				return nil, err
+			}
+			if slug != currentSlug {
+				if err := recordSlugChange(ctx, tx, req.ID, currentSlug, slug); err != nil {
+					return nil, err
+				}
+				updates = append(updates, fmt.Sprintf("slug = $%d", argCount))
+				args = append(args, slug)
+				argCount++
+			}
+		}
 	}
 
 	if req.Description != nil {
@@ -212,10 +307,22 @@ func (r *categoryRepository) UpdateCategory(ctx context.Context, req *request.Up
 		argCount++
 	}
 
+	// Re-parenting goes through moveSubtree so descendants' depths follow
+	// and cycles are rejected.
 	if req.ParentID != nil {
-		updates = append(updates, fmt.Sprintf("parent_id = $%d", argCount))
-		args = append(args, *req.ParentID)
-		argCount++
+		if _, err := moveSubtree(ctx, tx, req.ID, req.ParentID); err != nil {
+			return nil, err
+		}
+		if len(updates) == 0 {
+			category, err := getCategory(ctx, tx, req.ID)
+			if err != nil {
+				return nil, err
+			}
+			if err = tx.Commit(); err != nil {
+				return nil, fmt.Errorf("failed to commit transaction: %v", err)
+			}
+			return category, nil
+		}
 	}
 
 	if len(updates) == 0 {
@@ -223,19 +330,21 @@ func (r *categoryRepository) UpdateCategory(ctx context.Context, req *request.Up
 	}
 
 	query += strings.Join(updates, ", ")
-	query += fmt.Sprintf(" WHERE id = $%d RETURNING id, name, description, image, depth, parent_id, created_at", argCount)
+	query += fmt.Sprintf(" WHERE id = $%d AND deleted_at IS NULL RETURNING id, name, slug, description, image, depth, position, parent_id, created_at", argCount)
 	args = append(args, req.ID)
 
 	var category types.Category
 	var parentID, image sql.NullString
 	var createdAt sql.NullTime
 
-	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+	err = tx.QueryRowContext(ctx, query, args...).Scan(
 		&category.ID,
 		&category.Name,
+		&category.Slug,
 		&category.Description,
 		&image,
 		&category.Depth,
+		&category.Position,
 		&parentID,
 		&createdAt,
 	)
@@ -247,6 +356,10 @@ func (r *categoryRepository) UpdateCategory(ctx context.Context, req *request.Up
 		return nil, fmt.Errorf("failed to update category: %v", err)
 	}
 
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
 	if image.Valid {
 		category.Image = &image.String
 	}
@@ -260,81 +373,400 @@ func (r *categoryRepository) UpdateCategory(ctx context.Context, req *request.Up
 	return &category, nil
 }
 
+// DeleteCategory moves a category to the trash using req.Strategy to decide
+// what happens to its children and products. With DryRun the work is done
+// and counted but rolled back, which is how the delete preview is served.
 func (s *categoryRepository) DeleteCategory(ctx context.Context, req *request.DeleteCategoryRequest) (*response.DeleteCategoryResponse, error) {
-	// Start a transaction
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %v", err)
 	}
 	defer tx.Rollback()
 
-	// First check if category exists
-	var exists bool
-	err = tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1)", req.ID).Scan(&exists)
+	var parentID sql.NullString
+	err = tx.QueryRowContext(ctx,
+		"SELECT parent_id FROM categories WHERE id = $1 AND deleted_at IS NULL FOR UPDATE",
+		req.ID,
+	).Scan(&parentID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("category not found")
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to check category existence: %v", err)
 	}
-	if !exists {
-		return nil, fmt.Errorf("category not found")
+
+	childIDs, err := childCategoryIDs(ctx, tx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	var productCount int64
+	err = tx.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM products WHERE category_id = $1 AND deleted_at IS NULL",
+		req.ID,
+	).Scan(&productCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count products: %v", err)
 	}
 
-	var deletedCount int64
-	if req.DeleteChildren {
-		withDescendantsQuery := `
-            WITH RECURSIVE category_tree AS (
-                -- Base case: the category we want to delete
-                SELECT id FROM categories WHERE id = $1
-                UNION ALL
-                -- Recursive case: get all children
-                SELECT c.id 
-                FROM categories c
-                INNER JOIN category_tree ct ON c.parent_id = ct.id
-            )
-            DELETE FROM categories 
-            WHERE id IN (SELECT id FROM category_tree)
-            RETURNING id`
-
-		result, err := tx.ExecContext(ctx, withDescendantsQuery, req.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to delete category and its children: %v", err)
+	res := &response.DeleteCategoryResponse{
+		Strategy: req.Strategy,
+		DryRun:   req.DryRun,
+	}
+	now := time.Now()
+
+	switch req.Strategy {
+	case types.CategoryDeleteReject:
+		if len(childIDs) > 0 || productCount > 0 {
+			return nil, fmt.Errorf("invalid delete: category has %d children and %d products", len(childIDs), productCount)
 		}
-		deletedCount, err = result.RowsAffected()
+
+	case types.CategoryDeleteCascade:
+		res.DeletedProducts, err = trashSubtreeProducts(ctx, tx, req.ID, now)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get affected rows: %v", err)
+			return nil, err
 		}
-	} else {
-		var hasChildren bool
-		err = tx.QueryRowContext(ctx,
-			"SELECT EXISTS(SELECT 1 FROM categories WHERE parent_id = $1)",
-			req.ID,
-		).Scan(&hasChildren)
-		if err != nil {
-			return nil, fmt.Errorf("failed to check for children: %v", err)
+
+	case types.CategoryDeleteReassignParent, types.CategoryDeleteReassignTarget:
+		var dest *string
+		if req.Strategy == types.CategoryDeleteReassignParent {
+			if parentID.Valid {
+				dest = &parentID.String
+			} else if productCount > 0 {
+				return nil, fmt.Errorf("invalid delete: a root category has no parent to take its products")
+			}
+		} else {
+			if err := checkReassignTarget(ctx, tx, req.ID, req.TargetID); err != nil {
+				return nil, err
+			}
+			dest = &req.TargetID
+		}
+
+		for _, childID := range childIDs {
+			if _, err := moveSubtree(ctx, tx, childID, dest); err != nil {
+				return nil, err
+			}
 		}
-		if hasChildren {
-			return nil, fmt.Errorf("cannot delete category with children, set DeleteChildren to true to delete all")
+		res.ReassignedChildren = int64(len(childIDs))
+
+		if dest != nil {
+			result, err := tx.ExecContext(ctx,
+				"UPDATE products SET category_id = $1 WHERE category_id = $2 AND deleted_at IS NULL",
+				*dest, req.ID,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to reassign products: %v", err)
+			}
+			res.ReassignedProducts, err = result.RowsAffected()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get affected rows: %v", err)
+			}
 		}
 
-		// Delete single category
-		result, err := tx.ExecContext(ctx,
-			"DELETE FROM categories WHERE id = $1",
-			req.ID,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to delete category: %v", err)
+	default:
+		return nil, fmt.Errorf("invalid delete strategy: %s", req.Strategy)
+	}
+
+	// Whatever is still below the category at this point goes to the trash
+	// with it: the whole subtree for cascade, nothing else otherwise.
+	result, err := tx.ExecContext(ctx, categoryTreeCTE("c.id = $1")+`
+        UPDATE categories
+        SET deleted_at = $2
+        WHERE id IN (SELECT id FROM category_tree)`,
+		req.ID, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete category: %v", err)
+	}
+	res.DeletedCount, err = result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %v", err)
+	}
+
+	if req.DryRun {
+		return res, nil
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	res.Success = true
+	return res, nil
+}
+
+func childCategoryIDs(ctx context.Context, tx *sql.Tx, categoryID string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx,
+		"SELECT id FROM categories WHERE parent_id = $1 AND deleted_at IS NULL ORDER BY position",
+		categoryID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for children: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan child: %v", err)
 		}
-		deletedCount, err = result.RowsAffected()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get affected rows: %v", err)
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// checkReassignTarget makes sure the target exists and lies outside the
+// subtree being deleted.
+func checkReassignTarget(ctx context.Context, tx *sql.Tx, categoryID, targetID string) error {
+	var exists, inSubtree bool
+	err := tx.QueryRowContext(ctx, categoryTreeCTE("c.id = $1")+`
+        SELECT
+            EXISTS(SELECT 1 FROM categories WHERE id = $2 AND deleted_at IS NULL),
+            EXISTS(SELECT 1 FROM category_tree WHERE id = $2)`,
+		categoryID, targetID,
+	).Scan(&exists, &inSubtree)
+	if err != nil {
+		return fmt.Errorf("failed to check target category: %v", err)
+	}
+	if !exists {
+		return fmt.Errorf("target category not found")
+	}
+	if inSubtree {
+		return fmt.Errorf("invalid delete: target category is inside the deleted subtree")
+	}
+	return nil
+}
+
+// trashSubtreeProducts moves the products of a category subtree to the trash
+// together with their variants and images, the same way DeleteProduct does.
+func trashSubtreeProducts(ctx context.Context, tx *sql.Tx, categoryID string, now time.Time) (int64, error) {
+	subtreeProducts := categoryTreeCTE("c.id = $1") + `,
+        subtree_products AS (
+            SELECT p.id FROM products p
+            WHERE p.category_id IN (SELECT id FROM category_tree)
+            AND p.deleted_at IS NULL
+        )`
+
+	_, err := tx.ExecContext(ctx, subtreeProducts+`
+        UPDATE product_images SET deleted_at = $2
+        WHERE deleted_at IS NULL
+        AND variant_id IN (
+            SELECT id FROM product_variants
+            WHERE product_id IN (SELECT id FROM subtree_products) AND deleted_at IS NULL
+        )`, categoryID, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete product images: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, subtreeProducts+`
+        UPDATE product_variants SET deleted_at = $2
+        WHERE deleted_at IS NULL
+        AND product_id IN (SELECT id FROM subtree_products)`, categoryID, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete product variants: %v", err)
+	}
+
+	result, err := tx.ExecContext(ctx, subtreeProducts+`
+        UPDATE products SET deleted_at = $2
+        WHERE id IN (SELECT id FROM subtree_products)`, categoryID, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete products: %v", err)
+	}
+	return result.RowsAffected()
+}
+
+func (r *categoryRepository) ListDeletedCategories(ctx context.Context) (*response.ListTrashResponse, error) {
+	query := `
+        SELECT id, name, parent_id, deleted_at
+        FROM categories
+        WHERE deleted_at IS NOT NULL
+        ORDER BY deleted_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deleted categories: %v", err)
+	}
+	defer rows.Close()
+
+	items := make([]*types.TrashItem, 0)
+	for rows.Next() {
+		item := &types.TrashItem{Type: types.TrashTypeCategory}
+		var parentID sql.NullString
+		if err := rows.Scan(&item.ID, &item.Name, &parentID, &item.DeletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %v", err)
 		}
+		if parentID.Valid {
+			item.ParentID = &parentID.String
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating categories: %v", err)
+	}
+
+	return &response.ListTrashResponse{
+		Items: items,
+	}, nil
+}
+
+// RestoreCategory brings a category back from the trash along with any
+// descendants that were deleted in the same operation.
+func (r *categoryRepository) RestoreCategory(ctx context.Context, req *request.RestoreTrashRequest) (*response.RestoreTrashResponse, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var deletedAt time.Time
+	var parentDeleted bool
+	err = tx.QueryRowContext(ctx, `
+        SELECT c.deleted_at, COALESCE(p.deleted_at IS NOT NULL, false)
+        FROM categories c
+        LEFT JOIN categories p ON p.id = c.parent_id
+        WHERE c.id = $1 AND c.deleted_at IS NOT NULL`,
+		req.ID,
+	).Scan(&deletedAt, &parentDeleted)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("category not found in trash")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check category: %v", err)
+	}
+	if parentDeleted {
+		return nil, fmt.Errorf("cannot restore category of a deleted parent, restore the parent first")
+	}
+
+	restoreQuery := `
+        WITH RECURSIVE category_tree AS (
+            SELECT id FROM categories WHERE id = $1
+            UNION ALL
+            SELECT c.id
+            FROM categories c
+            INNER JOIN category_tree ct ON c.parent_id = ct.id
+            WHERE c.deleted_at = $2
+        )
+        UPDATE categories
+        SET deleted_at = NULL
+        WHERE id IN (SELECT id FROM category_tree)`
+
+	result, err := tx.ExecContext(ctx, restoreQuery, req.ID, deletedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore category: %v", err)
+	}
+	restoredCount, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %v", err)
 	}
 
 	if err = tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %v", err)
 	}
 
-	return &response.DeleteCategoryResponse{
-		Success:      true,
-		DeletedCount: deletedCount,
+	return &response.RestoreTrashResponse{
+		Success:       true,
+		RestoredCount: restoredCount,
 	}, nil
 }
+
+// PurgeDeletedCategories permanently removes categories that have been in
+// the trash since before the cutoff, deepest first. Each category is purged
+// in its own transaction: its products move up to its parent and its
+// children lose their parent, so a category that still holds products and
+// has no parent to give them to stays in the trash without blocking the rest.
+func (r *categoryRepository) PurgeDeletedCategories(ctx context.Context, before time.Time) (int64, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT id FROM categories WHERE deleted_at IS NOT NULL AND deleted_at < $1 ORDER BY depth DESC",
+		before,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list purgeable categories: %v", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan category: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating categories: %v", err)
+	}
+
+	var purged int64
+	var errs []error
+	for _, id := range ids {
+		ok, err := r.purgeCategory(ctx, id, before)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to purge category %s: %v", id, err))
+			continue
+		}
+		if ok {
+			purged++
+		}
+	}
+	return purged, errors.Join(errs...)
+}
+
+// purgeCategory deletes one trashed category. It reports false when the
+// category was restored or already purged since it was listed.
+func (r *categoryRepository) purgeCategory(ctx context.Context, categoryID string, before time.Time) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var parentID sql.NullString
+	err = tx.QueryRowContext(ctx,
+		"SELECT parent_id FROM categories WHERE id = $1 AND deleted_at IS NOT NULL AND deleted_at < $2 FOR UPDATE",
+		categoryID, before,
+	).Scan(&parentID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock category: %v", err)
+	}
+
+	if parentID.Valid {
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE products SET category_id = $1 WHERE category_id = $2",
+			parentID.String, categoryID,
+		); err != nil {
+			return false, fmt.Errorf("failed to reassign products: %v", err)
+		}
+	} else {
+		var productCount int64
+		err = tx.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM products WHERE category_id = $1",
+			categoryID,
+		).Scan(&productCount)
+		if err != nil {
+			return false, fmt.Errorf("failed to count products: %v", err)
+		}
+		if productCount > 0 {
+			return false, fmt.Errorf("root category still has %d products", productCount)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE categories SET parent_id = NULL WHERE parent_id = $1",
+		categoryID,
+	); err != nil {
+		return false, fmt.Errorf("failed to detach children: %v", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM categories WHERE id = $1", categoryID); err != nil {
+		return false, fmt.Errorf("failed to delete category: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,148 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+)
+
+// GetBreadcrumb walks up from a category to its root.
+func (r *categoryRepository) GetBreadcrumb(ctx context.Context, categoryID string) (*response.CategoryBreadcrumbResponse, error) {
+	query := `
+        WITH RECURSIVE ancestors AS (
+            SELECT id, name, slug, description, image, parent_id, depth, position, created_at, 0 AS level
+            FROM categories
+            WHERE id = $1 AND deleted_at IS NULL
+            UNION ALL
+            SELECT c.id, c.name, c.slug, c.description, c.image, c.parent_id, c.depth, c.position, c.created_at, a.level + 1
+            FROM categories c
+            INNER JOIN ancestors a ON c.id = a.parent_id
+            WHERE c.deleted_at IS NULL
+        )
+        SELECT id, name, slug, description, image, parent_id, depth, position, created_at
+        FROM ancestors
+        ORDER BY level DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query breadcrumb: %v", err)
+	}
+	defer rows.Close()
+
+	items := make([]*types.Category, 0)
+	for rows.Next() {
+		var cat types.Category
+		var parentID, image sql.NullString
+		var createdAt sql.NullTime
+		if err := rows.Scan(
+			&cat.ID,
+			&cat.Name,
+			&cat.Slug,
+			&cat.Description,
+			&image,
+			&parentID,
+			&cat.Depth,
+			&cat.Position,
+			&createdAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %v", err)
+		}
+		if parentID.Valid {
+			cat.ParentID = &parentID.String
+		}
+		if image.Valid {
+			cat.Image = &image.String
+		}
+		if createdAt.Valid {
+			cat.CreatedAt = createdAt.Time
+		}
+		items = append(items, &cat)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating categories: %v", err)
+	}
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("category not found")
+	}
+
+	return &response.CategoryBreadcrumbResponse{Items: items}, nil
+}
+
+// ListCategoryProducts returns the published products of a category and of
+// all its descendants, newest first.
+func (r *categoryRepository) ListCategoryProducts(ctx context.Context, req *request.ListCategoryProductsRequest) (*response.ListProductsResponse, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1 AND deleted_at IS NULL)",
+		req.ID,
+	).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check category existence: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("category not found")
+	}
+
+	query := categoryTreeCTE("c.id = $1") + `
+        SELECT
+            p.id, p.name, p.slug, p.sub_title, p.description,
+            p.price, p.sku, p.category_id, p.status,
+            p.rating_average, p.rating_count,
+            p.created_at, p.updated_at
+        FROM products p
+        WHERE p.category_id IN (SELECT id FROM category_tree)
+        AND p.deleted_at IS NULL
+        AND p.status = 'published'
+        AND (p.unpublish_at IS NULL OR p.unpublish_at > NOW())
+        ORDER BY p.created_at DESC
+        LIMIT $2
+        OFFSET ($2 * COALESCE(NULLIF($3, ''), '0')::integer)`
+
+	rows, err := r.db.QueryContext(ctx, query, req.ID, req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query products: %v", err)
+	}
+	defer rows.Close()
+
+	products := make([]*types.Product, 0)
+	for rows.Next() {
+		var product types.Product
+		var subTitle sql.NullString
+		var createdAt, updatedAt time.Time
+		if err := rows.Scan(
+			&product.ID, &product.Name, &product.Slug, &subTitle, &product.Description,
+			&product.Price, &product.SKU, &product.CategoryID, &product.Status,
+			&product.RatingAverage, &product.RatingCount,
+			&createdAt, &updatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan product: %v", err)
+		}
+		if subTitle.Valid {
+			product.SubTitle = subTitle.String
+		}
+		product.CreatedAt = createdAt.Unix()
+		product.UpdatedAt = updatedAt.Unix()
+		products = append(products, &product)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating products: %v", err)
+	}
+
+	nextPageToken := ""
+	if len(products) == int(req.PageSize) {
+		currentPage, _ := strconv.Atoi(req.PageToken)
+		nextPageToken = strconv.Itoa(currentPage + 1)
+	}
+
+	return &response.ListProductsResponse{
+		Products:      products,
+		NextPageToken: nextPageToken,
+	}, nil
+}
@@ -0,0 +1,169 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+)
+
+// moveSubtree re-parents a category inside tx, placing it after its new
+// siblings, and shifts the depth of the whole subtree by the same amount.
+// It rejects parents that are the category itself or one of its
+// descendants, and returns how many rows had their depth recomputed. Moving
+// a category under the parent it already has changes nothing, so edits that
+// resend the parent keep the category's position.
+func moveSubtree(ctx context.Context, tx *sql.Tx, categoryID string, parentID *string) (int64, error) {
+	var oldDepth int32
+	var oldParentID sql.NullString
+	err := tx.QueryRowContext(ctx,
+		"SELECT depth, parent_id FROM categories WHERE id = $1 AND deleted_at IS NULL FOR UPDATE",
+		categoryID,
+	).Scan(&oldDepth, &oldParentID)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("category not found")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get category: %v", err)
+	}
+	if parentID == nil && !oldParentID.Valid || parentID != nil && oldParentID.Valid && *parentID == oldParentID.String {
+		return 0, nil
+	}
+
+	var newDepth int32
+	if parentID != nil {
+		var parentDepth int32
+		err = tx.QueryRowContext(ctx,
+			"SELECT depth FROM categories WHERE id = $1 AND deleted_at IS NULL FOR UPDATE",
+			*parentID,
+		).Scan(&parentDepth)
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("parent category not found")
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to get parent category: %v", err)
+		}
+
+		var cycle bool
+		err = tx.QueryRowContext(ctx, `
+            WITH RECURSIVE ancestors AS (
+                SELECT id, parent_id FROM categories WHERE id = $1
+                UNION ALL
+                SELECT c.id, c.parent_id
+                FROM categories c
+                INNER JOIN ancestors a ON c.id = a.parent_id
+            )
+            SELECT EXISTS(SELECT 1 FROM ancestors WHERE id = $2)`,
+			*parentID, categoryID,
+		).Scan(&cycle)
+		if err != nil {
+			return 0, fmt.Errorf("failed to check ancestors: %v", err)
+		}
+		if cycle {
+			return 0, fmt.Errorf("invalid move: category cannot be moved under itself or its descendants")
+		}
+		newDepth = parentDepth + 1
+	}
+
+	position, err := nextPosition(ctx, tx, parentID, categoryID)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE categories SET parent_id = $1, position = $3 WHERE id = $2",
+		parentID, categoryID, position,
+	); err != nil {
+		return 0, fmt.Errorf("failed to update parent: %v", err)
+	}
+
+	// Deleted descendants are shifted too so a later restore lands them at
+	// the right depth.
+	result, err := tx.ExecContext(ctx, `
+        WITH RECURSIVE subtree AS (
+            SELECT id FROM categories WHERE id = $1
+            UNION ALL
+            SELECT c.id
+            FROM categories c
+            INNER JOIN subtree s ON c.parent_id = s.id
+        )
+        UPDATE categories
+        SET depth = depth + $2
+        WHERE id IN (SELECT id FROM subtree)`,
+		categoryID, newDepth-oldDepth,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to recompute depth: %v", err)
+	}
+	return result.RowsAffected()
+}
+
+func (r *categoryRepository) MoveCategory(ctx context.Context, req *request.MoveCategoryRequest) (*response.MoveCategoryResponse, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	updated, err := moveSubtree(ctx, tx, req.ID, req.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := getCategory(ctx, tx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return &response.MoveCategoryResponse{
+		Category:     category,
+		UpdatedCount: updated,
+	}, nil
+}
+
+func getCategory(ctx context.Context, tx *sql.Tx, categoryID string) (*types.Category, error) {
+	var category types.Category
+	var parentID, image sql.NullString
+	var createdAt sql.NullTime
+
+	err := tx.QueryRowContext(ctx, `
+        SELECT id, name, slug, description, image, depth, position, parent_id, created_at
+        FROM categories
+        WHERE id = $1 AND deleted_at IS NULL`,
+		categoryID,
+	).Scan(
+		&category.ID,
+		&category.Name,
+		&category.Slug,
+		&category.Description,
+		&image,
+		&category.Depth,
+		&category.Position,
+		&parentID,
+		&createdAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("category not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category: %v", err)
+	}
+
+	if image.Valid {
+		category.Image = &image.String
+	}
+	if parentID.Valid {
+		category.ParentID = &parentID.String
+	}
+	if createdAt.Valid {
+		category.CreatedAt = createdAt.Time
+	}
+	return &category, nil
+}
@@ -0,0 +1,116 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	request "github.com/wafi04/backend/pkg/types/req"
+)
+
+// nextPosition returns the position just after the last child of parentID,
+// ignoring excludeID.
+func nextPosition(ctx context.Context, tx *sql.Tx, parentID *string, excludeID string) (int32, error) {
+	var next int32
+	err := tx.QueryRowContext(ctx, `
+        SELECT COALESCE(MAX(position) + 1, 0)
+        FROM categories
+        WHERE parent_id IS NOT DISTINCT FROM $1
+        AND deleted_at IS NULL
+        AND id <> $2`,
+		parentID, excludeID,
+	).Scan(&next)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get sibling position: %v", err)
+	}
+	return next, nil
+}
+
+// insertPosition makes room for a new child of parentID at position by
+// shifting the siblings at or after it. A nil or out of range position
+// appends the child last.
+func insertPosition(ctx context.Context, tx *sql.Tx, parentID *string, position *int32) (int32, error) {
+	next, err := nextPosition(ctx, tx, parentID, "")
+	if err != nil {
+		return 0, err
+	}
+	if position == nil || *position >= next {
+		return next, nil
+	}
+	if *position < 0 {
+		return 0, fmt.Errorf("invalid position: must not be negative")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE categories
+        SET position = position + 1
+        WHERE parent_id IS NOT DISTINCT FROM $1
+        AND deleted_at IS NULL
+        AND position >= $2`,
+		parentID, *position,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to shift siblings: %v", err)
+	}
+	return *position, nil
+}
+
+// ReorderCategories rewrites the positions of a parent's children to the
+// order given in req.IDs.
+func (r *categoryRepository) ReorderCategories(ctx context.Context, req *request.ReorderCategoriesRequest) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+        SELECT id FROM categories
+        WHERE parent_id IS NOT DISTINCT FROM $1
+        AND deleted_at IS NULL
+        FOR UPDATE`,
+		req.ParentID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get children: %v", err)
+	}
+	children := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan child: %v", err)
+		}
+		children[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating children: %v", err)
+	}
+
+	if len(children) != len(req.IDs) {
+		return fmt.Errorf("invalid order: expected %d children, got %d", len(children), len(req.IDs))
+	}
+	for _, id := range req.IDs {
+		if !children[id] {
+			return fmt.Errorf("invalid order: %s is not a child of this parent", id)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE categories c
+        SET position = o.ordinality - 1
+        FROM UNNEST($1::VARCHAR[]) WITH ORDINALITY AS o(id, ordinality)
+        WHERE c.id = o.id`,
+		pq.Array(req.IDs),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reorder categories: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+)
+
+func recordSlugChange(ctx context.Context, tx *sql.Tx, categoryID, oldSlug, newSlug string) error {
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO slug_redirects (entity_type, slug, entity_id)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (entity_type, slug) DO UPDATE
+        SET entity_id = EXCLUDED.entity_id, created_at = CURRENT_TIMESTAMP`,
+		types.SlugEntityCategory, oldSlug, categoryID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record slug redirect: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"DELETE FROM slug_redirects WHERE entity_type = $1 AND slug = $2",
+		types.SlugEntityCategory, newSlug,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to clear slug redirect: %v", err)
+	}
+	return nil
+}
+
+func (r *categoryRepository) GetCategoryBySlug(ctx context.Context, req *request.GetCategoryBySlugRequest) (*response.CategoryBySlugResponse, error) {
+	query := `
+        SELECT id, name, slug, description, image, depth, position, parent_id, created_at
+        FROM categories
+        WHERE deleted_at IS NULL
+        AND id = COALESCE(
+            (SELECT id FROM categories WHERE slug = $1 AND deleted_at IS NULL),
+            (SELECT entity_id FROM slug_redirects WHERE entity_type = $2 AND slug = $1)
+        )`
+
+	var category types.Category
+	var parentID, image sql.NullString
+	var createdAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, query, req.Slug, types.SlugEntityCategory).Scan(
+		&category.ID,
+		&category.Name,
+		&category.Slug,
+		&category.Description,
+		&image,
+		&category.Depth,
+		&category.Position,
+		&parentID,
+		&createdAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("category not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category: %v", err)
+	}
+
+	if image.Valid {
+		category.Image = &image.String
+	}
+	if parentID.Valid {
+		category.ParentID = &parentID.String
+	}
+	if createdAt.Valid {
+		category.CreatedAt = createdAt.Time
+	}
+
+	return &response.CategoryBySlugResponse{
+		Category:      &category,
+		CanonicalSlug: category.Slug,
+		Redirected:    category.Slug != req.Slug,
+	}, nil
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/wafi04/backend/pkg/types"
@@ -40,7 +42,7 @@ func (s *CategoryService) CreateCategory(ctx context.Context, req *request.Creat
 		ParentID:    req.ParentID,
 	}
 
-	return s.categoryRepo.Create(ctx, category, depth)
+	return s.categoryRepo.Create(ctx, category, depth, req.Position)
 }
 
 func (s *CategoryService) GetCategories(ctx context.Context, req *request.ListCategoriesRequest) (*response.ListCategoriesResponse, error) {
@@ -55,10 +57,74 @@ func (s *CategoryService) GetCategories(ctx context.Context, req *request.ListCa
 	}, nil
 }
 
+func (s *CategoryService) GetCategoryBySlug(ctx context.Context, req *request.GetCategoryBySlugRequest) (*response.CategoryBySlugResponse, error) {
+	return s.categoryRepo.GetCategoryBySlug(ctx, req)
+}
+
 func (s *CategoryService) UppdateCategory(ctx context.Context, req *request.UpdateCategoryRequest) (*types.Category, error) {
 	return s.categoryRepo.UpdateCategory(ctx, req)
 }
 
+func (s *CategoryService) GetBreadcrumb(ctx context.Context, categoryID string) (*response.CategoryBreadcrumbResponse, error) {
+	return s.categoryRepo.GetBreadcrumb(ctx, categoryID)
+}
+
+func (s *CategoryService) ListCategoryProducts(ctx context.Context, req *request.ListCategoryProductsRequest) (*response.ListProductsResponse, error) {
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+	if req.PageToken == "" {
+		req.PageToken = "0"
+	}
+	return s.categoryRepo.ListCategoryProducts(ctx, req)
+}
+
+// MoveCategory re-parents a category, recomputing the depth of its whole
+// subtree.
+func (s *CategoryService) MoveCategory(ctx context.Context, req *request.MoveCategoryRequest) (*response.MoveCategoryResponse, error) {
+	if req.ParentID != nil && *req.ParentID == "" {
+		req.ParentID = nil
+	}
+	return s.categoryRepo.MoveCategory(ctx, req)
+}
+
+// ReorderCategories sets the menu order of a parent's children.
+func (s *CategoryService) ReorderCategories(ctx context.Context, req *request.ReorderCategoriesRequest) error {
+	if req.ParentID != nil && *req.ParentID == "" {
+		req.ParentID = nil
+	}
+	seen := make(map[string]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		if seen[id] {
+			return fmt.Errorf("invalid order: %s is listed twice", id)
+		}
+		seen[id] = true
+	}
+	return s.categoryRepo.ReorderCategories(ctx, req)
+}
+
 func (s *CategoryService) DeleteCategory(ctx context.Context, req *request.DeleteCategoryRequest) (*response.DeleteCategoryResponse, error) {
+	if req.Strategy == "" {
+		req.Strategy = types.CategoryDeleteReject
+	}
+	if req.Strategy == types.CategoryDeleteReassignTarget && req.TargetID == "" {
+		return nil, fmt.Errorf("invalid delete: target_id is required for %s", req.Strategy)
+	}
+	if req.TargetID == req.ID {
+		return nil, fmt.Errorf("invalid delete: a category cannot be reassigned to itself")
+	}
 	return s.categoryRepo.DeleteCategory(ctx, req)
 }
+
+func (s *CategoryService) ListTrash(ctx context.Context) (*response.ListTrashResponse, error) {
+	return s.categoryRepo.ListDeletedCategories(ctx)
+}
+
+func (s *CategoryService) RestoreCategory(ctx context.Context, req *request.RestoreTrashRequest) (*response.RestoreTrashResponse, error) {
+	return s.categoryRepo.RestoreCategory(ctx, req)
+}
+
+func (s *CategoryService) PurgeTrash(ctx context.Context, retention time.Duration) error {
+	_, err := s.categoryRepo.PurgeDeletedCategories(ctx, time.Now().Add(-retention))
+	return err
+}
@@ -0,0 +1,220 @@
+package collectionhandler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/backend/pkg/logger"
+	httpresponse "github.com/wafi04/backend/pkg/response"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	"github.com/wafi04/backend/pkg/utils"
+	collectionservice "github.com/wafi04/backend/services/collection/service"
+	"github.com/wafi04/backend/services/files"
+)
+
+type CollectionHandler struct {
+	collectionService *collectionservice.CollectionService
+	log               logger.Logger
+	filesclient       files.FileStorage
+}
+
+func NewCollectionHandler(service *collectionservice.CollectionService, files files.FileStorage) *CollectionHandler {
+	return &CollectionHandler{
+		collectionService: service,
+		filesclient:       files,
+	}
+}
+
+// uploadImage uploads the optional "file" form field and returns its URL,
+// or nil when no file was sent.
+func (h *CollectionHandler) uploadImage(c *gin.Context) (*string, error) {
+	file, _, err := c.Request.FormFile("file")
+	if err != nil {
+		return nil, nil
+	}
+	defer file.Close()
+
+	uploadResponse, err := h.filesclient.UploadFile(c, &request.FileUploadRequest{
+		FileData: file,
+		Folder:   "collections",
+		PublicID: utils.GenerateRandomId("COL"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &uploadResponse.URL, nil
+}
+
+// parseRules reads the rule form fields: comma separated tags and
+// category_ids, and min_price / max_price. It returns nil when none is set.
+func parseRules(c *gin.Context) (*types.CollectionRules, error) {
+	rules := &types.CollectionRules{
+		Tags:        splitList(c.PostForm("tags")),
+		CategoryIDs: splitList(c.PostForm("category_ids")),
+	}
+	for field, dest := range map[string]**float64{"min_price": &rules.MinPrice, "max_price": &rules.MaxPrice} {
+		raw := c.PostForm(field)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, err
+		}
+		*dest = &value
+	}
+
+	if len(rules.Tags) == 0 && len(rules.CategoryIDs) == 0 && rules.MinPrice == nil && rules.MaxPrice == nil {
+		return nil, nil
+	}
+	return rules, nil
+}
+
+func splitList(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func collectionErrorStatus(err error) int {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		return http.StatusNotFound
+	case strings.Contains(err.Error(), "invalid"):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *CollectionHandler) HandleCreateCollection(c *gin.Context) {
+	if err := c.Request.ParseMultipartForm(10 << 20); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to parse form data", err.Error())
+		return
+	}
+
+	name := c.PostForm("name")
+	if name == "" {
+		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Name is Required")
+		return
+	}
+
+	rules, err := parseRules(c)
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid rules", err.Error())
+		return
+	}
+
+	image, err := h.uploadImage(c)
+	if err != nil {
+		httpresponse.SendUploadError(c, err, http.StatusInternalServerError, "Failed to upload image")
+		return
+	}
+
+	resp, err := h.collectionService.CreateCollection(c, &request.CreateCollectionRequest{
+		Name:        name,
+		Description: c.PostForm("description"),
+		Image:       image,
+		Type:        c.PostForm("type"),
+		Rules:       rules,
+	})
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, collectionErrorStatus(err), "Failed to create collection", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusCreated, "Collection Created", resp)
+}
+
+func (h *CollectionHandler) HandleUpdateCollection(c *gin.Context) {
+	if err := c.Request.ParseMultipartForm(10 << 20); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to parse form data", err.Error())
+		return
+	}
+
+	req := &request.UpdateCollectionRequest{ID: c.Param("id")}
+	if name, ok := c.GetPostForm("name"); ok {
+		req.Name = &name
+	}
+	if description, ok := c.GetPostForm("description"); ok {
+		req.Description = &description
+	}
+
+	rules, err := parseRules(c)
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid rules", err.Error())
+		return
+	}
+	req.Rules = rules
+
+	image, err := h.uploadImage(c)
+	if err != nil {
+		httpresponse.SendUploadError(c, err, http.StatusInternalServerError, "Failed to upload image")
+		return
+	}
+	req.Image = image
+
+	resp, err := h.collectionService.UpdateCollection(c, req)
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, collectionErrorStatus(err), "Failed to update collection", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Collection Updated", resp)
+}
+
+func (h *CollectionHandler) HandleListCollections(c *gin.Context) {
+	resp, err := h.collectionService.ListCollections(c)
+	if err != nil {
+		h.log.Log(logger.ErrorLevel, "Error listing collections: %v", err)
+		httpresponse.SendErrorResponse(c, http.StatusInternalServerError, "Error retrieving collections")
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Collections Retrieved Successfully", resp)
+}
+
+func (h *CollectionHandler) HandleGetCollectionBySlug(c *gin.Context) {
+	resp, err := h.collectionService.GetCollectionBySlug(c, c.Param("slug"))
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, collectionErrorStatus(err), "Failed to get collection", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Collection Retrieved Successfully", resp)
+}
+
+func (h *CollectionHandler) HandleDeleteCollection(c *gin.Context) {
+	if err := h.collectionService.DeleteCollection(c, c.Param("id")); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, collectionErrorStatus(err), "Failed to delete collection", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Collection Deleted", nil)
+}
+
+// HandleSetCollectionProducts replaces the products of a manual collection
+// with the given ids, in display order.
+func (h *CollectionHandler) HandleSetCollectionProducts(c *gin.Context) {
+	var req request.SetCollectionProductsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+	req.CollectionID = c.Param("id")
+
+	resp, err := h.collectionService.SetCollectionProducts(c, &req)
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, collectionErrorStatus(err), "Failed to set collection products", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Collection Products Updated", resp)
+}
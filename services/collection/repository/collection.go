@@ -0,0 +1,352 @@
+package collection
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+	"github.com/wafi04/backend/pkg/utils"
+)
+
+type CollectionRepository interface {
+	Create(ctx context.Context, collection *types.Collection) (*types.Collection, error)
+	GetBySlug(ctx context.Context, slug string) (*types.Collection, error)
+	List(ctx context.Context) (*response.ListCollectionsResponse, error)
+	Update(ctx context.Context, req *request.UpdateCollectionRequest) (*types.Collection, error)
+	Delete(ctx context.Context, id string) error
+	SetProducts(ctx context.Context, req *request.SetCollectionProductsRequest) (*types.Collection, error)
+}
+
+type collectionRepository struct {
+	db *sqlx.DB
+}
+
+func NewCollectionRepository(db *sqlx.DB) CollectionRepository {
+	return &collectionRepository{db: db}
+}
+
+const collectionColumns = `id, name, slug, description, image, collection_type,
+    rule_tags, rule_category_ids, rule_min_price, rule_max_price,
+    created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCollection(row rowScanner) (*types.Collection, error) {
+	var c types.Collection
+	var image sql.NullString
+	var tags, categoryIDs pq.StringArray
+	var minPrice, maxPrice sql.NullFloat64
+	if err := row.Scan(
+		&c.ID,
+		&c.Name,
+		&c.Slug,
+		&c.Description,
+		&image,
+		&c.Type,
+		&tags,
+		&categoryIDs,
+		&minPrice,
+		&maxPrice,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if image.Valid {
+		c.Image = &image.String
+	}
+	if c.Type == types.CollectionTypeRule {
+		c.Rules = &types.CollectionRules{
+			Tags:        tags,
+			CategoryIDs: categoryIDs,
+		}
+		if minPrice.Valid {
+			c.Rules.MinPrice = &minPrice.Float64
+		}
+		if maxPrice.Valid {
+			c.Rules.MaxPrice = &maxPrice.Float64
+		}
+	}
+	return &c, nil
+}
+
+// ruleArgs flattens the rules into the rule_* column values.
+func ruleArgs(rules *types.CollectionRules) (pq.StringArray, pq.StringArray, *float64, *float64) {
+	if rules == nil {
+		return pq.StringArray{}, pq.StringArray{}, nil, nil
+	}
+	tags := pq.StringArray(rules.Tags)
+	if tags == nil {
+		tags = pq.StringArray{}
+	}
+	categoryIDs := pq.StringArray(rules.CategoryIDs)
+	if categoryIDs == nil {
+		categoryIDs = pq.StringArray{}
+	}
+	return tags, categoryIDs, rules.MinPrice, rules.MaxPrice
+}
+
+func (r *collectionRepository) Create(ctx context.Context, collection *types.Collection) (*types.Collection, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	slug, err := utils.GenerateSlug(ctx, tx, "collections", "", collection.Name, collection.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, categoryIDs, minPrice, maxPrice := ruleArgs(collection.Rules)
+	created, err := scanCollection(tx.QueryRowContext(ctx, `
+        INSERT INTO collections (
+            id, name, slug, description, image, collection_type,
+            rule_tags, rule_category_ids, rule_min_price, rule_max_price,
+            created_at, updated_at
+        )
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+        RETURNING `+collectionColumns,
+		collection.ID, collection.Name, slug, collection.Description, collection.Image, collection.Type,
+		tags, categoryIDs, minPrice, maxPrice,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert collection: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return created, nil
+}
+
+func (r *collectionRepository) GetBySlug(ctx context.Context, slug string) (*types.Collection, error) {
+	collection, err := scanCollection(r.db.QueryRowContext(ctx, `
+        SELECT `+collectionColumns+` FROM collections WHERE slug = $1`, slug))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("collection not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %v", err)
+	}
+
+	if collection.Type == types.CollectionTypeManual {
+		collection.ProductIDs, err = r.getProductIDs(ctx, r.db, collection.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return collection, nil
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func (r *collectionRepository) getProductIDs(ctx context.Context, q queryer, collectionID string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, `
+        SELECT cp.product_id
+        FROM collection_products cp
+        JOIN products p ON p.id = cp.product_id
+        WHERE cp.collection_id = $1 AND p.deleted_at IS NULL
+        ORDER BY cp.position`, collectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection products: %v", err)
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan collection product: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating collection products: %v", err)
+	}
+	return ids, nil
+}
+
+func (r *collectionRepository) List(ctx context.Context) (*response.ListCollectionsResponse, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT `+collectionColumns+` FROM collections ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query collections: %v", err)
+	}
+	defer rows.Close()
+
+	collections := make([]*types.Collection, 0)
+	for rows.Next() {
+		collection, err := scanCollection(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan collection: %v", err)
+		}
+		collections = append(collections, collection)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating collections: %v", err)
+	}
+
+	return &response.ListCollectionsResponse{Collections: collections}, nil
+}
+
+func (r *collectionRepository) Update(ctx context.Context, req *request.UpdateCollectionRequest) (*types.Collection, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var currentName, collectionType string
+	err = tx.QueryRowContext(ctx,
+		"SELECT name, collection_type FROM collections WHERE id = $1 FOR UPDATE", req.ID,
+	).Scan(&currentName, &collectionType)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("collection not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %v", err)
+	}
+
+	updates := []string{"updated_at = CURRENT_TIMESTAMP"}
+	args := []interface{}{}
+	argCount := 1
+
+	if req.Name != nil {
+		updates = append(updates, fmt.Sprintf("name = $%d", argCount))
+		args = append(args, *req.Name)
+		argCount++
+
+		if *req.Name != currentName {
+			slug, err := utils.GenerateSlug(ctx, tx, "collections", "", *req.Name, req.ID)
+			if err != nil {
+				return nil, err
+			}
+			updates = append(updates, fmt.Sprintf("slug = $%d", argCount))
+			args = append(args, slug)
+			argCount++
+		}
+	}
+
+	if req.Description != nil {
+		updates = append(updates, fmt.Sprintf("description = $%d", argCount))
+		args = append(args, *req.Description)
+		argCount++
+	}
+
+	if req.Image != nil {
+		updates = append(updates, fmt.Sprintf("image = $%d", argCount))
+		args = append(args, *req.Image)
+		argCount++
+	}
+
+	if req.Rules != nil {
+		if collectionType != types.CollectionTypeRule {
+			return nil, fmt.Errorf("invalid request: rules only apply to rule collections")
+		}
+		tags, categoryIDs, minPrice, maxPrice := ruleArgs(req.Rules)
+		updates = append(updates,
+			fmt.Sprintf("rule_tags = $%d", argCount),
+			fmt.Sprintf("rule_category_ids = $%d", argCount+1),
+			fmt.Sprintf("rule_min_price = $%d", argCount+2),
+			fmt.Sprintf("rule_max_price = $%d", argCount+3),
+		)
+		args = append(args, tags, categoryIDs, minPrice, maxPrice)
+		argCount += 4
+	}
+
+	query := "UPDATE collections SET " + strings.Join(updates, ", ") +
+		fmt.Sprintf(" WHERE id = $%d RETURNING ", argCount) + collectionColumns
+	args = append(args, req.ID)
+
+	collection, err := scanCollection(tx.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update collection: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return collection, nil
+}
+
+func (r *collectionRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM collections WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete collection: %v", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("collection not found")
+	}
+	return nil
+}
+
+// SetProducts replaces the members of a manual collection, storing each
+// product's index in ProductIDs as its position.
+func (r *collectionRepository) SetProducts(ctx context.Context, req *request.SetCollectionProductsRequest) (*types.Collection, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	collection, err := scanCollection(tx.QueryRowContext(ctx, `
+        SELECT `+collectionColumns+` FROM collections WHERE id = $1 FOR UPDATE`, req.CollectionID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("collection not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %v", err)
+	}
+	if collection.Type != types.CollectionTypeManual {
+		return nil, fmt.Errorf("invalid request: products can only be set on manual collections")
+	}
+
+	var found int
+	err = tx.QueryRowContext(ctx, `
+        SELECT COUNT(*) FROM products WHERE id = ANY($1) AND deleted_at IS NULL
+    `, pq.Array(req.ProductIDs)).Scan(&found)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check products: %v", err)
+	}
+	if found != len(req.ProductIDs) {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM collection_products WHERE collection_id = $1", req.CollectionID); err != nil {
+		return nil, fmt.Errorf("failed to clear collection products: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO collection_products (collection_id, product_id, position)
+        SELECT $1, product_id, ordinality - 1
+        FROM UNNEST($2::VARCHAR[]) WITH ORDINALITY AS t(product_id, ordinality)
+    `, req.CollectionID, pq.Array(req.ProductIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert collection products: %v", err)
+	}
+
+	collection.ProductIDs, err = r.getProductIDs(ctx, tx, req.CollectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return collection, nil
+}
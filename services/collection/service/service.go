@@ -0,0 +1,109 @@
+package collectionservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+	"github.com/wafi04/backend/pkg/utils"
+	collection "github.com/wafi04/backend/services/collection/repository"
+)
+
+type CollectionService struct {
+	collectionRepo collection.CollectionRepository
+}
+
+func NewCollectionService(collectionRepo collection.CollectionRepository) *CollectionService {
+	return &CollectionService{
+		collectionRepo: collectionRepo,
+	}
+}
+
+// normalizeRules slugifies the tag rules and checks that at least one rule
+// is set and the price range is ordered.
+func normalizeRules(rules *types.CollectionRules) error {
+	if rules == nil {
+		return fmt.Errorf("invalid collection: rules are required for rule collections")
+	}
+	if len(rules.Tags) == 0 && len(rules.CategoryIDs) == 0 && rules.MinPrice == nil && rules.MaxPrice == nil {
+		return fmt.Errorf("invalid collection: at least one rule is required")
+	}
+	if rules.MinPrice != nil && rules.MaxPrice != nil && *rules.MinPrice > *rules.MaxPrice {
+		return fmt.Errorf("invalid collection: min_price must not exceed max_price")
+	}
+	for i, tag := range rules.Tags {
+		rules.Tags[i] = utils.Slugify(tag)
+	}
+	return nil
+}
+
+func (s *CollectionService) CreateCollection(ctx context.Context, req *request.CreateCollectionRequest) (*types.Collection, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("invalid collection: name is required")
+	}
+
+	collectionType := req.Type
+	if collectionType == "" {
+		collectionType = types.CollectionTypeManual
+	}
+	switch collectionType {
+	case types.CollectionTypeManual:
+		if req.Rules != nil {
+			return nil, fmt.Errorf("invalid collection: manual collections do not take rules")
+		}
+	case types.CollectionTypeRule:
+		if err := normalizeRules(req.Rules); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid collection type: %s", collectionType)
+	}
+
+	return s.collectionRepo.Create(ctx, &types.Collection{
+		ID:          uuid.New().String(),
+		Name:        req.Name,
+		Description: req.Description,
+		Image:       req.Image,
+		Type:        collectionType,
+		Rules:       req.Rules,
+	})
+}
+
+func (s *CollectionService) GetCollectionBySlug(ctx context.Context, slug string) (*types.Collection, error) {
+	return s.collectionRepo.GetBySlug(ctx, slug)
+}
+
+func (s *CollectionService) ListCollections(ctx context.Context) (*response.ListCollectionsResponse, error) {
+	return s.collectionRepo.List(ctx)
+}
+
+func (s *CollectionService) UpdateCollection(ctx context.Context, req *request.UpdateCollectionRequest) (*types.Collection, error) {
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		return nil, fmt.Errorf("invalid collection: name cannot be empty")
+	}
+	if req.Rules != nil {
+		if err := normalizeRules(req.Rules); err != nil {
+			return nil, err
+		}
+	}
+	return s.collectionRepo.Update(ctx, req)
+}
+
+func (s *CollectionService) DeleteCollection(ctx context.Context, id string) error {
+	return s.collectionRepo.Delete(ctx, id)
+}
+
+func (s *CollectionService) SetCollectionProducts(ctx context.Context, req *request.SetCollectionProductsRequest) (*types.Collection, error) {
+	seen := make(map[string]bool, len(req.ProductIDs))
+	for _, id := range req.ProductIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("invalid request: product %s listed twice", id)
+		}
+		seen[id] = true
+	}
+	return s.collectionRepo.SetProducts(ctx, req)
+}
@@ -0,0 +1,360 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/HugoSmits86/nativewebp"
+	config "github.com/wafi04/backend/config/development"
+	"github.com/wafi04/backend/pkg/logger"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	_ "golang.org/x/image/webp"
+)
+
+// DerivativeSpec is one configured size. Images narrower than Width are
+// encoded at their own size rather than upscaled.
+type DerivativeSpec struct {
+	Name  string
+	Width int
+}
+
+var DefaultDerivativeSpecs = []DerivativeSpec{
+	{Name: "thumbnail", Width: 150},
+	{Name: "card", Width: 480},
+	{Name: "zoom", Width: 1600},
+}
+
+// Encoder writes img in one output format.
+type Encoder func(w io.Writer, img image.Image) error
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]Encoder{
+		"jpeg": func(w io.Writer, img image.Image) error {
+			return jpeg.Encode(w, flatten(img), &jpeg.Options{Quality: 82})
+		},
+		"png": func(w io.Writer, img image.Image) error {
+			return png.Encode(w, img)
+		},
+		// nativewebp writes lossless WebP, which keeps alpha and needs no
+		// cgo.
+		"webp": func(w io.Writer, img image.Image) error {
+			return nativewebp.Encode(w, img, nil)
+		},
+	}
+	extensions = map[string]string{"jpeg": ".jpg", "png": ".png", "webp": ".webp"}
+)
+
+// RegisterEncoder adds or replaces the encoder for a format and the file
+// extension its encodes are stored under.
+func RegisterEncoder(format, ext string, enc Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	encoders[format] = enc
+	extensions[format] = ext
+}
+
+func encoderFor(format string) (Encoder, string, bool) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	enc, ok := encoders[format]
+	return enc, extensions[format], ok
+}
+
+type DerivativeConfig struct {
+	Specs   []DerivativeSpec
+	Formats []string
+	Workers int
+	Queue   int
+}
+
+// DerivativeConfigFromEnv reads IMAGE_DERIVATIVES ("thumbnail:150,card:480"),
+// IMAGE_DERIVATIVE_FORMATS ("webp,jpeg") and IMAGE_DERIVATIVE_WORKERS.
+func DerivativeConfigFromEnv() DerivativeConfig {
+	cfg := DerivativeConfig{
+		Specs:   DefaultDerivativeSpecs,
+		Formats: []string{"webp", "jpeg"},
+		Workers: 2,
+		Queue:   100,
+	}
+
+	if raw := config.LoadEnv("IMAGE_DERIVATIVES"); raw != "" {
+		var specs []DerivativeSpec
+		for _, part := range strings.Split(raw, ",") {
+			name, width, ok := strings.Cut(strings.TrimSpace(part), ":")
+			w, err := strconv.Atoi(width)
+			if !ok || err != nil || name == "" || w <= 0 {
+				continue
+			}
+			specs = append(specs, DerivativeSpec{Name: name, Width: w})
+		}
+		if len(specs) > 0 {
+			cfg.Specs = specs
+		}
+	}
+	if raw := config.LoadEnv("IMAGE_DERIVATIVE_FORMATS"); raw != "" {
+		cfg.Formats = strings.Split(strings.ToLower(strings.ReplaceAll(raw, " ", "")), ",")
+	}
+	if n, err := strconv.Atoi(config.LoadEnv("IMAGE_DERIVATIVE_WORKERS")); err == nil && n > 0 {
+		cfg.Workers = n
+	}
+	return cfg
+}
+
+// DerivativeJob asks for the derivatives of one stored image. Key is the
+// folder/public-id the derivatives are stored next to.
+type DerivativeJob struct {
+	ImageID string
+	Folder  string
+	Key     string
+	Data    []byte
+}
+
+// SaveDerivatives persists the derivatives produced for an image.
+type SaveDerivatives func(ctx context.Context, imageID string, derivatives []*types.ImageDerivative) error
+
+// DerivativePool resizes and encodes uploaded images on a fixed number of
+// workers. A nil pool accepts and drops every job, which is what the
+// Cloudinary driver uses since it transforms images on delivery.
+type DerivativePool struct {
+	storage FileStorage
+	cfg     DerivativeConfig
+	save    SaveDerivatives
+	jobs    chan DerivativeJob
+	log     *logger.Logger
+}
+
+// NewDerivativePool fails when cfg names a format without a registered
+// encoder, so a typo in IMAGE_DERIVATIVE_FORMATS stops the server at startup
+// instead of silently producing fewer derivatives.
+func NewDerivativePool(storage FileStorage, cfg DerivativeConfig, save SaveDerivatives) (*DerivativePool, error) {
+	if len(cfg.Formats) == 0 {
+		return nil, fmt.Errorf("no derivative formats configured")
+	}
+	for _, format := range cfg.Formats {
+		if _, _, ok := encoderFor(format); !ok {
+			return nil, fmt.Errorf("unknown derivative format: %s", format)
+		}
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.Queue <= 0 {
+		cfg.Queue = 100
+	}
+	return &DerivativePool{
+		storage: storage,
+		cfg:     cfg,
+		save:    save,
+		jobs:    make(chan DerivativeJob, cfg.Queue),
+		log:     logger.NewLogger(),
+	}, nil
+}
+
+// Enqueue hands a job to the workers without blocking. It fails when the
+// queue is full.
+func (p *DerivativePool) Enqueue(job DerivativeJob) error {
+	if p == nil {
+		return nil
+	}
+	select {
+	case p.jobs <- job:
+		return nil
+	default:
+		return fmt.Errorf("derivative queue is full")
+	}
+}
+
+// Run starts the workers and blocks until ctx is cancelled.
+func (p *DerivativePool) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < p.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-p.jobs:
+					if err := p.process(ctx, job); err != nil {
+						p.log.Log(logger.ErrorLevel, "Failed to build derivatives for image %s: %v", job.ImageID, err)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (p *DerivativePool) process(ctx context.Context, job DerivativeJob) error {
+	derivatives, err := p.Build(ctx, job)
+	if err != nil {
+		return err
+	}
+	return p.save(ctx, job.ImageID, derivatives)
+}
+
+// Build decodes the job's image and uploads every configured size in every
+// configured format. The encoders write no EXIF, so JPEG orientation is
+// applied to the pixels first.
+func (p *DerivativePool) Build(ctx context.Context, job DerivativeJob) ([]*types.ImageDerivative, error) {
+	src, format, err := image.Decode(bytes.NewReader(job.Data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %v", err)
+	}
+	if format == "jpeg" {
+		src = Orient(src, jpegOrientation(job.Data))
+	}
+
+	var derivatives []*types.ImageDerivative
+	for _, spec := range p.cfg.Specs {
+		resized := Resize(src, spec.Width)
+		bounds := resized.Bounds()
+
+		for _, format := range p.cfg.Formats {
+			enc, ext, ok := encoderFor(format)
+			if !ok {
+				return nil, fmt.Errorf("unknown derivative format: %s", format)
+			}
+
+			var buf bytes.Buffer
+			if err := enc(&buf, resized); err != nil {
+				return nil, fmt.Errorf("failed to encode %s %s: %v", spec.Name, format, err)
+			}
+
+			res, err := p.storage.UploadFile(ctx, &request.FileUploadRequest{
+				FileData: NewMemoryFile(buf.Bytes()),
+				FileName: spec.Name + ext,
+				Folder:   job.Folder,
+				PublicID: job.Key + "_" + spec.Name,
+				// Rebuilding derivatives replaces the previous encodes.
+				Overwrite: true,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to upload %s %s: %v", spec.Name, format, err)
+			}
+
+			derivatives = append(derivatives, &types.ImageDerivative{
+				Name:   spec.Name,
+				Format: format,
+				Width:  bounds.Dx(),
+				Height: bounds.Dy(),
+				URL:    res.URL,
+			})
+		}
+	}
+	return derivatives, nil
+}
+
+// Resize scales src down to width, keeping its aspect ratio, by averaging
+// the source pixels that fall into each destination pixel. Colours are
+// weighted by alpha so transparent pixels do not bleed into the edges.
+func Resize(src image.Image, width int) image.Image {
+	sb := src.Bounds()
+	sw, sh := sb.Dx(), sb.Dy()
+	if width <= 0 || width >= sw {
+		return src
+	}
+	height := sh * width / sw
+	if height < 1 {
+		height = 1
+	}
+
+	rgba := image.NewNRGBA(image.Rect(0, 0, sw, sh))
+	draw.Draw(rgba, rgba.Bounds(), src, sb.Min, draw.Src)
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*sh/height, (y+1)*sh/height
+		for x := 0; x < width; x++ {
+			x0, x1 := x*sw/width, (x+1)*sw/width
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := rgba.Pix[sy*rgba.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					px := row[sx*4 : sx*4+4]
+					alpha := uint64(px[3])
+					r += uint64(px[0]) * alpha
+					g += uint64(px[1]) * alpha
+					b += uint64(px[2]) * alpha
+					a += alpha
+					n++
+				}
+			}
+			i := y*dst.Stride + x*4
+			if a > 0 {
+				dst.Pix[i] = uint8(r / a)
+				dst.Pix[i+1] = uint8(g / a)
+				dst.Pix[i+2] = uint8(b / a)
+			}
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// Orient rotates and flips img so that an image stored with the given EXIF
+// Orientation (1-8) displays upright without it.
+func Orient(img image.Image, orientation uint16) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	src := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+
+	// Orientations 5 to 8 turn the image by a quarter, swapping its sides.
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	// Each destination pixel is copied from the source pixel that the
+	// orientation places there.
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], src.Pix[sy*src.Stride+sx*4:])
+		}
+	}
+	return dst
+}
+
+// flatten draws img over white so formats without alpha do not turn
+// transparent areas black.
+func flatten(img image.Image) image.Image {
+	out := image.NewRGBA(img.Bounds())
+	draw.Draw(out, out.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(out, out.Bounds(), img, img.Bounds().Min, draw.Over)
+	return out
+}
@@ -0,0 +1,23 @@
+package files
+
+import (
+	"context"
+	"io"
+	"time"
+
+	response "github.com/wafi04/backend/pkg/types/res"
+)
+
+// DirectUploader is implemented by drivers that let clients upload straight
+// to storage through a short-lived signed URL. Keys are the folder/name
+// paths built by ObjectKey.
+type DirectUploader interface {
+	PresignUpload(ctx context.Context, key, contentType string, maxBytes int64, ttl time.Duration) (*response.UploadTarget, error)
+	OpenFile(ctx context.Context, key string) (io.ReadCloser, error)
+	FileURL(key string) string
+}
+
+// LocalUploadPrefix is the API path the local driver's signed upload URLs
+// point at; the upload handler passes requests there to
+// LocalStorage.ReceiveUpload.
+const LocalUploadPrefix = "/api/v1/uploads/local"
@@ -0,0 +1,42 @@
+package files
+
+import (
+	"bytes"
+	"regexp"
+
+	response "github.com/wafi04/backend/pkg/types/res"
+)
+
+// pdfActiveContent matches the plain forms of actions that run when a PDF is
+// opened. Names hidden inside compressed object streams are not seen; this
+// only keeps the obvious cases out of files we serve to shoppers.
+var pdfActiveContent = regexp.MustCompile(`/(JavaScript|JS|Launch|EmbeddedFile)\b`)
+
+// checkPDF accepts complete PDF files without scripts or embedded files.
+func checkPDF(data []byte) *response.FileValidationError {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return &response.FileValidationError{
+			Code:    response.FileErrorDocument,
+			Message: "document is not a PDF",
+		}
+	}
+
+	tail := data
+	if len(tail) > 1024 {
+		tail = tail[len(tail)-1024:]
+	}
+	if !bytes.Contains(tail, []byte("%%EOF")) {
+		return &response.FileValidationError{
+			Code:    response.FileErrorDocument,
+			Message: "document is truncated",
+		}
+	}
+
+	if pdfActiveContent.Match(data) {
+		return &response.FileValidationError{
+			Code:    response.FileErrorDocument,
+			Message: "document contains scripts or embedded files",
+		}
+	}
+	return nil
+}
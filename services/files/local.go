@@ -0,0 +1,241 @@
+package files
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+	"github.com/wafi04/backend/pkg/utils"
+)
+
+// DefaultLocalDir is used when STORAGE_LOCAL_DIR is not set.
+const DefaultLocalDir = "uploads"
+
+// LocalStorage writes uploads to a directory on disk that the router serves
+// under StaticPrefix. It needs no credentials, which makes it the driver for
+// development and tests.
+type LocalStorage struct {
+	dir     string
+	baseURL string
+	// secret signs direct upload URLs. Without a configured secret a random
+	// one is used, so URLs issued before a restart stop working.
+	secret []byte
+}
+
+func NewLocalStorage(dir, baseURL, secret string) (*LocalStorage, error) {
+	if dir == "" {
+		dir = DefaultLocalDir
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %v", err)
+	}
+
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate signing secret: %v", err)
+		}
+	}
+	return &LocalStorage{dir: dir, baseURL: strings.TrimRight(baseURL, "/"), secret: key}, nil
+}
+
+func (s *LocalStorage) UploadFile(
+	ctx context.Context,
+	req *request.FileUploadRequest,
+) (*response.FileUploadResponse, error) {
+	data, err := io.ReadAll(req.FileData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		key, err := ObjectKey(req, data)
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.writeFile(key, data, req.Overwrite)
+		if err == ErrFileExists && req.PublicID == "" && attempt < uploadAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &response.FileUploadResponse{
+			URL:      s.FileURL(key),
+			PublicID: key,
+		}, nil
+	}
+}
+
+// writeFile stores data under key, failing with ErrFileExists when the file
+// is already there and overwrite is false.
+func (s *LocalStorage) writeFile(key string, data []byte, overwrite bool) error {
+	target := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create folder: %v", err)
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(target, flags, 0644)
+	if os.IsExist(err) {
+		return ErrFileExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+	return nil
+}
+
+func (s *LocalStorage) DeleteFile(ctx context.Context, publicID, contentType string) error {
+	key, err := cleanKey(publicID)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete file: %v", err)
+	}
+	return nil
+}
+
+// ObjectKey builds the folder/public-id path a file is stored under by the
+// drivers that address files by path. The extension comes from FileName or,
+// failing that, the sniffed content type.
+func ObjectKey(req *request.FileUploadRequest, data []byte) (string, error) {
+	publicID := req.PublicID
+	if publicID == "" {
+		publicID = utils.GenerateRandomId("FILE")
+	}
+
+	ext := path.Ext(req.FileName)
+	if ext == "" {
+		ext = extensionFor(data)
+	}
+
+	key := path.Clean(path.Join("/", req.Folder, publicID+ext))
+	key = strings.TrimPrefix(key, "/")
+	if key == "" || key == "." {
+		return "", fmt.Errorf("invalid file path")
+	}
+	return key, nil
+}
+
+// PresignUpload returns an HMAC-signed URL that accepts one PUT of at most
+// maxBytes to key until ttl has passed.
+func (s *LocalStorage) PresignUpload(ctx context.Context, key, contentType string, maxBytes int64, ttl time.Duration) (*response.UploadTarget, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	expires := time.Now().Add(ttl).Unix()
+	limit := strconv.FormatInt(maxBytes, 10)
+	exp := strconv.FormatInt(expires, 10)
+
+	query := url.Values{}
+	query.Set("expires", exp)
+	query.Set("max", limit)
+	query.Set("signature", s.sign(key, exp, limit))
+
+	return &response.UploadTarget{
+		URL:       s.baseURL + LocalUploadPrefix + "/" + key + "?" + query.Encode(),
+		Method:    http.MethodPut,
+		Headers:   map[string]string{"Content-Type": contentType},
+		ExpiresAt: expires,
+	}, nil
+}
+
+// ReceiveUpload stores body under key after checking the signature and
+// expiry issued by PresignUpload. A key is written at most once, so a signed
+// URL cannot replace a file after it has been confirmed.
+func (s *LocalStorage) ReceiveUpload(key, expires, limit, signature string, body io.Reader) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, expires, limit))) {
+		return fmt.Errorf("invalid upload signature")
+	}
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return fmt.Errorf("invalid upload signature: expired")
+	}
+	maxBytes, err := strconv.ParseInt(limit, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid upload signature")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxBytes+1))
+	if err != nil {
+		return fmt.Errorf("failed to read upload: %v", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return &response.FileValidationError{
+			Code:    response.FileErrorTooLarge,
+			Message: fmt.Sprintf("file is larger than %d bytes", maxBytes),
+		}
+	}
+
+	err = s.writeFile(key, data, false)
+	if err == ErrFileExists {
+		return fmt.Errorf("invalid upload signature: file already uploaded")
+	}
+	return err
+}
+
+func (s *LocalStorage) OpenFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("file not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %v", err)
+	}
+	return f, nil
+}
+
+func (s *LocalStorage) FileURL(key string) string {
+	return s.baseURL + path.Join(StaticPrefix, key)
+}
+
+func (s *LocalStorage) sign(key, expires, limit string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(http.MethodPut + "\n" + key + "\n" + expires + "\n" + limit))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// cleanKey keeps a key inside the storage directory.
+func cleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(path.Clean("/"+key), "/")
+	if key == "" {
+		return "", fmt.Errorf("invalid file path")
+	}
+	return key, nil
+}
@@ -0,0 +1,241 @@
+package files
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// stripMetadata removes EXIF blocks from JPEG, PNG and WebP images, keeping
+// only the orientation of JPEGs. Pixel data and colour profiles are copied
+// unchanged; anything it cannot parse is returned as is and left to the
+// decoder checks above it.
+func stripMetadata(contentType string, data []byte) []byte {
+	var out []byte
+	var ok bool
+	switch contentType {
+	case "image/jpeg":
+		out, ok = stripJPEG(data)
+	case "image/png":
+		out, ok = stripPNG(data)
+	case "image/webp":
+		out, ok = stripWebP(data)
+	}
+	if !ok {
+		return data
+	}
+	return out
+}
+
+// stripJPEG drops APP1 segments, which hold EXIF and XMP, from the header
+// and copies everything from the start of scan onwards. The EXIF Orientation
+// tag is carried over in a minimal APP1 of its own, since without it viewers
+// show camera photos sideways.
+func stripJPEG(data []byte) ([]byte, bool) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, false
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return nil, false
+		}
+		marker := data[i+1]
+		if marker == 0xDA {
+			return append(out, data[i:]...), true
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, false
+		}
+		if marker != 0xE1 {
+			out = append(out, data[i:end]...)
+		} else if orientation, ok := exifOrientation(data[i+4 : end]); ok && orientation != 1 {
+			out = append(out, orientationSegment(orientation)...)
+		}
+		i = end
+	}
+	return nil, false
+}
+
+// jpegOrientation returns the EXIF Orientation of a JPEG, or 1 when it has
+// none.
+func jpegOrientation(data []byte) uint16 {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF && data[i+1] != 0xDA; {
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) {
+			break
+		}
+		if data[i+1] == 0xE1 {
+			if orientation, ok := exifOrientation(data[i+4 : end]); ok {
+				return orientation
+			}
+		}
+		i = end
+	}
+	return 1
+}
+
+var exifHeader = []byte("Exif\x00\x00")
+
+const exifOrientationTag = 0x0112
+
+// exifOrientation reads the Orientation tag from the first IFD of an APP1
+// EXIF payload.
+func exifOrientation(payload []byte) (uint16, bool) {
+	if !bytes.HasPrefix(payload, exifHeader) {
+		return 0, false
+	}
+	tiff := payload[len(exifHeader):]
+	if len(tiff) < 8 {
+		return 0, false
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, false
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0, false
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < count; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 0, false
+		}
+		if order.Uint16(tiff[entry:]) != exifOrientationTag {
+			continue
+		}
+		// The value is a single SHORT stored inline.
+		if order.Uint16(tiff[entry+2:]) != 3 || order.Uint32(tiff[entry+4:]) != 1 {
+			return 0, false
+		}
+		orientation := order.Uint16(tiff[entry+8:])
+		return orientation, orientation >= 1 && orientation <= 8
+	}
+	return 0, false
+}
+
+// orientationSegment builds an APP1 segment whose EXIF block holds nothing
+// but the Orientation tag.
+func orientationSegment(orientation uint16) []byte {
+	tiff := make([]byte, 26)
+	copy(tiff, "MM\x00\x2A")
+	binary.BigEndian.PutUint32(tiff[4:], 8)
+	binary.BigEndian.PutUint16(tiff[8:], 1)
+	binary.BigEndian.PutUint16(tiff[10:], exifOrientationTag)
+	binary.BigEndian.PutUint16(tiff[12:], 3)
+	binary.BigEndian.PutUint32(tiff[14:], 1)
+	binary.BigEndian.PutUint16(tiff[18:], orientation)
+	// tiff[22:26] is the zero offset of the next IFD.
+
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	segment = append(segment, exifHeader...)
+	segment = append(segment, tiff...)
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(segment)-2))
+	return segment
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// stripPNG drops eXIf chunks.
+func stripPNG(data []byte) ([]byte, bool) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, false
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	i := len(pngSignature)
+	for i+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+		if end > len(data) {
+			return nil, false
+		}
+		if string(data[i+4:i+8]) != "eXIf" {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return out, i == len(data)
+}
+
+// stripWebP drops the EXIF chunk of an extended WebP and clears its flag in
+// the VP8X header.
+func stripWebP(data []byte) ([]byte, bool) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, false
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+	i := 12
+	for i+8 <= len(data) {
+		fourCC := string(data[i : i+4])
+		length := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + length + length%2
+		if end > len(data) {
+			return nil, false
+		}
+		switch fourCC {
+		case "EXIF":
+		case "VP8X":
+			chunk := append([]byte(nil), data[i:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= 0x08
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, i == len(data)
+}
+
+// webpSize reads the canvas size from a lossy, lossless or extended WebP.
+func webpSize(data []byte) (int, int, error) {
+	if len(data) < 30 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return 0, 0, fmt.Errorf("not a webp image")
+	}
+
+	chunk := data[20:]
+	switch string(data[12:16]) {
+	case "VP8 ":
+		if chunk[3] != 0x9D || chunk[4] != 0x01 || chunk[5] != 0x2A {
+			return 0, 0, fmt.Errorf("invalid vp8 frame")
+		}
+		width := int(binary.LittleEndian.Uint16(chunk[6:]) & 0x3FFF)
+		height := int(binary.LittleEndian.Uint16(chunk[8:]) & 0x3FFF)
+		return width, height, nil
+	case "VP8L":
+		if chunk[0] != 0x2F {
+			return 0, 0, fmt.Errorf("invalid vp8l header")
+		}
+		bits := binary.LittleEndian.Uint32(chunk[1:])
+		return int(bits&0x3FFF) + 1, int((bits>>14)&0x3FFF) + 1, nil
+	case "VP8X":
+		width := int(chunk[4]) | int(chunk[5])<<8 | int(chunk[6])<<16
+		height := int(chunk[7]) | int(chunk[8])<<8 | int(chunk[9])<<16
+		return width + 1, height + 1, nil
+	default:
+		return 0, 0, fmt.Errorf("unknown webp chunk")
+	}
+}
@@ -0,0 +1,35 @@
+package files
+
+import (
+	"net/http"
+	"strings"
+)
+
+var extensionsByType = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"image/bmp":       ".bmp",
+	"video/mp4":       ".mp4",
+	"video/webm":      ".webm",
+	"application/pdf": ".pdf",
+	"application/zip": ".zip",
+	"text/plain":      ".txt",
+}
+
+// extensionFor picks a file extension from the sniffed content type, or
+// none when the type is unknown.
+func extensionFor(data []byte) string {
+	contentType := http.DetectContentType(data)
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return extensionsByType[contentType]
+}
+
+// ExtensionForType returns the file extension used for contentType, or none
+// when the type is unknown.
+func ExtensionForType(contentType string) string {
+	return extensionsByType[contentType]
+}
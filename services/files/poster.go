@@ -0,0 +1,76 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoFrameExtractor is returned by ExtractPosterFrame when ffmpeg is not
+// installed. Callers are expected to carry on without a poster.
+var ErrNoFrameExtractor = errors.New("ffmpeg is not available")
+
+// posterTimeout bounds one ffmpeg run.
+const posterTimeout = 30 * time.Second
+
+// ExtractPosterFrame decodes the frame at offset from an MP4 or WebM video
+// and returns it as a JPEG. It shells out to ffmpeg, since the standard
+// library has no video decoders.
+func ExtractPosterFrame(ctx context.Context, video []byte, offset time.Duration) ([]byte, error) {
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, ErrNoFrameExtractor
+	}
+
+	// ffmpeg needs to seek in MP4s, which it cannot do on a pipe.
+	in, err := os.CreateTemp("", "video-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(in.Name())
+	if _, err := in.Write(video); err != nil {
+		in.Close()
+		return nil, fmt.Errorf("failed to write temp file: %v", err)
+	}
+	if err := in.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write temp file: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, posterTimeout)
+	defer cancel()
+
+	var out, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ffmpeg,
+		"-v", "error",
+		"-ss", strconv.FormatFloat(offset.Seconds(), 'f', 3, 64),
+		"-i", in.Name(),
+		"-frames:v", "1",
+		"-f", "image2",
+		"-c:v", "mjpeg",
+		"pipe:1",
+	)
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to extract poster frame: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if out.Len() == 0 {
+		return nil, fmt.Errorf("failed to extract poster frame: no frame at %s", offset)
+	}
+	return out.Bytes(), nil
+}
+
+// PosterOffset picks the frame a poster is taken from: one second in, or
+// the middle of shorter videos, which often open on a black frame.
+func PosterOffset(duration time.Duration) time.Duration {
+	if duration > 0 && duration < 2*time.Second {
+		return duration / 2
+	}
+	return time.Second
+}
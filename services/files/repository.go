@@ -2,10 +2,13 @@ package files
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 
 	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	request "github.com/wafi04/backend/pkg/types/req"
 	response "github.com/wafi04/backend/pkg/types/res"
@@ -42,15 +45,50 @@ func (s *Cloudinary) UploadFile(
 	tempFile.Close()
 
 	uploadResult, err := s.cloudinary.Upload.Upload(ctx, tempFile.Name(), uploader.UploadParams{
-		Folder:   req.Folder,
-		PublicID: req.PublicID,
+		Folder:    req.Folder,
+		PublicID:  req.PublicID,
+		Overwrite: api.Bool(req.Overwrite),
 	})
 	if err != nil {
 		return nil, err
 	}
+	// With overwrite off Cloudinary answers with the stored asset and
+	// flags it as existing instead of failing.
+	if raw, ok := uploadResult.Response.(map[string]interface{}); ok && raw["existing"] == true {
+		return nil, ErrFileExists
+	}
 
 	return &response.FileUploadResponse{
 		URL:      uploadResult.SecureURL,
 		PublicID: uploadResult.PublicID,
 	}, nil
 }
+
+func (s *Cloudinary) DeleteFile(ctx context.Context, publicID, contentType string) error {
+	result, err := s.cloudinary.Upload.Destroy(ctx, uploader.DestroyParams{
+		PublicID:     publicID,
+		ResourceType: resourceType(contentType),
+	})
+	if err != nil {
+		return err
+	}
+	if result.Error.Message != "" {
+		return fmt.Errorf("failed to delete %s: %s", publicID, result.Error.Message)
+	}
+	return nil
+}
+
+// resourceType maps a MIME type to the resource type Cloudinary's "auto"
+// upload files it under. Destroy only finds a file under its own type, and
+// defaults to image. Cloudinary keeps PDFs as images so they can be
+// rendered page by page.
+func resourceType(contentType string) string {
+	switch {
+	case strings.HasPrefix(contentType, "image/"), contentType == "application/pdf":
+		return "image"
+	case strings.HasPrefix(contentType, "video/"), strings.HasPrefix(contentType, "audio/"):
+		return "video"
+	default:
+		return "raw"
+	}
+}
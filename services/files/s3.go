@@ -0,0 +1,288 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+)
+
+// S3Storage uploads to any S3-compatible bucket (AWS, MinIO, R2, ...) with
+// path-style addressing and Signature Version 4.
+type S3Storage struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	publicURL string
+	client    *http.Client
+}
+
+func NewS3Storage(cfg Config) (*S3Storage, error) {
+	if cfg.S3Endpoint == "" || cfg.S3Bucket == "" || cfg.S3AccessKey == "" || cfg.S3SecretKey == "" {
+		return nil, fmt.Errorf("s3 storage needs an endpoint, bucket and credentials")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(cfg.S3Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint: %s", cfg.S3Endpoint)
+	}
+
+	region := cfg.S3Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	publicURL := cfg.S3PublicURL
+	if publicURL == "" {
+		publicURL = endpoint.String() + "/" + cfg.S3Bucket
+	}
+
+	return &S3Storage{
+		endpoint:  endpoint,
+		region:    region,
+		bucket:    cfg.S3Bucket,
+		accessKey: cfg.S3AccessKey,
+		secretKey: cfg.S3SecretKey,
+		publicURL: strings.TrimRight(publicURL, "/"),
+		client:    &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+func (s *S3Storage) UploadFile(
+	ctx context.Context,
+	req *request.FileUploadRequest,
+) (*response.FileUploadResponse, error) {
+	data, err := io.ReadAll(req.FileData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		key, err := ObjectKey(req, data)
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.putObject(ctx, key, data, req.Overwrite)
+		if err == ErrFileExists && req.PublicID == "" && attempt < uploadAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &response.FileUploadResponse{
+			URL:      s.FileURL(key),
+			PublicID: key,
+		}, nil
+	}
+}
+
+// putObject uploads data to key. Unless overwrite is set the PUT carries
+// If-None-Match: *, so the bucket refuses it with 412 when the key is taken.
+func (s *S3Storage) putObject(ctx context.Context, key string, data []byte, overwrite bool) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to build upload request: %v", err)
+	}
+	httpReq.Header.Set("Content-Type", http.DetectContentType(data))
+	if !overwrite {
+		httpReq.Header.Set("If-None-Match", "*")
+	}
+	s.sign(httpReq, data, time.Now().UTC())
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// 409 is what S3 answers when a concurrent conditional write won.
+	if resp.StatusCode == http.StatusPreconditionFailed || resp.StatusCode == http.StatusConflict {
+		return ErrFileExists
+	}
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to upload file: %s: %s", resp.Status, body)
+	}
+	return nil
+}
+
+func (s *S3Storage) DeleteFile(ctx context.Context, publicID, contentType string) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(publicID), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build delete request: %v", err)
+	}
+	s.sign(httpReq, nil, time.Now().UTC())
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to delete file: %s: %s", resp.Status, body)
+	}
+	return nil
+}
+
+func (s *S3Storage) objectURL(key string) string {
+	u := *s.endpoint
+	u.Path = "/" + s.bucket + "/" + key
+	return u.String()
+}
+
+// sign adds an AWS Signature Version 4 Authorization header to req.
+func (s *S3Storage) sign(req *http.Request, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		signedHeaders = "content-type;" + signedHeaders
+		canonicalHeaders = "content-type:" + contentType + "\n" + canonicalHeaders
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signature := hex.EncodeToString(hmacSHA256(s.signingKey(date), stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature,
+	))
+}
+
+func (s *S3Storage) signingKey(date string) []byte {
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	return hmacSHA256(key, "aws4_request")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// PresignUpload returns a query-signed PUT URL for key. S3 cannot bound the
+// size of a presigned PUT, so maxBytes is enforced when the upload is
+// confirmed.
+func (s *S3Storage) PresignUpload(ctx context.Context, key, contentType string, maxBytes int64, ttl time.Duration) (*response.UploadTarget, error) {
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	scope := date + "/" + s.region + "/s3/aws4_request"
+
+	u, err := url.Parse(s.objectURL(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build upload url: %v", err)
+	}
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.accessKey+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(ttl.Seconds())))
+	// If-None-Match makes the URL good for one upload: it cannot replace
+	// the object once it has been written and confirmed.
+	query.Set("X-Amz-SignedHeaders", "content-type;host;if-none-match")
+	// url.Values encodes spaces as "+", SigV4 wants "%20".
+	canonicalQuery := strings.ReplaceAll(query.Encode(), "+", "%20")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodPut,
+		u.EscapedPath(),
+		canonicalQuery,
+		"content-type:" + contentType + "\nhost:" + u.Host + "\nif-none-match:*\n",
+		"content-type;host;if-none-match",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	u.RawQuery = canonicalQuery + "&X-Amz-Signature=" + hex.EncodeToString(hmacSHA256(s.signingKey(date), stringToSign))
+
+	return &response.UploadTarget{
+		URL:    u.String(),
+		Method: http.MethodPut,
+		Headers: map[string]string{
+			"Content-Type":  contentType,
+			"If-None-Match": "*",
+		},
+		ExpiresAt: now.Add(ttl).Unix(),
+	}, nil
+}
+
+func (s *S3Storage) OpenFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build download request: %v", err)
+	}
+	s.sign(httpReq, nil, time.Now().UTC())
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %v", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, fmt.Errorf("file not found")
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("failed to download file: %s: %s", resp.Status, body)
+	}
+	return resp.Body, nil
+}
+
+func (s *S3Storage) FileURL(key string) string {
+	return s.publicURL + "/" + key
+}
@@ -0,0 +1,118 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudinary/cloudinary-go/v2"
+	config "github.com/wafi04/backend/config/development"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+)
+
+// FileStorage is what handlers upload through. Cloudinary, the local disk
+// and S3-compatible buckets all implement it.
+type FileStorage interface {
+	// UploadFile stores a file and returns its URL and PublicID. It returns
+	// ErrFileExists rather than replace a stored file unless req.Overwrite
+	// is set.
+	UploadFile(ctx context.Context, req *request.FileUploadRequest) (*response.FileUploadResponse, error)
+	// DeleteFile removes the file with the PublicID returned by UploadFile.
+	// contentType is the MIME type of the stored file, which Cloudinary needs
+	// to look the file up. Deleting a file that does not exist is not an
+	// error.
+	DeleteFile(ctx context.Context, publicID, contentType string) error
+}
+
+// ErrFileExists is returned by UploadFile when the key is already taken.
+var ErrFileExists = errors.New("file already exists")
+
+// uploadAttempts bounds how often a driver draws a new key for an upload
+// without a PublicID after the generated one turned out to be taken.
+const uploadAttempts = 3
+
+const (
+	DriverCloudinary = "cloudinary"
+	DriverLocal      = "local"
+	DriverS3         = "s3"
+)
+
+// StaticPrefix is the URL path the local driver's files are served under.
+const StaticPrefix = "/static"
+
+type Config struct {
+	Driver string
+
+	CloudinaryCloudName string
+	CloudinaryAPIKey    string
+	CloudinaryAPISecret string
+
+	// LocalDir is where the local driver writes files and LocalBaseURL is
+	// prepended to StaticPrefix to build their URLs.
+	LocalDir     string
+	LocalBaseURL string
+	// LocalSecret signs the local driver's direct upload URLs.
+	LocalSecret string
+
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	// S3PublicURL overrides the endpoint/bucket URL returned to clients,
+	// e.g. for a CDN in front of the bucket.
+	S3PublicURL string
+}
+
+// ConfigFromEnv reads STORAGE_DRIVER and the settings of the selected
+// driver. The driver defaults to cloudinary.
+func ConfigFromEnv() Config {
+	cfg := Config{Driver: strings.ToLower(config.LoadEnv("STORAGE_DRIVER"))}
+	if cfg.Driver == "" {
+		cfg.Driver = DriverCloudinary
+	}
+
+	switch cfg.Driver {
+	case DriverCloudinary:
+		cfg.CloudinaryCloudName = config.LoadEnv("CLOUDINARY_CLOUD_NAME")
+		cfg.CloudinaryAPIKey = config.LoadEnv("CLOUDINARY_API_KEY")
+		cfg.CloudinaryAPISecret = config.LoadEnv("CLOUDINARY_API_SECRET")
+	case DriverLocal:
+		cfg.LocalDir = config.LoadEnv("STORAGE_LOCAL_DIR")
+		if cfg.LocalDir == "" {
+			cfg.LocalDir = DefaultLocalDir
+		}
+		cfg.LocalBaseURL = config.LoadEnv("STORAGE_LOCAL_BASE_URL")
+		cfg.LocalSecret = config.LoadEnv("STORAGE_LOCAL_SECRET")
+	case DriverS3:
+		cfg.S3Endpoint = config.LoadEnv("S3_ENDPOINT")
+		cfg.S3Region = config.LoadEnv("S3_REGION")
+		cfg.S3Bucket = config.LoadEnv("S3_BUCKET")
+		cfg.S3AccessKey = config.LoadEnv("S3_ACCESS_KEY")
+		cfg.S3SecretKey = config.LoadEnv("S3_SECRET_KEY")
+		cfg.S3PublicURL = config.LoadEnv("S3_PUBLIC_URL")
+	}
+	return cfg
+}
+
+// NewDriver builds the driver selected by cfg.Driver. Uploads from clients
+// should go through WithValidation; files the server generates itself can
+// use the driver directly.
+func NewDriver(cfg Config) (FileStorage, error) {
+	switch cfg.Driver {
+	case DriverCloudinary, "":
+		cld, err := cloudinary.NewFromParams(cfg.CloudinaryCloudName, cfg.CloudinaryAPIKey, cfg.CloudinaryAPISecret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize cloudinary: %v", err)
+		}
+		return NewCloudinaryService(cld), nil
+	case DriverLocal:
+		return NewLocalStorage(cfg.LocalDir, cfg.LocalBaseURL, cfg.LocalSecret)
+	case DriverS3:
+		return NewS3Storage(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", cfg.Driver)
+	}
+}
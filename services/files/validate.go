@@ -0,0 +1,251 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+)
+
+// UploadRule limits what may be uploaded for one request.FileType. Zero
+// dimension and aspect fields disable the matching check.
+type UploadRule struct {
+	MaxBytes     int64
+	AllowedTypes []string
+
+	MinWidth, MinHeight int
+	MaxWidth, MaxHeight int
+	// MinAspect and MaxAspect bound width/height.
+	MinAspect, MaxAspect float64
+	// MaxDuration bounds the length of a video.
+	MaxDuration time.Duration
+}
+
+var DefaultUploadRules = map[request.FileType]UploadRule{
+	request.IMAGE: {
+		MaxBytes:     10 << 20,
+		AllowedTypes: []string{"image/jpeg", "image/png", "image/gif", "image/webp"},
+		MinWidth:     64,
+		MinHeight:    64,
+		MaxWidth:     8000,
+		MaxHeight:    8000,
+		MinAspect:    0.25,
+		MaxAspect:    4,
+	},
+	request.VIDEO: {
+		MaxBytes:     200 << 20,
+		AllowedTypes: []string{"video/mp4", "video/webm"},
+		MaxWidth:     3840,
+		MaxHeight:    3840,
+		MaxDuration:  10 * time.Minute,
+	},
+	request.DOCUMENT: {
+		MaxBytes:     20 << 20,
+		AllowedTypes: []string{"application/pdf"},
+	},
+}
+
+// validatingStorage checks every upload against its rule and strips image
+// metadata before handing it to the wrapped driver.
+type validatingStorage struct {
+	next  FileStorage
+	rules map[request.FileType]UploadRule
+}
+
+func WithValidation(next FileStorage, rules map[request.FileType]UploadRule) FileStorage {
+	return &validatingStorage{next: next, rules: rules}
+}
+
+func (s *validatingStorage) UploadFile(
+	ctx context.Context,
+	req *request.FileUploadRequest,
+) (*response.FileUploadResponse, error) {
+	rule, ok := s.rules[req.FileType]
+	if !ok {
+		return nil, &response.FileValidationError{
+			Code:    response.FileErrorUnsupported,
+			Message: fmt.Sprintf("file type %d is not accepted", req.FileType),
+		}
+	}
+
+	data, err := ValidateUpload(req.FileData, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	cleaned := *req
+	cleaned.FileData = NewMemoryFile(data)
+	return s.next.UploadFile(ctx, &cleaned)
+}
+
+func (s *validatingStorage) DeleteFile(ctx context.Context, publicID, contentType string) error {
+	return s.next.DeleteFile(ctx, publicID, contentType)
+}
+
+// ValidateUpload reads r and checks it against rule. It returns the file
+// contents with EXIF metadata removed, or a *response.FileValidationError.
+func ValidateUpload(r io.Reader, rule UploadRule) ([]byte, error) {
+	limit := rule.MaxBytes
+	if limit <= 0 {
+		limit = 10 << 20
+	}
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, &response.FileValidationError{
+			Code:    response.FileErrorTooLarge,
+			Message: fmt.Sprintf("file is larger than %d bytes", limit),
+		}
+	}
+
+	contentType := http.DetectContentType(data)
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	if !allowed(rule.AllowedTypes, contentType) {
+		return nil, &response.FileValidationError{
+			Code:        response.FileErrorUnsupported,
+			Message:     fmt.Sprintf("%s is not accepted, expected one of %s", contentType, strings.Join(rule.AllowedTypes, ", ")),
+			ContentType: contentType,
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(contentType, "video/"):
+		if err := checkVideo(rule, data); err != nil {
+			err.ContentType = contentType
+			return nil, err
+		}
+		return data, nil
+	case contentType == "application/pdf":
+		if err := checkPDF(data); err != nil {
+			err.ContentType = contentType
+			return nil, err
+		}
+		return data, nil
+	case !strings.HasPrefix(contentType, "image/"):
+		return data, nil
+	}
+
+	width, height, err := imageSize(contentType, data)
+	if err != nil {
+		return nil, &response.FileValidationError{
+			Code:        response.FileErrorUnreadable,
+			Message:     "image header could not be decoded",
+			ContentType: contentType,
+		}
+	}
+	if err := checkDimensions(rule, width, height); err != nil {
+		err.ContentType = contentType
+		return nil, err
+	}
+
+	return stripMetadata(contentType, data), nil
+}
+
+func checkDimensions(rule UploadRule, width, height int) *response.FileValidationError {
+	if width < rule.MinWidth || height < rule.MinHeight ||
+		(rule.MaxWidth > 0 && width > rule.MaxWidth) ||
+		(rule.MaxHeight > 0 && height > rule.MaxHeight) {
+		return &response.FileValidationError{
+			Code: response.FileErrorDimensions,
+			Message: fmt.Sprintf("image is %dx%d, expected between %dx%d and %dx%d",
+				width, height, rule.MinWidth, rule.MinHeight, rule.MaxWidth, rule.MaxHeight),
+			Width:  width,
+			Height: height,
+		}
+	}
+
+	aspect := float64(width) / float64(height)
+	if (rule.MinAspect > 0 && aspect < rule.MinAspect) || (rule.MaxAspect > 0 && aspect > rule.MaxAspect) {
+		return &response.FileValidationError{
+			Code: response.FileErrorAspectRatio,
+			Message: fmt.Sprintf("image aspect ratio %.2f is outside %.2f-%.2f",
+				aspect, rule.MinAspect, rule.MaxAspect),
+			Width:  width,
+			Height: height,
+		}
+	}
+	return nil
+}
+
+func checkVideo(rule UploadRule, data []byte) *response.FileValidationError {
+	info, err := ProbeVideo(data)
+	if err != nil {
+		return &response.FileValidationError{
+			Code:    response.FileErrorVideo,
+			Message: "video header could not be read: " + err.Error(),
+		}
+	}
+	if info.Width == 0 || info.Height == 0 {
+		return &response.FileValidationError{
+			Code:    response.FileErrorVideo,
+			Message: "video has no picture track",
+		}
+	}
+	if (rule.MaxWidth > 0 && info.Width > rule.MaxWidth) || (rule.MaxHeight > 0 && info.Height > rule.MaxHeight) {
+		return &response.FileValidationError{
+			Code:    response.FileErrorDimensions,
+			Message: fmt.Sprintf("video is %dx%d, expected at most %dx%d", info.Width, info.Height, rule.MaxWidth, rule.MaxHeight),
+			Width:   info.Width,
+			Height:  info.Height,
+		}
+	}
+	if rule.MaxDuration > 0 && info.Duration > rule.MaxDuration {
+		return &response.FileValidationError{
+			Code:    response.FileErrorDuration,
+			Message: fmt.Sprintf("video is %s long, expected at most %s", info.Duration.Round(time.Second), rule.MaxDuration),
+			Width:   info.Width,
+			Height:  info.Height,
+		}
+	}
+	return nil
+}
+
+func imageSize(contentType string, data []byte) (int, int, error) {
+	if contentType == "image/webp" {
+		return webpSize(data)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return 0, 0, fmt.Errorf("empty image")
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+func allowed(types []string, contentType string) bool {
+	for _, t := range types {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// memoryFile serves bytes already in memory through the multipart.File
+// interface.
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (memoryFile) Close() error { return nil }
+
+func NewMemoryFile(data []byte) multipart.File {
+	return memoryFile{bytes.NewReader(data)}
+}
@@ -0,0 +1,280 @@
+package files
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VideoInfo is what the container header says about a video.
+type VideoInfo struct {
+	// Duration is zero when the container does not record it, as with
+	// WebM written by a live recorder.
+	Duration time.Duration
+	Width    int
+	Height   int
+}
+
+// ProbeVideo reads the duration and frame size from an MP4 or WebM header
+// without decoding any frames.
+func ProbeVideo(data []byte) (*VideoInfo, error) {
+	contentType := http.DetectContentType(data)
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	switch contentType {
+	case "video/mp4":
+		return probeMP4(data)
+	case "video/webm":
+		return probeWebM(data)
+	}
+	return nil, fmt.Errorf("unsupported video type %s", contentType)
+}
+
+// mp4Boxes calls fn for every box in data with its type and payload.
+func mp4Boxes(data []byte, fn func(typ string, payload []byte) error) error {
+	for len(data) > 0 {
+		if len(data) < 8 {
+			return fmt.Errorf("truncated box header")
+		}
+		size := uint64(binary.BigEndian.Uint32(data))
+		typ := string(data[4:8])
+		header := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return fmt.Errorf("truncated box header")
+			}
+			size = binary.BigEndian.Uint64(data[8:])
+			header = 16
+		}
+		if size < header || size > uint64(len(data)) {
+			return fmt.Errorf("box %q overruns the file", typ)
+		}
+		if err := fn(typ, data[header:size]); err != nil {
+			return err
+		}
+		data = data[size:]
+	}
+	return nil
+}
+
+func probeMP4(data []byte) (*VideoInfo, error) {
+	var info VideoInfo
+	var haveMovie bool
+
+	err := mp4Boxes(data, func(typ string, moov []byte) error {
+		if typ != "moov" {
+			return nil
+		}
+		haveMovie = true
+		return mp4Boxes(moov, func(typ string, box []byte) error {
+			switch typ {
+			case "mvhd":
+				d, err := mvhdDuration(box)
+				if err != nil {
+					return err
+				}
+				info.Duration = d
+			case "trak":
+				return mp4Boxes(box, func(typ string, tkhd []byte) error {
+					if typ != "tkhd" || len(tkhd) < 8 || info.Width > 0 {
+						return nil
+					}
+					// Width and height are the last two fields, 16.16 fixed point.
+					w := int(binary.BigEndian.Uint32(tkhd[len(tkhd)-8:]) >> 16)
+					h := int(binary.BigEndian.Uint32(tkhd[len(tkhd)-4:]) >> 16)
+					if w > 0 && h > 0 {
+						info.Width, info.Height = w, h
+					}
+					return nil
+				})
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !haveMovie {
+		return nil, fmt.Errorf("mp4 has no moov box")
+	}
+	return &info, nil
+}
+
+func mvhdDuration(box []byte) (time.Duration, error) {
+	if len(box) < 20 {
+		return 0, fmt.Errorf("truncated mvhd box")
+	}
+	var timescale, duration uint64
+	if box[0] == 1 {
+		if len(box) < 32 {
+			return 0, fmt.Errorf("truncated mvhd box")
+		}
+		timescale = uint64(binary.BigEndian.Uint32(box[20:]))
+		duration = binary.BigEndian.Uint64(box[24:])
+	} else {
+		timescale = uint64(binary.BigEndian.Uint32(box[12:]))
+		duration = uint64(binary.BigEndian.Uint32(box[16:]))
+	}
+	if timescale == 0 {
+		return 0, fmt.Errorf("mvhd has no timescale")
+	}
+	return time.Duration(float64(duration) / float64(timescale) * float64(time.Second)), nil
+}
+
+// Matroska element IDs used by probeWebM.
+const (
+	ebmlSegment    = 0x18538067
+	ebmlInfo       = 0x1549A966
+	ebmlTimescale  = 0x2AD7B1
+	ebmlDuration   = 0x4489
+	ebmlTracks     = 0x1654AE6B
+	ebmlTrackEntry = 0xAE
+	ebmlVideo      = 0xE0
+	ebmlWidth      = 0xB0
+	ebmlHeight     = 0xBA
+	ebmlCluster    = 0x1F43B675
+)
+
+// ebmlVint reads a variable length integer. With keepMarker the length bit
+// stays in the value, which is how element IDs are written.
+func ebmlVint(data []byte, keepMarker bool) (uint64, int, bool) {
+	if len(data) == 0 || data[0] == 0 {
+		return 0, 0, false
+	}
+	n := 1
+	for mask := byte(0x80); data[0]&mask == 0; mask >>= 1 {
+		n++
+	}
+	if n > 8 || len(data) < n {
+		return 0, 0, false
+	}
+	v := uint64(data[0])
+	if !keepMarker {
+		v &= uint64(0xFF >> n)
+	}
+	allOnes := v == uint64(0xFF>>n)
+	for _, b := range data[1:n] {
+		v = v<<8 | uint64(b)
+		allOnes = allOnes && b == 0xFF
+	}
+	if !keepMarker && allOnes {
+		// Unknown size: the element runs to the end of its parent.
+		return math.MaxUint64, n, true
+	}
+	return v, n, true
+}
+
+// ebmlElements calls fn for every element in data. It stops at the first
+// cluster, since the headers we need always come before the media.
+func ebmlElements(data []byte, fn func(id uint64, payload []byte) error) error {
+	for len(data) > 0 {
+		id, idLen, ok := ebmlVint(data, true)
+		if !ok {
+			return fmt.Errorf("invalid element id")
+		}
+		size, sizeLen, ok := ebmlVint(data[idLen:], false)
+		if !ok {
+			return fmt.Errorf("invalid element size")
+		}
+		if id == ebmlCluster {
+			return nil
+		}
+		start := idLen + sizeLen
+		end := uint64(len(data))
+		if size != math.MaxUint64 {
+			if size > end-uint64(start) {
+				return fmt.Errorf("element %x overruns the file", id)
+			}
+			end = uint64(start) + size
+		}
+		if err := fn(id, data[start:end]); err != nil {
+			return err
+		}
+		data = data[end:]
+	}
+	return nil
+}
+
+func ebmlUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+func ebmlFloat(b []byte) float64 {
+	switch len(b) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func probeWebM(data []byte) (*VideoInfo, error) {
+	var info VideoInfo
+	var haveSegment bool
+	timescale := uint64(1000000)
+	var duration float64
+
+	err := ebmlElements(data, func(id uint64, segment []byte) error {
+		if id != ebmlSegment {
+			return nil
+		}
+		haveSegment = true
+		return ebmlElements(segment, func(id uint64, el []byte) error {
+			switch id {
+			case ebmlInfo:
+				return ebmlElements(el, func(id uint64, v []byte) error {
+					switch id {
+					case ebmlTimescale:
+						if ts := ebmlUint(v); ts > 0 {
+							timescale = ts
+						}
+					case ebmlDuration:
+						duration = ebmlFloat(v)
+					}
+					return nil
+				})
+			case ebmlTracks:
+				return ebmlElements(el, func(id uint64, track []byte) error {
+					if id != ebmlTrackEntry || info.Width > 0 {
+						return nil
+					}
+					return ebmlElements(track, func(id uint64, video []byte) error {
+						if id != ebmlVideo {
+							return nil
+						}
+						return ebmlElements(video, func(id uint64, v []byte) error {
+							switch id {
+							case ebmlWidth:
+								info.Width = int(ebmlUint(v))
+							case ebmlHeight:
+								info.Height = int(ebmlUint(v))
+							}
+							return nil
+						})
+					})
+				})
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !haveSegment {
+		return nil, fmt.Errorf("webm has no segment")
+	}
+	info.Duration = time.Duration(duration * float64(timescale))
+	return &info, nil
+}
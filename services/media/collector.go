@@ -0,0 +1,66 @@
+package media
+
+import (
+	"context"
+	"time"
+
+	"github.com/wafi04/backend/pkg/logger"
+	"github.com/wafi04/backend/services/files"
+)
+
+// collectBatch caps how many assets one collection run deletes.
+const collectBatch = 500
+
+// Collector deletes stored files that nothing has referenced for longer
+// than the grace period.
+type Collector struct {
+	repo    MediaRepository
+	storage files.FileStorage
+	grace   time.Duration
+	log     *logger.Logger
+}
+
+func NewCollector(repo MediaRepository, storage files.FileStorage, grace time.Duration) *Collector {
+	return &Collector{repo: repo, storage: storage, grace: grace, log: logger.NewLogger()}
+}
+
+// Collect refreshes the reference counts and removes expired assets. The
+// row is deleted before the file so an asset that gains an owner in the
+// meantime is never left pointing at a missing file; a failed file delete
+// only leaves an orphan behind.
+func (c *Collector) Collect(ctx context.Context) error {
+	unreferenced, err := c.repo.SyncReferences(ctx)
+	if err != nil {
+		return err
+	}
+	if unreferenced == 0 {
+		return nil
+	}
+
+	before := time.Now().Add(-c.grace)
+	assets, err := c.repo.ListUnreferenced(ctx, before, collectBatch)
+	if err != nil {
+		return err
+	}
+
+	var deleted int
+	for _, asset := range assets {
+		ok, err := c.repo.DeleteAsset(ctx, asset.ID, before)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err := c.storage.DeleteFile(ctx, asset.PublicID, asset.ContentType); err != nil {
+			c.log.Log(logger.ErrorLevel, "Failed to delete stored file %s: %v", asset.PublicID, err)
+			continue
+		}
+		deleted++
+	}
+
+	if deleted > 0 {
+		c.log.Log(logger.InfoLevel, "Deleted %d unreferenced media files", deleted)
+	}
+	return nil
+}
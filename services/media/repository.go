@@ -0,0 +1,212 @@
+package media
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/wafi04/backend/pkg/logger"
+	"github.com/wafi04/backend/pkg/types"
+)
+
+type Database struct {
+	DB  *sqlx.DB
+	log logger.Logger
+}
+
+type MediaRepository interface {
+	FindByHash(ctx context.Context, hash string) (*types.MediaAsset, error)
+	CreateAsset(ctx context.Context, asset *types.MediaAsset) (*types.MediaAsset, error)
+	SyncReferences(ctx context.Context) (int64, error)
+	ListUnreferenced(ctx context.Context, before time.Time, limit int) ([]*types.MediaAsset, error)
+	DeleteAsset(ctx context.Context, id string, before time.Time) (bool, error)
+}
+
+func NewMediaRepository(DB *sqlx.DB) MediaRepository {
+	return &Database{DB: DB}
+}
+
+const assetColumns = `id, hash, public_id, url, content_type, size, ref_count, unreferenced_at, created_at`
+
+func scanAsset(row interface{ Scan(...interface{}) error }) (*types.MediaAsset, error) {
+	var asset types.MediaAsset
+	var unreferencedAt sql.NullTime
+	err := row.Scan(
+		&asset.ID,
+		&asset.Hash,
+		&asset.PublicID,
+		&asset.URL,
+		&asset.ContentType,
+		&asset.Size,
+		&asset.RefCount,
+		&unreferencedAt,
+		&asset.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if unreferencedAt.Valid {
+		asset.UnreferencedAt = &unreferencedAt.Time
+	}
+	return &asset, nil
+}
+
+// FindByHash returns the asset with the given content hash, or nil when the
+// content has not been stored yet. An unreferenced asset that is handed out
+// again has its grace period restarted so the collector does not delete it
+// before the new owner is recorded.
+func (r *Database) FindByHash(ctx context.Context, hash string) (*types.MediaAsset, error) {
+	asset, err := scanAsset(r.DB.QueryRowContext(ctx, `
+        UPDATE media_assets
+        SET unreferenced_at = CASE WHEN ref_count = 0 THEN NOW() ELSE unreferenced_at END
+        WHERE hash = $1
+        RETURNING `+assetColumns,
+		hash,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find media asset: %v", err)
+	}
+	return asset, nil
+}
+
+// CreateAsset records a newly stored file. When the same content was
+// registered concurrently the existing asset is returned instead, and the
+// caller should delete its own copy if the public ids differ.
+func (r *Database) CreateAsset(ctx context.Context, asset *types.MediaAsset) (*types.MediaAsset, error) {
+	created, err := scanAsset(r.DB.QueryRowContext(ctx, `
+        INSERT INTO media_assets (id, hash, public_id, url, content_type, size, ref_count, unreferenced_at)
+        VALUES ($1, $2, $3, $4, $5, $6, 0, NOW())
+        ON CONFLICT (hash) DO UPDATE SET hash = EXCLUDED.hash
+        RETURNING `+assetColumns,
+		uuid.New().String(), asset.Hash, asset.PublicID, asset.URL, asset.ContentType, asset.Size,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create media asset: %v", err)
+	}
+	return created, nil
+}
+
+// referenceSources lists every column that stores an uploaded file's URL,
+// with the entity that owns it. Trashed rows still count until they are
+// purged, so restoring them never finds the file gone.
+const referenceSources = `
+    SELECT 'product_image' AS owner_type, id AS owner_id, url FROM product_images
+    UNION ALL
+    SELECT 'product_image', image_id, url FROM product_image_derivatives
+    UNION ALL
+    SELECT 'product_video', id, url FROM product_videos
+    UNION ALL
+    SELECT 'product_video', id, poster_url FROM product_videos WHERE poster_url <> ''
+    UNION ALL
+    SELECT 'product_document', id, url FROM product_documents
+    UNION ALL
+    SELECT 'category', id, image FROM categories WHERE image IS NOT NULL
+    UNION ALL
+    SELECT 'brand', id, image FROM brands WHERE image IS NOT NULL
+    UNION ALL
+    SELECT 'collection', id, image FROM collections WHERE image IS NOT NULL
+    UNION ALL
+    SELECT 'review', id, UNNEST(photos) FROM product_reviews`
+
+// SyncReferences rebuilds the owners of every asset from the tables that
+// reference uploaded files and updates the reference counts. It returns the
+// number of assets left without owners.
+func (r *Database) SyncReferences(ctx context.Context) (int64, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM media_asset_owners"); err != nil {
+		return 0, fmt.Errorf("failed to clear media owners: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO media_asset_owners (asset_id, owner_type, owner_id)
+        SELECT DISTINCT a.id, s.owner_type, s.owner_id
+        FROM (`+referenceSources+`) s
+        JOIN media_assets a ON a.url = s.url`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record media owners: %v", err)
+	}
+
+	var unreferenced int64
+	err = tx.QueryRowContext(ctx, `
+        WITH counts AS (
+            UPDATE media_assets a
+            SET ref_count = c.owners,
+                unreferenced_at = CASE
+                    WHEN c.owners > 0 THEN NULL
+                    ELSE COALESCE(a.unreferenced_at, NOW())
+                END
+            FROM (
+                SELECT a.id, COUNT(o.asset_id) AS owners
+                FROM media_assets a
+                LEFT JOIN media_asset_owners o ON o.asset_id = a.id
+                GROUP BY a.id
+            ) c
+            WHERE a.id = c.id
+            RETURNING a.ref_count
+        )
+        SELECT COUNT(*) FROM counts WHERE ref_count = 0`).Scan(&unreferenced)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update reference counts: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return unreferenced, nil
+}
+
+// ListUnreferenced returns assets that have had no owners since before the
+// given time.
+func (r *Database) ListUnreferenced(ctx context.Context, before time.Time, limit int) ([]*types.MediaAsset, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+        SELECT `+assetColumns+`
+        FROM media_assets
+        WHERE ref_count = 0 AND unreferenced_at < $1
+        ORDER BY unreferenced_at
+        LIMIT $2`,
+		before, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unreferenced media: %v", err)
+	}
+	defer rows.Close()
+
+	var assets []*types.MediaAsset
+	for rows.Next() {
+		asset, err := scanAsset(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan media asset: %v", err)
+		}
+		assets = append(assets, asset)
+	}
+	return assets, rows.Err()
+}
+
+// DeleteAsset removes the asset row if it is still unreferenced since
+// before the given time, and reports whether it did.
+func (r *Database) DeleteAsset(ctx context.Context, id string, before time.Time) (bool, error) {
+	result, err := r.DB.ExecContext(ctx, `
+        DELETE FROM media_assets
+        WHERE id = $1 AND ref_count = 0 AND unreferenced_at < $2`,
+		id, before,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete media asset: %v", err)
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,79 @@
+package media
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/wafi04/backend/pkg/logger"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+	"github.com/wafi04/backend/services/files"
+)
+
+// DedupStorage registers every upload as a media asset and serves repeated
+// content from the asset that already holds it instead of storing it again.
+type DedupStorage struct {
+	next files.FileStorage
+	repo MediaRepository
+	log  *logger.Logger
+}
+
+func NewDedupStorage(next files.FileStorage, repo MediaRepository) *DedupStorage {
+	return &DedupStorage{next: next, repo: repo, log: logger.NewLogger()}
+}
+
+func (s *DedupStorage) UploadFile(
+	ctx context.Context,
+	req *request.FileUploadRequest,
+) (*response.FileUploadResponse, error) {
+	data, err := io.ReadAll(req.FileData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	existing, err := s.repo.FindByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return &response.FileUploadResponse{URL: existing.URL, PublicID: existing.PublicID}, nil
+	}
+
+	upload := *req
+	upload.FileData = files.NewMemoryFile(data)
+	res, err := s.next.UploadFile(ctx, &upload)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := http.DetectContentType(data)
+	asset, err := s.repo.CreateAsset(ctx, &types.MediaAsset{
+		Hash:        hash,
+		PublicID:    res.PublicID,
+		URL:         res.URL,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Someone stored the same content while we were uploading; keep theirs.
+	if asset.PublicID != res.PublicID {
+		if err := s.next.DeleteFile(ctx, res.PublicID, contentType); err != nil {
+			s.log.Log(logger.ErrorLevel, "Failed to delete duplicate upload %s: %v", res.PublicID, err)
+		}
+	}
+	return &response.FileUploadResponse{URL: asset.URL, PublicID: asset.PublicID}, nil
+}
+
+func (s *DedupStorage) DeleteFile(ctx context.Context, publicID, contentType string) error {
+	return s.next.DeleteFile(ctx, publicID, contentType)
+}
@@ -0,0 +1,32 @@
+package producthandler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	httpresponse "github.com/wafi04/backend/pkg/response"
+	request "github.com/wafi04/backend/pkg/types/req"
+)
+
+// HandleSetBundleItems replaces the component lines of a bundle product.
+func (h *ProductHandler) HandleSetBundleItems(c *gin.Context) {
+	var req request.SetBundleItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+	req.BundleID = c.Param("id")
+
+	res, err := h.productService.SetBundleItems(c, &req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if strings.Contains(err.Error(), "product not found") {
+			status = http.StatusNotFound
+		}
+		httpresponse.SendErrorResponseWithDetails(c, status, "Failed to set bundle items", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Bundle Items Updated", res)
+}
@@ -0,0 +1,96 @@
+package producthandler
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/backend/pkg/logger"
+	httpresponse "github.com/wafi04/backend/pkg/response"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+)
+
+// HandleImportCatalog accepts a CSV or JSON file in the "file" form field.
+// The format comes from the "format" field or the file extension. Dry runs
+// return the full report; real imports return a job to poll.
+func (h *ProductHandler) HandleImportCatalog(c *gin.Context) {
+	maxSize := int64(50 << 20)
+	if err := c.Request.ParseMultipartForm(maxSize); err != nil {
+		h.log.Log(logger.ErrorLevel, "Error parsing multipart form: %v", err)
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to parse form data", err.Error())
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "File is required")
+		return
+	}
+	defer file.Close()
+
+	format := strings.ToLower(c.Request.FormValue("format"))
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), ".")
+	}
+
+	job, err := h.productService.ImportCatalog(c, &request.ImportCatalogRequest{
+		Format: format,
+		DryRun: c.Request.FormValue("dry_run") == "true",
+	}, file)
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to import catalog", err.Error())
+		return
+	}
+
+	if job.DryRun {
+		httpresponse.SendSuccessResponse(c, http.StatusOK, "Dry Run Completed", job)
+		return
+	}
+	httpresponse.SendSuccessResponse(c, http.StatusAccepted, "Import Started", job)
+}
+
+func (h *ProductHandler) HandleGetImportJob(c *gin.Context) {
+	id := c.Param("id")
+
+	if id == "" {
+		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Import ID is required")
+		return
+	}
+
+	job, err := h.productService.GetImportJob(c, id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "not found") {
+			status = http.StatusNotFound
+		}
+		httpresponse.SendErrorResponseWithDetails(c, status, "Failed to get import job", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Get Import Job Success", job)
+}
+
+func (h *ProductHandler) HandleExportCatalog(c *gin.Context) {
+	format := c.DefaultQuery("format", types.CatalogFormatCSV)
+	if format != types.CatalogFormatCSV && format != types.CatalogFormatJSON {
+		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Format must be csv or json")
+		return
+	}
+
+	contentType := "text/csv"
+	if format == types.CatalogFormatJSON {
+		contentType = "application/json"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=catalog.%s", format))
+
+	if err := h.productService.ExportCatalog(c, format, c.Writer); err != nil {
+		h.log.Log(logger.ErrorLevel, "Failed to export catalog: %v", err)
+		if !c.Writer.Written() {
+			httpresponse.SendErrorResponseWithDetails(c, http.StatusInternalServerError, "Failed to export catalog", err.Error())
+		}
+	}
+}
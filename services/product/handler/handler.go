@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/wafi04/backend/pkg/logger"
+	"github.com/wafi04/backend/pkg/middleware"
 	httpresponse "github.com/wafi04/backend/pkg/response"
 	"github.com/wafi04/backend/pkg/types"
 	request "github.com/wafi04/backend/pkg/types/req"
@@ -16,13 +17,15 @@ import (
 type ProductHandler struct {
 	productService *productservice.ProductService
 	log            logger.Logger
-	filesclient    *files.Cloudinary
+	filesclient    files.FileStorage
+	derivatives    *files.DerivativePool
 }
 
-func NewProductHandler(service *productservice.ProductService, files *files.Cloudinary) *ProductHandler {
+func NewProductHandler(service *productservice.ProductService, files files.FileStorage, derivatives *files.DerivativePool) *ProductHandler {
 	return &ProductHandler{
 		productService: service,
 		filesclient:    files,
+		derivatives:    derivatives,
 	}
 }
 
@@ -57,7 +60,8 @@ func (h *ProductHandler) HandleGetProduct(c *gin.Context) {
 	}
 
 	res, err := h.productService.GetProduct(c, &request.GetProductRequest{
-		ID: id,
+		ID:            id,
+		PublishedOnly: !isAdmin(c),
 	})
 	if err != nil {
 		log.Printf("Failed to get Product: %v", err)
@@ -68,6 +72,29 @@ func (h *ProductHandler) HandleGetProduct(c *gin.Context) {
 	httpresponse.SendSuccessResponse(c, http.StatusOK, "Get product success", res)
 }
 
+// HandleGetProductBySlug resolves a storefront slug. Old slugs of renamed
+// products still resolve, with redirected set so the client can move to
+// canonical_slug.
+func (h *ProductHandler) HandleGetProductBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+
+	if slug == "" {
+		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Slug is required")
+		return
+	}
+
+	res, err := h.productService.GetProductBySlug(c, &request.GetProductBySlugRequest{
+		Slug:          slug,
+		PublishedOnly: !isAdmin(c),
+	})
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusNotFound, "Failed to get product", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Get product success", res)
+}
+
 func (h *ProductHandler) HandleListProducts(c *gin.Context) {
 	log.Printf("Received get product request: %s %s", c.Request.Method, c.Request.URL.Path)
 
@@ -75,8 +102,14 @@ func (h *ProductHandler) HandleListProducts(c *gin.Context) {
 	// limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
 
 	req := &request.ListProductsRequest{
-		PageSize:  10,
-		PageToken: "0",
+		PageSize:     10,
+		PageToken:    "0",
+		Status:       types.ProductStatusPublished,
+		SortBy:       c.Query("sort"),
+		BrandID:      c.Query("brand"),
+		CollectionID: c.Query("collection"),
+		Tags:         splitTags(c),
+		Query:        c.Query("q"),
 	}
 
 	res, err := h.productService.ListProducts(c, req)
@@ -90,6 +123,99 @@ func (h *ProductHandler) HandleListProducts(c *gin.Context) {
 	httpresponse.SendSuccessResponse(c, http.StatusOK, "Get product successfuly ", res)
 }
 
+// HandleListAllProducts is the admin listing: it returns products in every
+// status, optionally filtered with ?status=.
+func (h *ProductHandler) HandleListAllProducts(c *gin.Context) {
+	req := &request.ListProductsRequest{
+		PageSize:     10,
+		PageToken:    c.DefaultQuery("page", "0"),
+		Status:       c.Query("status"),
+		SortBy:       c.Query("sort"),
+		BrandID:      c.Query("brand"),
+		CollectionID: c.Query("collection"),
+		Tags:         splitTags(c),
+		Query:        c.Query("q"),
+	}
+
+	res, err := h.productService.ListProducts(c, req)
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusInternalServerError, "Failed to get products", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Get product successfuly ", res)
+}
+
+func (h *ProductHandler) HandleUpdateProductStatus(c *gin.Context) {
+	id := c.Param("id")
+
+	if id == "" {
+		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Product ID is required")
+		return
+	}
+
+	var req struct {
+		Status      string `json:"status"`
+		PublishAt   *int64 `json:"publish_at"`
+		UnpublishAt *int64 `json:"unpublish_at"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "error : %s", err.Error())
+		return
+	}
+
+	product, err := h.productService.UpdateProductStatus(c, &request.UpdateProductStatusRequest{
+		ID:          id,
+		Status:      req.Status,
+		PublishAt:   req.PublishAt,
+		UnpublishAt: req.UnpublishAt,
+	})
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to update product status", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Updated Product Status Success", product)
+}
+
+func (h *ProductHandler) HandleListTrash(c *gin.Context) {
+	res, err := h.productService.ListTrash(c, &request.ListTrashRequest{
+		Type: c.DefaultQuery("type", types.TrashTypeProduct),
+	})
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to get trash", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Get Trash Success", res)
+}
+
+func (h *ProductHandler) HandleRestoreFromTrash(c *gin.Context) {
+	id := c.Param("id")
+
+	if id == "" {
+		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "ID is required")
+		return
+	}
+
+	res, err := h.productService.RestoreFromTrash(c, &request.RestoreTrashRequest{
+		ID:   id,
+		Type: c.DefaultQuery("type", types.TrashTypeProduct),
+	})
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to restore", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Restore Success", res)
+}
+
+func isAdmin(c *gin.Context) bool {
+	user, err := middleware.GetUserFromGinContext(c)
+	return err == nil && user.Role == "admin"
+}
+
 func (h *ProductHandler) HandleUpdateProduct(c *gin.Context) {
 	id := c.Param("id")
 
@@ -107,6 +233,8 @@ func (h *ProductHandler) HandleUpdateProduct(c *gin.Context) {
 		CategoryID  string  `json:"category_id"`
 		Price       float64 `json:"price"`
 		Sku         string  `json:"sku"`
+		// BrandID is left unchanged when omitted; "" removes the brand.
+		BrandID *string `json:"brand_id"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -114,6 +242,11 @@ func (h *ProductHandler) HandleUpdateProduct(c *gin.Context) {
 		return
 	}
 
+	var actorID string
+	if user, err := middleware.GetUserFromGinContext(c); err == nil {
+		actorID = user.UserID
+	}
+
 	update, err := h.productService.UpdateProduct(c, &request.UpdateProductRequest{
 		Product: &types.Product{
 			ID:          id,
@@ -122,7 +255,9 @@ func (h *ProductHandler) HandleUpdateProduct(c *gin.Context) {
 			SubTitle:    req.SubTitle,
 			Price:       req.Price,
 			CategoryID:  req.CategoryID,
+			BrandID:     req.BrandID,
 		},
+		ActorID: actorID,
 	})
 
 	if err != nil {
@@ -1,16 +1,32 @@
 package producthandler
 
 import (
-	"fmt"
-	"math/rand"
+	"mime/multipart"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/wafi04/backend/pkg/logger"
 	httpresponse "github.com/wafi04/backend/pkg/response"
+	"github.com/wafi04/backend/pkg/types"
 	request "github.com/wafi04/backend/pkg/types/req"
+	"github.com/wafi04/backend/pkg/utils"
+	"github.com/wafi04/backend/services/files"
 )
 
+func imageErrorStatus(err error) int {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		return http.StatusNotFound
+	case strings.Contains(err.Error(), "invalid"):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// HandleAddProductImage uploads one image for the variant in :id. Optional
+// form fields: alt_text, is_main and position.
 func (h *ProductHandler) HandleAddProductImage(c *gin.Context) {
 	maxSize := int64(10 << 20)
 	if err := c.Request.ParseMultipartForm(maxSize); err != nil {
@@ -21,46 +37,206 @@ func (h *ProductHandler) HandleAddProductImage(c *gin.Context) {
 	id := c.Param("id")
 
 	if id == "" {
-		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Category ID is required")
+		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Variant ID is required")
 		return
 	}
 
-	file, _, err := c.Request.FormFile("image")
-	var imageUrl string
-	if err == nil {
-		defer file.Close()
+	_, header, err := c.Request.FormFile("image")
+	if err != nil {
+		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Image is required")
+		return
+	}
 
-		PublicID := fmt.Sprintf("%06d", rand.Intn(1000000))
-		uploadRequest := &request.FileUploadRequest{
-			FileData: file,
-			Folder:   "products",
-			PublicID: PublicID,
+	addReq := &request.AddProductImageRequest{
+		VariantID: id,
+		AltText:   strings.TrimSpace(c.PostForm("alt_text")),
+	}
+	if raw := c.PostForm("is_main"); raw != "" {
+		addReq.IsMain, err = strconv.ParseBool(raw)
+		if err != nil {
+			httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Invalid is_main")
+			return
 		}
+	}
+	if raw := c.PostForm("position"); raw != "" {
+		position, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil {
+			httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Invalid position")
+			return
+		}
+		p := int32(position)
+		addReq.Position = &p
+	}
 
-		uploadResponse, err := h.filesclient.UploadFile(c, uploadRequest)
+	job, err := h.uploadProductImage(c, header, addReq)
+	if err != nil {
+		httpresponse.SendUploadError(c, err, http.StatusBadRequest, "Failed to upload image")
+		return
+	}
+
+	productImage, err := h.productService.AddProductImage(c, addReq)
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, imageErrorStatus(err), "Failed to created product image", err.Error())
+		return
+	}
+	h.enqueueDerivatives(job, productImage.ID)
+	httpresponse.SendSuccessResponse(c, http.StatusCreated, "Created Product Image succesfully", productImage)
+}
+
+// HandleBulkAddProductImages uploads every "images" file of the form for the
+// variant in :id and appends them in the order sent. The nth alt_text value
+// belongs to the nth file.
+func (h *ProductHandler) HandleBulkAddProductImages(c *gin.Context) {
+	maxSize := int64(32 << 20)
+	if err := c.Request.ParseMultipartForm(maxSize); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to parse form data", err.Error())
+		return
+	}
+
+	headers := c.Request.MultipartForm.File["images"]
+	if len(headers) == 0 {
+		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "At least one image is required")
+		return
+	}
+	altTexts := c.PostFormArray("alt_text")
+
+	variantID := c.Param("id")
+	reqs := make([]*request.AddProductImageRequest, len(headers))
+	jobs := make([]*files.DerivativeJob, len(headers))
+	for i, header := range headers {
+		reqs[i] = &request.AddProductImageRequest{VariantID: variantID}
+		if i < len(altTexts) {
+			reqs[i].AltText = strings.TrimSpace(altTexts[i])
+		}
+
+		job, err := h.uploadProductImage(c, header, reqs[i])
 		if err != nil {
-			httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Failed to upload image")
+			httpresponse.SendUploadError(c, err, http.StatusBadRequest, "Failed to upload "+header.Filename)
 			return
 		}
+		jobs[i] = job
+	}
 
-		imageUrl = uploadResponse.URL
+	images, err := h.productService.AddProductImages(c, variantID, reqs)
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, imageErrorStatus(err), "Failed to create product images", err.Error())
+		return
+	}
+	for i, image := range images {
+		h.enqueueDerivatives(jobs[i], image.ID)
 	}
 
-	var imageUrlPtr *string
-	if imageUrl != "" {
-		imageUrlPtr = &imageUrl
+	httpresponse.SendSuccessResponse(c, http.StatusCreated, "Created Product Images succesfully", images)
+}
+
+// uploadProductImage stores one multipart file, sets req.URL and returns the
+// derivative job to queue once the image row exists.
+func (h *ProductHandler) uploadProductImage(c *gin.Context, header *multipart.FileHeader, req *request.AddProductImageRequest) (*files.DerivativeJob, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
 	}
+	defer file.Close()
 
-	productImage, err := h.productService.AddProductImage(c, &request.AddProductImageRequest{
-		VariantID: id,
-		URL:       *imageUrlPtr,
-		IsMain:    true,
+	// Derivatives are built from the checked, metadata-stripped bytes that
+	// are stored, not from the raw upload.
+	data, err := files.ValidateUpload(file, files.DefaultUploadRules[request.IMAGE])
+	if err != nil {
+		return nil, err
+	}
+
+	publicID := utils.GenerateRandomId("IMG")
+	uploadResponse, err := h.filesclient.UploadFile(c, &request.FileUploadRequest{
+		FileData: files.NewMemoryFile(data),
+		Folder:   "products",
+		PublicID: publicID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req.URL = uploadResponse.URL
+	return h.derivativeJob(data, "products", publicID), nil
+}
+
+// HandleUpdateProductImage changes the alt text of image :imageId, and makes
+// it the main image when is_main is true.
+func (h *ProductHandler) HandleUpdateProductImage(c *gin.Context) {
+	var body struct {
+		AltText string `json:"alt_text"`
+		IsMain  bool   `json:"is_main"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	image, err := h.productService.UpdateProductImage(c, &request.UpdateProductImageRequest{
+		Image: &types.ProductImage{
+			ID:      c.Param("imageId"),
+			AltText: strings.TrimSpace(body.AltText),
+			IsMain:  body.IsMain,
+		},
 	})
 	if err != nil {
-		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Failed to created product image")
+		httpresponse.SendErrorResponseWithDetails(c, imageErrorStatus(err), "Failed to update product image", err.Error())
 		return
 	}
-	httpresponse.SendSuccessResponse(c, http.StatusCreated, "Created Product Image succesfully", productImage)
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Update Product Image succesfully", image)
+}
+
+// HandleSetMainProductImage makes image :imageId the main image of variant
+// :id.
+func (h *ProductHandler) HandleSetMainProductImage(c *gin.Context) {
+	image, err := h.productService.SetMainProductImage(c, &request.SetMainProductImageRequest{
+		VariantID: c.Param("id"),
+		ImageID:   c.Param("imageId"),
+	})
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, imageErrorStatus(err), "Failed to set main image", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Main Image Updated", image)
+}
+
+// HandleReorderProductImages sets the order of the images of variant :id;
+// the body lists every image id in the new order.
+func (h *ProductHandler) HandleReorderProductImages(c *gin.Context) {
+	var req request.ReorderProductImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+	req.VariantID = c.Param("id")
+
+	if err := h.productService.ReorderProductImages(c, &req); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, imageErrorStatus(err), "Invalid Order", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Reorder Product Images Succesfully", nil)
+}
+
+// derivativeJob wraps the stored bytes of an upload for the derivative
+// workers, or returns nil when derivatives are not generated for this
+// storage.
+func (h *ProductHandler) derivativeJob(data []byte, folder, publicID string) *files.DerivativeJob {
+	if h.derivatives == nil {
+		return nil
+	}
+	return &files.DerivativeJob{Folder: folder, Key: publicID, Data: data}
+}
+
+func (h *ProductHandler) enqueueDerivatives(job *files.DerivativeJob, imageID string) {
+	if job == nil {
+		return
+	}
+	job.ImageID = imageID
+	if err := h.derivatives.Enqueue(*job); err != nil {
+		h.log.Log(logger.ErrorLevel, "Failed to queue derivatives for image %s: %v", imageID, err)
+	}
 }
 
 func (p *ProductHandler) HandleDeleteProductImage(c *gin.Context) {
@@ -0,0 +1,205 @@
+package producthandler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/backend/pkg/logger"
+	httpresponse "github.com/wafi04/backend/pkg/response"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+	"github.com/wafi04/backend/pkg/utils"
+	"github.com/wafi04/backend/services/files"
+)
+
+// HandleAddProductVideo uploads the "video" file of the form for product
+// :id. Duration and frame size are read from the file. The poster is the
+// optional "poster" image upload; without one a frame is taken from the
+// video when ffmpeg is installed, and the video has no poster otherwise.
+// Optional form fields: title and variant_id.
+func (h *ProductHandler) HandleAddProductVideo(c *gin.Context) {
+	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to parse form data", err.Error())
+		return
+	}
+
+	_, header, err := c.Request.FormFile("video")
+	if err != nil {
+		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Video is required")
+		return
+	}
+
+	req := &request.AddProductVideoRequest{
+		ProductID: c.Param("id"),
+		VariantID: strings.TrimSpace(c.PostForm("variant_id")),
+		Title:     c.PostForm("title"),
+	}
+
+	upload, data, err := h.uploadMedia(c, header, request.VIDEO, "videos")
+	if err != nil {
+		httpresponse.SendUploadError(c, err, http.StatusBadRequest, "Failed to upload video")
+		return
+	}
+	req.URL = upload.URL
+
+	info, err := files.ProbeVideo(data)
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to read video", err.Error())
+		return
+	}
+	req.Duration = info.Duration.Seconds()
+	req.Width = info.Width
+	req.Height = info.Height
+
+	if _, poster, err := c.Request.FormFile("poster"); err == nil {
+		upload, _, err := h.uploadMedia(c, poster, request.IMAGE, "videos")
+		if err != nil {
+			httpresponse.SendUploadError(c, err, http.StatusBadRequest, "Failed to upload poster")
+			return
+		}
+		req.PosterURL = upload.URL
+	} else {
+		req.PosterURL = h.extractPoster(c, data, info.Duration)
+	}
+
+	video, err := h.productService.AddProductVideo(c, req)
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, imageErrorStatus(err), "Failed to add product video", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusCreated, "Product Video Added", video)
+}
+
+func (h *ProductHandler) HandleDeleteProductVideo(c *gin.Context) {
+	err := h.productService.DeleteProductVideo(c, &request.DeleteProductMediaRequest{
+		ProductID: c.Param("id"),
+		ID:        c.Param("videoId"),
+	})
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, imageErrorStatus(err), "Failed to delete product video", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Product Video Deleted", nil)
+}
+
+// HandleAddProductDocument uploads the "document" PDF of the form for
+// product :id. Form fields: title, kind (size_guide, care_instructions or
+// other) and the optional variant_id.
+func (h *ProductHandler) HandleAddProductDocument(c *gin.Context) {
+	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to parse form data", err.Error())
+		return
+	}
+
+	_, header, err := c.Request.FormFile("document")
+	if err != nil {
+		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Document is required")
+		return
+	}
+
+	req := &request.AddProductDocumentRequest{
+		ProductID: c.Param("id"),
+		VariantID: strings.TrimSpace(c.PostForm("variant_id")),
+		Kind:      strings.TrimSpace(c.PostForm("kind")),
+		Title:     c.PostForm("title"),
+	}
+
+	upload, data, err := h.uploadMedia(c, header, request.DOCUMENT, "documents")
+	if err != nil {
+		httpresponse.SendUploadError(c, err, http.StatusBadRequest, "Failed to upload document")
+		return
+	}
+	req.URL = upload.URL
+	req.Size = int64(len(data))
+
+	doc, err := h.productService.AddProductDocument(c, req)
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, imageErrorStatus(err), "Failed to add product document", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusCreated, "Product Document Added", doc)
+}
+
+func (h *ProductHandler) HandleDeleteProductDocument(c *gin.Context) {
+	err := h.productService.DeleteProductDocument(c, &request.DeleteProductMediaRequest{
+		ProductID: c.Param("id"),
+		ID:        c.Param("documentId"),
+	})
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, imageErrorStatus(err), "Failed to delete product document", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Product Document Deleted", nil)
+}
+
+// extractPoster stores a frame of the video as its poster and returns its
+// URL. A poster is optional, so failures are logged and yield "".
+func (h *ProductHandler) extractPoster(c *gin.Context, video []byte, duration time.Duration) string {
+	frame, err := files.ExtractPosterFrame(c, video, files.PosterOffset(duration))
+	if errors.Is(err, files.ErrNoFrameExtractor) {
+		return ""
+	}
+	if err != nil {
+		h.log.Log(logger.ErrorLevel, "Failed to extract poster frame: %v", err)
+		return ""
+	}
+
+	upload, err := h.filesclient.UploadFile(c, &request.FileUploadRequest{
+		FileData: files.NewMemoryFile(frame),
+		FileName: "poster.jpg",
+		Folder:   "videos",
+		PublicID: utils.GenerateRandomId("IMG"),
+		FileType: request.IMAGE,
+	})
+	if err != nil {
+		h.log.Log(logger.ErrorLevel, "Failed to upload poster frame: %v", err)
+		return ""
+	}
+	return upload.URL
+}
+
+// mediaIDPrefixes name uploaded media by kind.
+var mediaIDPrefixes = map[request.FileType]string{
+	request.IMAGE:    "IMG",
+	request.VIDEO:    "VID",
+	request.DOCUMENT: "DOC",
+}
+
+// uploadMedia stores one multipart file as fileType and returns the upload
+// with the file contents, for reading metadata afterwards.
+func (h *ProductHandler) uploadMedia(c *gin.Context, header *multipart.FileHeader, fileType request.FileType, folder string) (*response.FileUploadResponse, []byte, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	upload, err := h.filesclient.UploadFile(c, &request.FileUploadRequest{
+		FileData: file,
+		Folder:   folder,
+		PublicID: utils.GenerateRandomId(mediaIDPrefixes[fileType]),
+		FileType: fileType,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, fmt.Errorf("failed to rewind upload: %v", err)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to reread upload: %v", err)
+	}
+	return upload, data, nil
+}
@@ -0,0 +1,85 @@
+package producthandler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/backend/pkg/middleware"
+	httpresponse "github.com/wafi04/backend/pkg/response"
+	request "github.com/wafi04/backend/pkg/types/req"
+)
+
+// HandleGetPriceHistory returns the price history of a product, or of one of
+// its variants with ?variant_id=, along with the lowest price of the 30 days
+// before the current price.
+func (h *ProductHandler) HandleGetPriceHistory(c *gin.Context) {
+	res, err := h.productService.GetPriceHistory(c, &request.GetPriceHistoryRequest{
+		ProductID: c.Param("id"),
+		VariantID: c.Query("variant_id"),
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "not found") {
+			status = http.StatusNotFound
+		}
+		httpresponse.SendErrorResponseWithDetails(c, status, "Failed to get price history", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Get Price History Success", res)
+}
+
+func (h *ProductHandler) HandleSchedulePriceChange(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		httpresponse.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req request.SchedulePriceChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+	req.ProductID = c.Param("id")
+	req.CreatedBy = user.UserID
+
+	res, err := h.productService.SchedulePriceChange(c, &req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if strings.Contains(err.Error(), "not found") {
+			status = http.StatusNotFound
+		}
+		httpresponse.SendErrorResponseWithDetails(c, status, "Failed to schedule price change", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusCreated, "Price Change Scheduled", res)
+}
+
+func (h *ProductHandler) HandleListPriceChanges(c *gin.Context) {
+	res, err := h.productService.ListPriceChanges(c, c.Param("id"))
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusInternalServerError, "Failed to get price changes", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Get Price Changes Success", res)
+}
+
+func (h *ProductHandler) HandleCancelPriceChange(c *gin.Context) {
+	err := h.productService.CancelPriceChange(c, &request.CancelPriceChangeRequest{
+		ID: c.Param("id"),
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "not found") {
+			status = http.StatusNotFound
+		}
+		httpresponse.SendErrorResponseWithDetails(c, status, "Failed to cancel price change", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Price Change Cancelled", nil)
+}
@@ -0,0 +1,175 @@
+package producthandler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/backend/pkg/middleware"
+	httpresponse "github.com/wafi04/backend/pkg/response"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+)
+
+func qaErrorStatus(err error) int {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		return http.StatusNotFound
+	case strings.Contains(err.Error(), "only admins"):
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
+}
+
+func (h *ProductHandler) HandleCreateQuestion(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		httpresponse.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req struct {
+		Body string `json:"body" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	question, err := h.productService.CreateQuestion(c, &request.CreateQuestionRequest{
+		ProductID: c.Param("id"),
+		UserID:    user.UserID,
+		Body:      req.Body,
+	})
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, qaErrorStatus(err), "Failed to create question", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusCreated, "Question submitted for moderation", question)
+}
+
+// HandleListProductQuestions lists approved questions of a product with
+// their approved answers.
+func (h *ProductHandler) HandleListProductQuestions(c *gin.Context) {
+	res, err := h.productService.ListQuestions(c, &request.ListQuestionsRequest{
+		ProductID: c.Param("id"),
+		Status:    types.QAStatusApproved,
+		PageToken: c.DefaultQuery("page", "0"),
+	})
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusInternalServerError, "Failed to get questions", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Get Questions Success", res)
+}
+
+func (h *ProductHandler) HandleCreateAnswer(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		httpresponse.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req struct {
+		Body string `json:"body" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	answer, err := h.productService.CreateAnswer(c, &request.CreateAnswerRequest{
+		QuestionID: c.Param("id"),
+		UserID:     user.UserID,
+		IsAdmin:    user.Role == "admin",
+		Body:       req.Body,
+	})
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, qaErrorStatus(err), "Failed to create answer", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusCreated, "Answer Created", answer)
+}
+
+func (h *ProductHandler) HandleUpvoteAnswer(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		httpresponse.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	res, err := h.productService.UpvoteAnswer(c, &request.UpvoteAnswerRequest{
+		AnswerID: c.Param("id"),
+		UserID:   user.UserID,
+	})
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, qaErrorStatus(err), "Failed to upvote answer", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Answer Upvoted", res)
+}
+
+func (h *ProductHandler) HandleListQuestionQueue(c *gin.Context) {
+	res, err := h.productService.ListQuestions(c, &request.ListQuestionsRequest{
+		ProductID: c.Query("product_id"),
+		Status:    c.DefaultQuery("status", types.QAStatusPending),
+		PageToken: c.DefaultQuery("page", "0"),
+		PageSize:  50,
+	})
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusInternalServerError, "Failed to get questions", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Get Questions Success", res)
+}
+
+func (h *ProductHandler) HandleListAnswerQueue(c *gin.Context) {
+	res, err := h.productService.ListAnswers(c, &request.ListAnswersRequest{
+		Status:    c.DefaultQuery("status", types.QAStatusPending),
+		PageToken: c.DefaultQuery("page", "0"),
+		PageSize:  50,
+	})
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusInternalServerError, "Failed to get answers", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Get Answers Success", res)
+}
+
+func (h *ProductHandler) HandleModerateQuestion(c *gin.Context) {
+	var req request.ModerateQARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+	req.ID = c.Param("id")
+
+	if err := h.productService.ModerateQuestion(c, &req); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, qaErrorStatus(err), "Failed to moderate question", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Question Moderated", req)
+}
+
+func (h *ProductHandler) HandleModerateAnswer(c *gin.Context) {
+	var req request.ModerateQARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+	req.ID = c.Param("id")
+
+	if err := h.productService.ModerateAnswer(c, &req); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, qaErrorStatus(err), "Failed to moderate answer", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Answer Moderated", req)
+}
@@ -0,0 +1,106 @@
+package producthandler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/backend/pkg/middleware"
+	httpresponse "github.com/wafi04/backend/pkg/response"
+	request "github.com/wafi04/backend/pkg/types/req"
+)
+
+func (h *ProductHandler) HandleGetRelatedProducts(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	res, err := h.productService.GetRelatedProducts(c, &request.GetRelatedProductsRequest{
+		ProductID: c.Param("id"),
+		Limit:     limit,
+	})
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusInternalServerError, "Failed to get related products", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Get Related Products Success", res)
+}
+
+func (h *ProductHandler) HandleGetCartRecommendations(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		httpresponse.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	res, err := h.productService.GetCartRecommendations(c, &request.GetCartRecommendationsRequest{
+		UserID: user.UserID,
+		Limit:  limit,
+	})
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusInternalServerError, "Failed to get recommendations", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Get Recommendations Success", res)
+}
+
+func (h *ProductHandler) HandleListRecommendationOverrides(c *gin.Context) {
+	res, err := h.productService.ListRecommendationOverrides(c, c.Param("id"))
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusInternalServerError, "Failed to get overrides", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Get Overrides Success", res)
+}
+
+// HandleSetRecommendationOverride pins a related product at a position or
+// excludes it from the product's recommendations.
+func (h *ProductHandler) HandleSetRecommendationOverride(c *gin.Context) {
+	var req request.SetRecommendationOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+	req.ProductID = c.Param("id")
+
+	res, err := h.productService.SetRecommendationOverride(c, &req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if strings.Contains(err.Error(), "not found") {
+			status = http.StatusNotFound
+		}
+		httpresponse.SendErrorResponseWithDetails(c, status, "Failed to set override", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Override Saved", res)
+}
+
+func (h *ProductHandler) HandleDeleteRecommendationOverride(c *gin.Context) {
+	err := h.productService.DeleteRecommendationOverride(c, &request.DeleteRecommendationOverrideRequest{
+		ProductID: c.Param("id"),
+		RelatedID: c.Param("relatedId"),
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "not found") {
+			status = http.StatusNotFound
+		}
+		httpresponse.SendErrorResponseWithDetails(c, status, "Failed to delete override", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Override Deleted", nil)
+}
+
+func (h *ProductHandler) HandleRecomputeSimilarities(c *gin.Context) {
+	if err := h.productService.RecomputeSimilarities(c); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusInternalServerError, "Failed to recompute recommendations", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Recommendations Recomputed", nil)
+}
@@ -0,0 +1,178 @@
+package producthandler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/backend/pkg/logger"
+	"github.com/wafi04/backend/pkg/middleware"
+	httpresponse "github.com/wafi04/backend/pkg/response"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	"github.com/wafi04/backend/pkg/utils"
+	productservice "github.com/wafi04/backend/services/product/service"
+)
+
+// HandleCreateReview takes a multipart form with rating, title, body and up
+// to five "photos" files. New reviews wait for moderation before they are
+// shown or counted in the product rating.
+func (h *ProductHandler) HandleCreateReview(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		httpresponse.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	maxSize := int64(25 << 20)
+	if err := c.Request.ParseMultipartForm(maxSize); err != nil {
+		h.log.Log(logger.ErrorLevel, "Error parsing multipart form: %v", err)
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to parse form data", err.Error())
+		return
+	}
+
+	rating, err := strconv.Atoi(c.PostForm("rating"))
+	if err != nil {
+		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Rating is required")
+		return
+	}
+
+	files := c.Request.MultipartForm.File["photos"]
+	if len(files) > productservice.MaxReviewPhotos {
+		httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Too many photos")
+		return
+	}
+
+	req := &request.CreateReviewRequest{
+		ProductID: c.Param("id"),
+		UserID:    user.UserID,
+		Rating:    rating,
+		Title:     c.PostForm("title"),
+		Body:      c.PostForm("body"),
+	}
+
+	// Validate before uploading so rejected reviews leave no photos behind.
+	if err := h.productService.ValidateReview(c, req); err != nil {
+		sendReviewError(c, err)
+		return
+	}
+
+	for _, header := range files {
+		file, err := header.Open()
+		if err != nil {
+			httpresponse.SendErrorResponse(c, http.StatusBadRequest, "Failed to read photo")
+			return
+		}
+
+		uploadResponse, err := h.filesclient.UploadFile(c, &request.FileUploadRequest{
+			FileData: file,
+			Folder:   "reviews",
+			PublicID: utils.GenerateRandomId("REV"),
+		})
+		file.Close()
+		if err != nil {
+			httpresponse.SendUploadError(c, err, http.StatusInternalServerError, "Failed to upload photo")
+			return
+		}
+		req.Photos = append(req.Photos, uploadResponse.URL)
+	}
+
+	review, err := h.productService.CreateReview(c, req)
+	if err != nil {
+		sendReviewError(c, err)
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusCreated, "Review submitted for moderation", review)
+}
+
+func sendReviewError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	if strings.Contains(err.Error(), "not found") {
+		status = http.StatusNotFound
+	}
+	httpresponse.SendErrorResponseWithDetails(c, status, "Failed to create review", err.Error())
+}
+
+// HandleListProductReviews lists approved reviews of a product. Sort with
+// ?sort=recent|helpful|rating_desc|rating_asc|oldest.
+func (h *ProductHandler) HandleListProductReviews(c *gin.Context) {
+	res, err := h.productService.ListReviews(c, &request.ListReviewsRequest{
+		ProductID: c.Param("id"),
+		Status:    types.ReviewStatusApproved,
+		SortBy:    c.Query("sort"),
+		PageToken: c.DefaultQuery("page", "0"),
+	})
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusInternalServerError, "Failed to get reviews", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Get Reviews Success", res)
+}
+
+func (h *ProductHandler) HandleMarkReviewHelpful(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		httpresponse.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	res, err := h.productService.MarkReviewHelpful(c, &request.MarkReviewHelpfulRequest{
+		ReviewID: c.Param("id"),
+		UserID:   user.UserID,
+	})
+	if err != nil {
+		status := http.StatusBadRequest
+		if strings.Contains(err.Error(), "not found") {
+			status = http.StatusNotFound
+		}
+		httpresponse.SendErrorResponseWithDetails(c, status, "Failed to mark review helpful", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Marked Review Helpful", res)
+}
+
+// HandleListReviewQueue is the admin moderation queue. It shows pending
+// reviews unless ?status= asks for another status.
+func (h *ProductHandler) HandleListReviewQueue(c *gin.Context) {
+	res, err := h.productService.ListReviews(c, &request.ListReviewsRequest{
+		ProductID: c.Query("product_id"),
+		Status:    c.DefaultQuery("status", types.ReviewStatusPending),
+		SortBy:    "oldest",
+		PageToken: c.DefaultQuery("page", "0"),
+		PageSize:  50,
+	})
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusInternalServerError, "Failed to get reviews", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Get Reviews Success", res)
+}
+
+// HandleModerateReview approves or rejects a review. Because there is no
+// order history to check against, moderators also set verified_buyer here;
+// it is required when approving.
+func (h *ProductHandler) HandleModerateReview(c *gin.Context) {
+	var req request.ModerateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+	req.ID = c.Param("id")
+
+	review, err := h.productService.ModerateReview(c, &req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if strings.Contains(err.Error(), "not found") {
+			status = http.StatusNotFound
+		}
+		httpresponse.SendErrorResponseWithDetails(c, status, "Failed to moderate review", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Review Moderated", review)
+}
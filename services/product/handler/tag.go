@@ -0,0 +1,76 @@
+package producthandler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	httpresponse "github.com/wafi04/backend/pkg/response"
+	request "github.com/wafi04/backend/pkg/types/req"
+)
+
+func (h *ProductHandler) HandleAddProductTags(c *gin.Context) {
+	var req request.AddProductTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+	req.ProductID = c.Param("id")
+
+	res, err := h.productService.AddProductTags(c, &req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if strings.Contains(err.Error(), "not found") {
+			status = http.StatusNotFound
+		}
+		httpresponse.SendErrorResponseWithDetails(c, status, "Failed to add tags", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Tags Added", res)
+}
+
+func (h *ProductHandler) HandleRemoveProductTag(c *gin.Context) {
+	err := h.productService.RemoveProductTag(c, &request.RemoveProductTagRequest{
+		ProductID: c.Param("id"),
+		Slug:      c.Param("slug"),
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "not found") {
+			status = http.StatusNotFound
+		}
+		httpresponse.SendErrorResponseWithDetails(c, status, "Failed to remove tag", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Tag Removed", nil)
+}
+
+// HandleListTags returns the tags used by published products with their
+// product counts.
+func (h *ProductHandler) HandleListTags(c *gin.Context) {
+	res, err := h.productService.ListTags(c, &request.ListTagsRequest{
+		PublishedOnly: true,
+	})
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusInternalServerError, "Failed to get tags", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Get Tags Success", res)
+}
+
+// splitTags reads ?tag= as either repeated parameters or a comma separated
+// list.
+func splitTags(c *gin.Context) []string {
+	var tags []string
+	for _, raw := range c.QueryArray("tag") {
+		for _, tag := range strings.Split(raw, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
@@ -0,0 +1,128 @@
+package productRepository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/wafi04/backend/pkg/logger"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+)
+
+// SetBundleItems replaces the component lines of a bundle. Every component
+// must be an existing variant/size of a standard product.
+func (r *Database) SetBundleItems(ctx context.Context, req *request.SetBundleItemsRequest) ([]*types.BundleItem, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var productType string
+	err = tx.QueryRowContext(ctx, `
+        SELECT product_type FROM products
+        WHERE id = $1 AND deleted_at IS NULL
+        FOR UPDATE
+    `, req.BundleID).Scan(&productType)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("product not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %v", err)
+	}
+	if productType != types.ProductTypeBundle {
+		return nil, fmt.Errorf("product %s is not a bundle", req.BundleID)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM bundle_items WHERE bundle_id = $1`, req.BundleID); err != nil {
+		return nil, fmt.Errorf("failed to clear bundle items: %v", err)
+	}
+
+	for i, item := range req.Items {
+		var componentType string
+		err := tx.QueryRowContext(ctx, `
+            SELECT p.product_type
+            FROM inventory i
+            JOIN product_variants v ON v.id = i.variant_id
+            JOIN products p ON p.id = v.product_id
+            WHERE i.variant_id = $1 AND i.size = $2
+            AND v.deleted_at IS NULL AND p.deleted_at IS NULL
+        `, item.VariantID, item.Size).Scan(&componentType)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("component %s size %s not found", item.VariantID, item.Size)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to check component: %v", err)
+		}
+		if componentType == types.ProductTypeBundle {
+			return nil, fmt.Errorf("a bundle cannot contain another bundle")
+		}
+
+		_, err = tx.ExecContext(ctx, `
+            INSERT INTO bundle_items (id, bundle_id, variant_id, size, quantity, position)
+            VALUES ($1, $2, $3, $4, $5, $6)
+        `, uuid.New().String(), req.BundleID, item.VariantID, item.Size, item.Quantity, i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert bundle item: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	items, _, err := r.getBundleItems(ctx, req.BundleID)
+	return items, err
+}
+
+// getBundleItems returns the components of a bundle together with how many
+// bundles can be assembled from the current inventory, which is the lowest
+// number of complete sets any single component allows.
+func (r *Database) getBundleItems(ctx context.Context, bundleID string) ([]*types.BundleItem, int, error) {
+	const query = `
+        SELECT
+            b.id, b.variant_id, b.size, b.quantity,
+            v.product_id, p.name, v.color, v.sku,
+            COALESCE(i.available_stock, 0) / b.quantity
+        FROM bundle_items b
+        JOIN product_variants v ON v.id = b.variant_id
+        JOIN products p ON p.id = v.product_id
+        LEFT JOIN inventory i ON i.variant_id = b.variant_id AND i.size = b.size
+        WHERE b.bundle_id = $1
+        ORDER BY b.position
+    `
+
+	rows, err := r.DB.QueryContext(ctx, query, bundleID)
+	if err != nil {
+		r.log.Log(logger.ErrorLevel, "Failed to get bundle items: %v", err)
+		return nil, 0, fmt.Errorf("failed to get bundle items")
+	}
+	defer rows.Close()
+
+	items := make([]*types.BundleItem, 0)
+	available := -1
+	for rows.Next() {
+		item := &types.BundleItem{}
+		if err := rows.Scan(
+			&item.ID, &item.VariantID, &item.Size, &item.Quantity,
+			&item.ProductID, &item.ProductName, &item.Color, &item.SKU,
+			&item.Available,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan bundle item: %v", err)
+		}
+		if available < 0 || item.Available < available {
+			available = item.Available
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to get bundle items: %v", err)
+	}
+
+	if available < 0 {
+		available = 0
+	}
+	return items, available, nil
+}
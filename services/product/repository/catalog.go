@@ -0,0 +1,365 @@
+package productRepository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/wafi04/backend/pkg/types"
+	"github.com/wafi04/backend/pkg/utils"
+)
+
+// UpsertCatalogProduct creates or updates a product with its variants, images
+// and inventory, matching on product SKU, variant SKU and size. With dryRun
+// every statement still runs but the transaction is rolled back, so database
+// constraint errors are reported without changing anything.
+func (s *Database) UpsertCatalogProduct(ctx context.Context, p *types.CatalogProduct, dryRun bool) (bool, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	created, err := upsertCatalogProduct(ctx, tx, p)
+	if err != nil {
+		return false, err
+	}
+
+	if dryRun {
+		return created, nil
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return created, nil
+}
+
+func upsertCatalogProduct(ctx context.Context, tx *sql.Tx, p *types.CatalogProduct) (bool, error) {
+	var productID, currentName, currentSlug string
+	var currentPrice float64
+	err := tx.QueryRowContext(ctx, `
+        SELECT id, name, slug, price FROM products
+        WHERE sku = $1 AND deleted_at IS NULL
+        FOR UPDATE
+    `, p.SKU).Scan(&productID, &currentName, &currentSlug, &currentPrice)
+
+	created := err == sql.ErrNoRows
+	switch {
+	case created:
+		productID = utils.GenerateRandomId("PROD")
+		slug, err := utils.GenerateSlug(ctx, tx, "products", types.SlugEntityProduct, p.Name, productID)
+		if err != nil {
+			return false, err
+		}
+		_, err = tx.ExecContext(ctx, `
+            INSERT INTO products
+            (id, name, slug, sub_title, description, sku, price, category_id, status,
+             publish_at, unpublish_at, created_at, updated_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
+        `, productID, p.Name, slug, p.SubTitle, p.Description, p.SKU, p.Price, p.CategoryID, p.Status,
+			toNullTime(p.PublishAt), toNullTime(p.UnpublishAt))
+		if err != nil {
+			return false, fmt.Errorf("failed to insert product: %v", err)
+		}
+		if err := recordPriceChange(ctx, tx, productID, nil, nil, p.Price, types.PriceChangeCreated, ""); err != nil {
+			return false, err
+		}
+	case err != nil:
+		return false, fmt.Errorf("failed to get product: %v", err)
+	default:
+		slug := currentSlug
+		if p.Name != currentName {
+			slug, err = utils.GenerateSlug(ctx, tx, "products", types.SlugEntityProduct, p.Name, productID)
+			if err != nil {
+				return false, err
+			}
+			if slug != currentSlug {
+				if err := recordSlugChange(ctx, tx, productID, currentSlug, slug); err != nil {
+					return false, err
+				}
+			}
+		}
+		_, err = tx.ExecContext(ctx, `
+            UPDATE products
+            SET name = $1, slug = $2, sub_title = $3, description = $4,
+                price = $5, category_id = $6
+            WHERE id = $7
+        `, p.Name, slug, p.SubTitle, p.Description, p.Price, p.CategoryID, productID)
+		if err != nil {
+			return false, fmt.Errorf("failed to update product: %v", err)
+		}
+		// An import without a status leaves the product's status and
+		// schedule as they are.
+		if p.Status != "" {
+			_, err = tx.ExecContext(ctx, `
+                UPDATE products SET status = $1, publish_at = $2, unpublish_at = $3
+                WHERE id = $4
+            `, p.Status, toNullTime(p.PublishAt), toNullTime(p.UnpublishAt), productID)
+			if err != nil {
+				return false, fmt.Errorf("failed to update product status: %v", err)
+			}
+		}
+		if p.Price != currentPrice {
+			if err := recordPriceChange(ctx, tx, productID, nil, &currentPrice, p.Price, types.PriceChangeImport, ""); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	for _, v := range p.Variants {
+		if err := upsertCatalogVariant(ctx, tx, productID, v); err != nil {
+			return false, err
+		}
+	}
+
+	return created, nil
+}
+
+func upsertCatalogVariant(ctx context.Context, tx *sql.Tx, productID string, v *types.CatalogVariant) error {
+	var variantID string
+	err := tx.QueryRowContext(ctx, `
+        SELECT id FROM product_variants
+        WHERE product_id = $1 AND sku = $2 AND deleted_at IS NULL
+    `, productID, v.SKU).Scan(&variantID)
+
+	switch {
+	case err == sql.ErrNoRows:
+		variantID = uuid.New().String()
+		_, err = tx.ExecContext(ctx, `
+            INSERT INTO product_variants (id, color, sku, product_id)
+            VALUES ($1, $2, $3, $4)
+        `, variantID, v.Color, v.SKU, productID)
+		if err != nil {
+			return fmt.Errorf("failed to insert variant %s: %v", v.SKU, err)
+		}
+	case err != nil:
+		return fmt.Errorf("failed to get variant %s: %v", v.SKU, err)
+	default:
+		_, err = tx.ExecContext(ctx, `
+            UPDATE product_variants SET color = $1 WHERE id = $2
+        `, v.Color, variantID)
+		if err != nil {
+			return fmt.Errorf("failed to update variant %s: %v", v.SKU, err)
+		}
+	}
+
+	for _, url := range v.Images {
+		_, err = tx.ExecContext(ctx, `
+            INSERT INTO product_images (id, url, variant_id, is_main, position)
+            SELECT $1, $2, $3, NOT EXISTS (
+                SELECT 1 FROM product_images
+                WHERE variant_id = $3 AND is_main AND deleted_at IS NULL
+            ), (
+                SELECT COALESCE(MAX(position) + 1, 0) FROM product_images
+                WHERE variant_id = $3 AND deleted_at IS NULL
+            )
+            WHERE NOT EXISTS (
+                SELECT 1 FROM product_images
+                WHERE variant_id = $3 AND url = $2 AND deleted_at IS NULL
+            )
+        `, uuid.New().String(), url, variantID)
+		if err != nil {
+			return fmt.Errorf("failed to insert image for variant %s: %v", v.SKU, err)
+		}
+	}
+
+	for _, inv := range v.Inventory {
+		result, err := tx.ExecContext(ctx, `
+            UPDATE inventory
+            SET stock = $1, available_stock = $1 - COALESCE(reserved_stock, 0)
+            WHERE variant_id = $2 AND size = $3
+        `, inv.Stock, variantID, inv.Size)
+		if err != nil {
+			return fmt.Errorf("failed to update inventory %s/%s: %v", v.SKU, inv.Size, err)
+		}
+		if rows, _ := result.RowsAffected(); rows > 0 {
+			continue
+		}
+		_, err = tx.ExecContext(ctx, `
+            INSERT INTO inventory (id, variant_id, size, stock, available_stock, reserved_stock, created_at, updated_at)
+            VALUES ($1, $2, $3, $4, $4, 0, NOW(), NOW())
+        `, utils.GenerateRandomId("INV"), variantID, inv.Size, inv.Stock)
+		if err != nil {
+			return fmt.Errorf("failed to insert inventory %s/%s: %v", v.SKU, inv.Size, err)
+		}
+	}
+
+	return nil
+}
+
+// GetCatalogProductStatus returns the status of the product with the given
+// SKU, or an empty string when no such product exists.
+func (s *Database) GetCatalogProductStatus(ctx context.Context, sku string) (string, error) {
+	var status string
+	err := s.DB.QueryRowContext(ctx, `
+        SELECT status FROM products WHERE sku = $1 AND deleted_at IS NULL
+    `, sku).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get product status: %v", err)
+	}
+	return status, nil
+}
+
+// ExistingCategoryIDs returns which of the given category IDs exist and are
+// not deleted.
+func (s *Database) ExistingCategoryIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT id FROM categories WHERE id = ANY($1) AND deleted_at IS NULL
+    `, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check categories: %v", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %v", err)
+		}
+		existing[id] = true
+	}
+	return existing, rows.Err()
+}
+
+// ExportCatalog returns every non-deleted product in the import format.
+func (s *Database) ExportCatalog(ctx context.Context) ([]*types.CatalogProduct, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT
+            p.sku, p.name, COALESCE(p.sub_title, ''), p.description,
+            p.price, p.category_id, p.status, p.publish_at, p.unpublish_at,
+            v.sku, v.color,
+            (
+                SELECT COALESCE(ARRAY_AGG(i.url ORDER BY i.is_main DESC, i.id), '{}')
+                FROM product_images i
+                WHERE i.variant_id = v.id AND i.deleted_at IS NULL
+            ),
+            inv.size, inv.stock
+        FROM products p
+        LEFT JOIN product_variants v ON v.product_id = p.id AND v.deleted_at IS NULL
+        LEFT JOIN inventory inv ON inv.variant_id = v.id
+        WHERE p.deleted_at IS NULL
+        ORDER BY p.created_at, p.sku, v.sku, inv.size
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export catalog: %v", err)
+	}
+	defer rows.Close()
+
+	var products []*types.CatalogProduct
+	var product *types.CatalogProduct
+	var variant *types.CatalogVariant
+	for rows.Next() {
+		var p types.CatalogProduct
+		var publishAt, unpublishAt sql.NullTime
+		var variantSKU, color, size sql.NullString
+		var images pq.StringArray
+		var stock sql.NullInt64
+		if err := rows.Scan(
+			&p.SKU, &p.Name, &p.SubTitle, &p.Description,
+			&p.Price, &p.CategoryID, &p.Status, &publishAt, &unpublishAt,
+			&variantSKU, &color, &images, &size, &stock,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan catalog row: %v", err)
+		}
+
+		if product == nil || product.SKU != p.SKU {
+			p.PublishAt = fromNullTime(publishAt)
+			p.UnpublishAt = fromNullTime(unpublishAt)
+			product = &p
+			variant = nil
+			products = append(products, product)
+		}
+		if !variantSKU.Valid {
+			continue
+		}
+		if variant == nil || variant.SKU != variantSKU.String {
+			variant = &types.CatalogVariant{
+				SKU:    variantSKU.String,
+				Color:  color.String,
+				Images: images,
+			}
+			product.Variants = append(product.Variants, variant)
+		}
+		if size.Valid {
+			variant.Inventory = append(variant.Inventory, &types.CatalogInventory{
+				Size:  size.String,
+				Stock: int(stock.Int64),
+			})
+		}
+	}
+
+	return products, rows.Err()
+}
+
+func (s *Database) CreateImportJob(ctx context.Context, job *types.ImportJob) error {
+	_, err := s.DB.ExecContext(ctx, `
+        INSERT INTO import_jobs (id, format, status, dry_run, total, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+    `, job.ID, job.Format, job.Status, job.DryRun, job.Total, job.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create import job: %v", err)
+	}
+	return nil
+}
+
+func (s *Database) UpdateImportJob(ctx context.Context, job *types.ImportJob) error {
+	errs, err := json.Marshal(job.Errors)
+	if err != nil {
+		return fmt.Errorf("failed to encode import errors: %v", err)
+	}
+
+	var finishedAt sql.NullTime
+	if job.FinishedAt != nil {
+		finishedAt = sql.NullTime{Time: *job.FinishedAt, Valid: true}
+	}
+
+	_, err = s.DB.ExecContext(ctx, `
+        UPDATE import_jobs
+        SET status = $1, processed = $2, created = $3, updated = $4,
+            failed = $5, errors = $6, finished_at = $7
+        WHERE id = $8
+    `, job.Status, job.Processed, job.Created, job.Updated, job.Failed, errs, finishedAt, job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update import job: %v", err)
+	}
+	return nil
+}
+
+func (s *Database) GetImportJob(ctx context.Context, id string) (*types.ImportJob, error) {
+	var job types.ImportJob
+	var errs []byte
+	var finishedAt sql.NullTime
+
+	err := s.DB.QueryRowContext(ctx, `
+        SELECT id, format, status, dry_run, total, processed,
+               created, updated, failed, errors, created_at, finished_at
+        FROM import_jobs
+        WHERE id = $1
+    `, id).Scan(
+		&job.ID, &job.Format, &job.Status, &job.DryRun, &job.Total, &job.Processed,
+		&job.Created, &job.Updated, &job.Failed, &errs, &job.CreatedAt, &finishedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("import job not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get import job: %v", err)
+	}
+
+	if err := json.Unmarshal(errs, &job.Errors); err != nil {
+		return nil, fmt.Errorf("failed to decode import errors: %v", err)
+	}
+	if finishedAt.Valid {
+		t := finishedAt.Time
+		job.FinishedAt = &t
+	}
+	return &job, nil
+}
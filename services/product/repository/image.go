@@ -2,7 +2,9 @@ package productRepository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
@@ -13,94 +15,344 @@ import (
 )
 
 func (pr *Database) AddProductImage(ctx context.Context, req *request.AddProductImageRequest) (*types.ProductImage, error) {
-	imageID := uuid.New().String()
+	images, err := pr.AddProductImages(ctx, req.VariantID, []*request.AddProductImageRequest{req})
+	if err != nil {
+		return nil, err
+	}
+	return images[0], nil
+}
 
-	querySelect := `
-        SELECT id FROM product_variants WHERE id = $1
-    `
-	var variantID string
-	err := pr.DB.QueryRowContext(ctx, querySelect, req.VariantID).Scan(&variantID)
+// AddProductImages adds several images to a variant in one transaction, in
+// the order given.
+func (pr *Database) AddProductImages(ctx context.Context, variantID string, reqs []*request.AddProductImageRequest) ([]*types.ProductImage, error) {
+	tx, err := pr.DB.BeginTx(ctx, nil)
 	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockVariant(ctx, tx, variantID); err != nil {
 		pr.log.Log(logger.ErrorLevel, "Variant not found: %v", err)
-		return nil, fmt.Errorf("variant not found: %v", err)
+		return nil, err
 	}
 
-	queryInsert := `
-        INSERT INTO product_images (id, url, variant_id, is_main)
-        VALUES ($1, $2, $3, $4)
-        RETURNING id, url, variant_id, is_main
-    `
+	images := make([]*types.ProductImage, 0, len(reqs))
+	for _, req := range reqs {
+		image, err := addImage(ctx, tx, variantID, req)
+		if err != nil {
+			pr.log.Log(logger.ErrorLevel, "Failed to create product image: %v", err)
+			return nil, err
+		}
+		images = append(images, image)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return images, nil
+}
+
+// lockVariant locks a live variant so concurrent image changes on it are
+// serialized, which keeps positions and the single main image consistent.
+func lockVariant(ctx context.Context, tx *sql.Tx, variantID string) error {
+	var id string
+	err := tx.QueryRowContext(ctx,
+		"SELECT id FROM product_variants WHERE id = $1 AND deleted_at IS NULL FOR UPDATE",
+		variantID,
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("variant not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get variant: %v", err)
+	}
+	return nil
+}
+
+func addImage(ctx context.Context, tx *sql.Tx, variantID string, req *request.AddProductImageRequest) (*types.ProductImage, error) {
+	var next int32
+	var hasMain bool
+	err := tx.QueryRowContext(ctx, `
+        SELECT COALESCE(MAX(position) + 1, 0), COALESCE(BOOL_OR(is_main), false)
+        FROM product_images
+        WHERE variant_id = $1 AND deleted_at IS NULL`,
+		variantID,
+	).Scan(&next, &hasMain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image position: %v", err)
+	}
+
+	position := next
+	if req.Position != nil && *req.Position < next {
+		if *req.Position < 0 {
+			return nil, fmt.Errorf("invalid position: must not be negative")
+		}
+		position = *req.Position
+		_, err = tx.ExecContext(ctx, `
+            UPDATE product_images
+            SET position = position + 1
+            WHERE variant_id = $1 AND deleted_at IS NULL AND position >= $2`,
+			variantID, position,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to shift images: %v", err)
+		}
+	}
+
+	isMain := req.IsMain || !hasMain
+	if isMain && hasMain {
+		if err := unsetMainImage(ctx, tx, variantID); err != nil {
+			return nil, err
+		}
+	}
 
 	var image types.ProductImage
-	err = pr.DB.QueryRowContext(ctx, queryInsert, imageID, req.URL, req.VariantID, req.IsMain).Scan(
-		&image.ID,
-		&image.URL,
-		&image.VariantID,
-		&image.IsMain,
-	)
+	err = tx.QueryRowContext(ctx, `
+        INSERT INTO product_images (id, url, variant_id, is_main, position, alt_text)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, url, variant_id, is_main, position, alt_text`,
+		uuid.New().String(), req.URL, variantID, isMain, position, req.AltText,
+	).Scan(&image.ID, &image.URL, &image.VariantID, &image.IsMain, &image.Position, &image.AltText)
 	if err != nil {
-		pr.log.Log(logger.ErrorLevel, "Failed to create product image: %v", err)
 		return nil, fmt.Errorf("failed to create product image: %v", err)
 	}
+	return &image, nil
+}
 
+func unsetMainImage(ctx context.Context, tx *sql.Tx, variantID string) error {
+	_, err := tx.ExecContext(ctx,
+		"UPDATE product_images SET is_main = false WHERE variant_id = $1 AND is_main AND deleted_at IS NULL",
+		variantID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to unset main image: %v", err)
+	}
+	return nil
+}
+
+// SetMainProductImage makes one image the variant's main image and clears
+// the flag on the previous one in the same transaction.
+func (pr *Database) SetMainProductImage(ctx context.Context, req *request.SetMainProductImageRequest) (*types.ProductImage, error) {
+	tx, err := pr.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockVariant(ctx, tx, req.VariantID); err != nil {
+		return nil, err
+	}
+	image, err := setMainImage(ctx, tx, req.VariantID, req.ImageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return image, nil
+}
+
+func setMainImage(ctx context.Context, tx *sql.Tx, variantID, imageID string) (*types.ProductImage, error) {
+	if err := unsetMainImage(ctx, tx, variantID); err != nil {
+		return nil, err
+	}
+
+	var image types.ProductImage
+	err := tx.QueryRowContext(ctx, `
+        UPDATE product_images
+        SET is_main = true
+        WHERE id = $1 AND variant_id = $2 AND deleted_at IS NULL
+        RETURNING id, url, variant_id, is_main, position, alt_text`,
+		imageID, variantID,
+	).Scan(&image.ID, &image.URL, &image.VariantID, &image.IsMain, &image.Position, &image.AltText)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("image not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set main image: %v", err)
+	}
 	return &image, nil
 }
 
+// ReorderProductImages rewrites the positions of a variant's images to the
+// order given in req.IDs.
+func (pr *Database) ReorderProductImages(ctx context.Context, req *request.ReorderProductImagesRequest) error {
+	tx, err := pr.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockVariant(ctx, tx, req.VariantID); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		"SELECT id FROM product_images WHERE variant_id = $1 AND deleted_at IS NULL",
+		req.VariantID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get images: %v", err)
+	}
+	images := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan image: %v", err)
+		}
+		images[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating images: %v", err)
+	}
+
+	if len(images) != len(req.IDs) {
+		return fmt.Errorf("invalid order: expected %d images, got %d", len(images), len(req.IDs))
+	}
+	for _, id := range req.IDs {
+		if !images[id] {
+			return fmt.Errorf("invalid order: %s is not an image of this variant", id)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE product_images i
+        SET position = o.ordinality - 1
+        FROM UNNEST($1::VARCHAR[]) WITH ORDINALITY AS o(id, ordinality)
+        WHERE i.id = o.id`,
+		pq.Array(req.IDs),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reorder images: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// UpdateProductImage changes an image's URL and alt text. Setting IsMain
+// moves the main flag to it; clearing the flag is done by choosing another
+// main image.
 func (pr *Database) UpdateProductImage(ctx context.Context, req *request.UpdateProductImageRequest) (*types.ProductImage, error) {
-	querySelect := `
-        SELECT id FROM product_images WHERE id = $1
-    `
-	var imageID string
-	err := pr.DB.QueryRowContext(ctx, querySelect, req.Image.ID).Scan(&imageID)
+	tx, err := pr.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var variantID string
+	err = tx.QueryRowContext(ctx,
+		"SELECT variant_id FROM product_images WHERE id = $1 AND deleted_at IS NULL",
+		req.Image.ID,
+	).Scan(&variantID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("image not found")
+	}
 	if err != nil {
 		pr.log.Log(logger.ErrorLevel, "Image not found: %v", err)
-		return nil, fmt.Errorf("image not found: %v", err)
+		return nil, fmt.Errorf("failed to get image: %v", err)
+	}
+	if err := lockVariant(ctx, tx, variantID); err != nil {
+		return nil, err
 	}
 
-	queryUpdate := `
+	_, err = tx.ExecContext(ctx, `
         UPDATE product_images
-        SET url = $1, is_main = $2
-        WHERE id = $3
-        RETURNING id, url, variant_id, is_main
-    `
-
-	var image types.ProductImage
-	err = pr.DB.QueryRowContext(ctx, queryUpdate, req.Image.URL, req.Image.IsMain, req.Image.ID).Scan(
-		&image.ID,
-		&image.URL,
-		&image.VariantID,
-		&image.IsMain,
+        SET url = COALESCE(NULLIF($1, ''), url), alt_text = $2
+        WHERE id = $3`,
+		req.Image.URL, req.Image.AltText, req.Image.ID,
 	)
 	if err != nil {
 		pr.log.Log(logger.ErrorLevel, "Failed to update product image: %v", err)
 		return nil, fmt.Errorf("failed to update product image: %v", err)
 	}
 
+	var image *types.ProductImage
+	if req.Image.IsMain {
+		image, err = setMainImage(ctx, tx, variantID, req.Image.ID)
+	} else {
+		image, err = getImage(ctx, tx, req.Image.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return image, nil
+}
+
+func getImage(ctx context.Context, tx *sql.Tx, id string) (*types.ProductImage, error) {
+	var image types.ProductImage
+	err := tx.QueryRowContext(ctx,
+		"SELECT id, url, variant_id, is_main, position, alt_text FROM product_images WHERE id = $1",
+		id,
+	).Scan(&image.ID, &image.URL, &image.VariantID, &image.IsMain, &image.Position, &image.AltText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image: %v", err)
+	}
 	return &image, nil
 }
 
+// DeleteProductImage moves an image to the trash. When it was the main
+// image, the first remaining image takes over.
 func (pr *Database) DeleteProductImage(ctx context.Context, req *request.DeleteProductImageRequest) (*response.DeleteProductResponse, error) {
-	queryDelete := `
-        DELETE FROM product_images
-        WHERE id = $1
-    `
+	tx, err := pr.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
 
-	result, err := pr.DB.ExecContext(ctx, queryDelete, req.ID)
+	var variantID string
+	var wasMain bool
+	err = tx.QueryRowContext(ctx, `
+        WITH old AS (
+            SELECT id, variant_id, is_main FROM product_images
+            WHERE id = $1 AND deleted_at IS NULL
+            FOR UPDATE
+        )
+        UPDATE product_images i
+        SET deleted_at = NOW(), is_main = false
+        FROM old
+        WHERE i.id = old.id
+        RETURNING old.variant_id, old.is_main`,
+		req.ID,
+	).Scan(&variantID, &wasMain)
+	if err == sql.ErrNoRows {
+		return &response.DeleteProductResponse{
+			Success: false,
+		}, nil
+	}
 	if err != nil {
 		pr.log.Log(logger.ErrorLevel, "Failed to delete product image: %v", err)
 		return nil, fmt.Errorf("failed to delete product image: %v", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		pr.log.Log(logger.ErrorLevel, "Failed to get rows affected: %v", err)
-		return nil, fmt.Errorf("failed to get rows affected: %v", err)
+	if wasMain {
+		_, err = tx.ExecContext(ctx, `
+            UPDATE product_images
+            SET is_main = true
+            WHERE id = (
+                SELECT id FROM product_images
+                WHERE variant_id = $1 AND deleted_at IS NULL
+                ORDER BY position, id
+                LIMIT 1
+            )`,
+			variantID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to promote main image: %v", err)
+		}
 	}
 
-	if rowsAffected == 0 {
-		return &response.DeleteProductResponse{
-			Success: false,
-		}, nil
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
 	}
 
 	return &response.DeleteProductResponse{
@@ -110,10 +362,11 @@ func (pr *Database) DeleteProductImage(ctx context.Context, req *request.DeleteP
 
 func (r *Database) enrichVariantsWithImages(ctx context.Context, variants []*types.ProductVariant, variantIDs []string) error {
 	const query = `
-        SELECT id, url, variant_id, is_main
+        SELECT id, url, variant_id, is_main, position, alt_text
         FROM product_images
         WHERE variant_id = ANY($1)
-        ORDER BY is_main DESC
+        AND deleted_at IS NULL
+        ORDER BY variant_id, position, id
     `
 
 	rows, err := r.DB.QueryContext(ctx, query, pq.Array(variantIDs))
@@ -124,11 +377,13 @@ func (r *Database) enrichVariantsWithImages(ctx context.Context, variants []*typ
 	defer rows.Close()
 
 	variantMap := createVariantMap(variants)
+	images := make(map[string]*types.ProductImage)
+	var imageIDs []string
 
 	for rows.Next() {
 		var img types.ProductImage
 		var variantID string
-		if err := rows.Scan(&img.ID, &img.URL, &variantID, &img.IsMain); err != nil {
+		if err := rows.Scan(&img.ID, &img.URL, &variantID, &img.IsMain, &img.Position, &img.AltText); err != nil {
 			r.log.Log(logger.ErrorLevel, "Failed to scan image row: %v", err)
 			return fmt.Errorf("failed to scan image row")
 		}
@@ -136,8 +391,99 @@ func (r *Database) enrichVariantsWithImages(ctx context.Context, variants []*typ
 		if variant, exists := variantMap[variantID]; exists {
 			img.VariantID = variantID
 			variant.Images = append(variant.Images, &img)
+			images[img.ID] = &img
+			imageIDs = append(imageIDs, img.ID)
 		}
 	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating images: %v", err)
+	}
+
+	return r.enrichImagesWithDerivatives(ctx, images, imageIDs)
+}
+
+func (r *Database) enrichImagesWithDerivatives(ctx context.Context, images map[string]*types.ProductImage, imageIDs []string) error {
+	if len(imageIDs) == 0 {
+		return nil
+	}
 
+	rows, err := r.DB.QueryContext(ctx, `
+        SELECT image_id, name, format, width, height, url
+        FROM product_image_derivatives
+        WHERE image_id = ANY($1)
+        ORDER BY image_id, format, width`,
+		pq.Array(imageIDs),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get image derivatives: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var imageID string
+		var d types.ImageDerivative
+		if err := rows.Scan(&imageID, &d.Name, &d.Format, &d.Width, &d.Height, &d.URL); err != nil {
+			return fmt.Errorf("failed to scan image derivative: %v", err)
+		}
+		if img, ok := images[imageID]; ok {
+			img.Derivatives = append(img.Derivatives, &d)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating image derivatives: %v", err)
+	}
+
+	for _, img := range images {
+		img.Sources = imageSources(img.Derivatives)
+	}
+	return nil
+}
+
+// imageSources builds one srcset per format from derivatives sorted by
+// format and width, with the preferred formats first.
+func imageSources(derivatives []*types.ImageDerivative) []*types.ImageSource {
+	byFormat := make(map[string][]string)
+	for _, d := range derivatives {
+		byFormat[d.Format] = append(byFormat[d.Format], fmt.Sprintf("%s %dw", d.URL, d.Width))
+	}
+
+	var sources []*types.ImageSource
+	for _, format := range []string{"avif", "webp", "jpeg", "png"} {
+		if entries, ok := byFormat[format]; ok {
+			sources = append(sources, &types.ImageSource{
+				Type:   "image/" + format,
+				SrcSet: strings.Join(entries, ", "),
+			})
+		}
+	}
+	return sources
+}
+
+// SaveImageDerivatives replaces the stored derivatives of an image.
+func (pr *Database) SaveImageDerivatives(ctx context.Context, imageID string, derivatives []*types.ImageDerivative) error {
+	tx, err := pr.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM product_image_derivatives WHERE image_id = $1", imageID); err != nil {
+		return fmt.Errorf("failed to clear image derivatives: %v", err)
+	}
+
+	for _, d := range derivatives {
+		_, err := tx.ExecContext(ctx, `
+            INSERT INTO product_image_derivatives (image_id, name, format, width, height, url)
+            VALUES ($1, $2, $3, $4, $5, $6)`,
+			imageID, d.Name, d.Format, d.Width, d.Height, d.URL,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save image derivative: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
 	return nil
 }
@@ -0,0 +1,238 @@
+package productRepository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wafi04/backend/pkg/logger"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+)
+
+// lockMediaOwner locks the product, checks that variantID (when set)
+// belongs to it, and returns the next position in the owner's list.
+func lockMediaOwner(ctx context.Context, tx *sql.Tx, table, productID, variantID string) (int32, error) {
+	var id string
+	err := tx.QueryRowContext(ctx,
+		"SELECT id FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE",
+		productID,
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("product not found")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get product: %v", err)
+	}
+
+	if variantID != "" {
+		err = tx.QueryRowContext(ctx,
+			"SELECT id FROM product_variants WHERE id = $1 AND product_id = $2 AND deleted_at IS NULL",
+			variantID, productID,
+		).Scan(&id)
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("variant not found")
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to get variant: %v", err)
+		}
+	}
+
+	var next int32
+	err = tx.QueryRowContext(ctx, `
+        SELECT COALESCE(MAX(position) + 1, 0)
+        FROM `+table+`
+        WHERE product_id = $1 AND variant_id IS NOT DISTINCT FROM $2`,
+		productID, nullString(variantID),
+	).Scan(&next)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get position: %v", err)
+	}
+	return next, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func (r *Database) AddProductVideo(ctx context.Context, req *request.AddProductVideoRequest) (*types.ProductVideo, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	position, err := lockMediaOwner(ctx, tx, "product_videos", req.ProductID, req.VariantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var video types.ProductVideo
+	var variantID sql.NullString
+	var createdAt time.Time
+	err = tx.QueryRowContext(ctx, `
+        INSERT INTO product_videos (id, product_id, variant_id, url, poster_url, title, duration, width, height, position)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        RETURNING id, product_id, variant_id, url, poster_url, title, duration, width, height, position, created_at`,
+		uuid.New().String(), req.ProductID, nullString(req.VariantID), req.URL, req.PosterURL, req.Title,
+		req.Duration, req.Width, req.Height, position,
+	).Scan(
+		&video.ID, &video.ProductID, &variantID, &video.URL, &video.PosterURL, &video.Title,
+		&video.Duration, &video.Width, &video.Height, &video.Position, &createdAt,
+	)
+	if err != nil {
+		r.log.Log(logger.ErrorLevel, "Failed to create product video: %v", err)
+		return nil, fmt.Errorf("failed to create product video: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	if variantID.Valid {
+		video.VariantID = &variantID.String
+	}
+	video.CreatedAt = createdAt.Unix()
+	return &video, nil
+}
+
+func (r *Database) AddProductDocument(ctx context.Context, req *request.AddProductDocumentRequest) (*types.ProductDocument, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	position, err := lockMediaOwner(ctx, tx, "product_documents", req.ProductID, req.VariantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc types.ProductDocument
+	var variantID sql.NullString
+	var createdAt time.Time
+	err = tx.QueryRowContext(ctx, `
+        INSERT INTO product_documents (id, product_id, variant_id, kind, title, url, size, position)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id, product_id, variant_id, kind, title, url, size, position, created_at`,
+		uuid.New().String(), req.ProductID, nullString(req.VariantID), req.Kind, req.Title, req.URL, req.Size, position,
+	).Scan(
+		&doc.ID, &doc.ProductID, &variantID, &doc.Kind, &doc.Title, &doc.URL, &doc.Size, &doc.Position, &createdAt,
+	)
+	if err != nil {
+		r.log.Log(logger.ErrorLevel, "Failed to create product document: %v", err)
+		return nil, fmt.Errorf("failed to create product document: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	if variantID.Valid {
+		doc.VariantID = &variantID.String
+	}
+	doc.CreatedAt = createdAt.Unix()
+	return &doc, nil
+}
+
+// DeleteProductVideo removes the row only; the file is collected by the
+// media GC once nothing references it.
+func (r *Database) DeleteProductVideo(ctx context.Context, req *request.DeleteProductMediaRequest) error {
+	return r.deleteProductMedia(ctx, "product_videos", "video", req)
+}
+
+func (r *Database) DeleteProductDocument(ctx context.Context, req *request.DeleteProductMediaRequest) error {
+	return r.deleteProductMedia(ctx, "product_documents", "document", req)
+}
+
+func (r *Database) deleteProductMedia(ctx context.Context, table, kind string, req *request.DeleteProductMediaRequest) error {
+	res, err := r.DB.ExecContext(ctx,
+		"DELETE FROM "+table+" WHERE id = $1 AND product_id = $2",
+		req.ID, req.ProductID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete product %s: %v", kind, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("product %s not found", kind)
+	}
+	return nil
+}
+
+// enrichProductWithMedia loads the videos and documents of product and puts
+// each on the product or on its variant. Media of variants that are not in
+// product.Variants, such as trashed ones, is left out.
+func (r *Database) enrichProductWithMedia(ctx context.Context, product *types.Product) error {
+	variantMap := createVariantMap(product.Variants)
+
+	rows, err := r.DB.QueryContext(ctx, `
+        SELECT id, variant_id, url, poster_url, title, duration, width, height, position, created_at
+        FROM product_videos
+        WHERE product_id = $1
+        ORDER BY position, id`,
+		product.ID,
+	)
+	if err != nil {
+		r.log.Log(logger.ErrorLevel, "Failed to get videos: %v", err)
+		return fmt.Errorf("failed to get product videos")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		video := &types.ProductVideo{ProductID: product.ID}
+		var variantID sql.NullString
+		var createdAt time.Time
+		if err := rows.Scan(
+			&video.ID, &variantID, &video.URL, &video.PosterURL, &video.Title,
+			&video.Duration, &video.Width, &video.Height, &video.Position, &createdAt,
+		); err != nil {
+			return fmt.Errorf("failed to scan video row: %v", err)
+		}
+		video.CreatedAt = createdAt.Unix()
+
+		if !variantID.Valid {
+			product.Videos = append(product.Videos, video)
+		} else if variant, ok := variantMap[variantID.String]; ok {
+			video.VariantID = &variantID.String
+			variant.Videos = append(variant.Videos, video)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating videos: %v", err)
+	}
+
+	docRows, err := r.DB.QueryContext(ctx, `
+        SELECT id, variant_id, kind, title, url, size, position, created_at
+        FROM product_documents
+        WHERE product_id = $1
+        ORDER BY position, id`,
+		product.ID,
+	)
+	if err != nil {
+		r.log.Log(logger.ErrorLevel, "Failed to get documents: %v", err)
+		return fmt.Errorf("failed to get product documents")
+	}
+	defer docRows.Close()
+
+	for docRows.Next() {
+		doc := &types.ProductDocument{ProductID: product.ID}
+		var variantID sql.NullString
+		var createdAt time.Time
+		if err := docRows.Scan(
+			&doc.ID, &variantID, &doc.Kind, &doc.Title, &doc.URL, &doc.Size, &doc.Position, &createdAt,
+		); err != nil {
+			return fmt.Errorf("failed to scan document row: %v", err)
+		}
+		doc.CreatedAt = createdAt.Unix()
+
+		if !variantID.Valid {
+			product.Documents = append(product.Documents, doc)
+		} else if variant, ok := variantMap[variantID.String]; ok {
+			doc.VariantID = &variantID.String
+			variant.Documents = append(variant.Documents, doc)
+		}
+	}
+	return docRows.Err()
+}
@@ -0,0 +1,399 @@
+package productRepository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+)
+
+// recordPriceChange closes the open history entry of the product, or of the
+// variant when variantID is set, and opens a new one at newPrice.
+func recordPriceChange(ctx context.Context, tx *sql.Tx, productID string, variantID *string, oldPrice *float64, newPrice float64, reason, actor string) error {
+	_, err := tx.ExecContext(ctx, `
+        UPDATE price_history
+        SET effective_to = NOW()
+        WHERE product_id = $1
+        AND variant_id IS NOT DISTINCT FROM $2
+        AND effective_to IS NULL
+    `, productID, variantID)
+	if err != nil {
+		return fmt.Errorf("failed to close price history: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO price_history
+        (id, product_id, variant_id, old_price, new_price, reason, actor_id, effective_from)
+        VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NOW())
+    `, uuid.New().String(), productID, variantID, oldPrice, newPrice, reason, actor)
+	if err != nil {
+		return fmt.Errorf("failed to record price history: %v", err)
+	}
+	return nil
+}
+
+// setPrice changes a product price, or a variant price override when
+// variantID is set, and records the change in the price history. A nil price
+// clears a variant override so the variant inherits the product price again.
+func setPrice(ctx context.Context, tx *sql.Tx, productID string, variantID *string, price *float64, reason, actor string) error {
+	var oldPrice float64
+	var err error
+	if variantID == nil {
+		if price == nil {
+			return fmt.Errorf("price is required")
+		}
+		err = tx.QueryRowContext(ctx, `
+            SELECT price FROM products WHERE id = $1 FOR UPDATE
+        `, productID).Scan(&oldPrice)
+	} else {
+		err = tx.QueryRowContext(ctx, `
+            SELECT COALESCE(v.price, p.price)
+            FROM product_variants v
+            JOIN products p ON p.id = v.product_id
+            WHERE v.id = $1 AND v.product_id = $2
+            FOR UPDATE OF v
+        `, *variantID, productID).Scan(&oldPrice)
+	}
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("product not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get price: %v", err)
+	}
+
+	var newPrice float64
+	if variantID == nil {
+		err = tx.QueryRowContext(ctx, `
+            UPDATE products SET price = $1 WHERE id = $2 RETURNING price
+        `, *price, productID).Scan(&newPrice)
+	} else {
+		err = tx.QueryRowContext(ctx, `
+            UPDATE product_variants v
+            SET price = $1
+            FROM products p
+            WHERE v.id = $2 AND p.id = v.product_id
+            RETURNING COALESCE(v.price, p.price)
+        `, price, *variantID).Scan(&newPrice)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update price: %v", err)
+	}
+
+	if newPrice == oldPrice {
+		return nil
+	}
+	return recordPriceChange(ctx, tx, productID, variantID, &oldPrice, newPrice, reason, actor)
+}
+
+// GetPriceHistory returns history newest first. A variant without its own
+// price changes shares the product history, since it inherits that price.
+func (s *Database) GetPriceHistory(ctx context.Context, req *request.GetPriceHistoryRequest) (*response.PriceHistoryResponse, error) {
+	res := &response.PriceHistoryResponse{
+		ProductID: req.ProductID,
+		VariantID: req.VariantID,
+	}
+
+	var variantID *string
+	var err error
+	if req.VariantID == "" {
+		err = s.DB.QueryRowContext(ctx, `
+            SELECT price FROM products WHERE id = $1 AND deleted_at IS NULL
+        `, req.ProductID).Scan(&res.CurrentPrice)
+	} else {
+		variantID = &req.VariantID
+		var ownHistory bool
+		err = s.DB.QueryRowContext(ctx, `
+            SELECT COALESCE(v.price, p.price),
+                   EXISTS (SELECT 1 FROM price_history h WHERE h.variant_id = v.id)
+            FROM product_variants v
+            JOIN products p ON p.id = v.product_id
+            WHERE v.id = $1 AND v.product_id = $2
+            AND v.deleted_at IS NULL AND p.deleted_at IS NULL
+        `, req.VariantID, req.ProductID).Scan(&res.CurrentPrice, &ownHistory)
+		if !ownHistory {
+			variantID = nil
+		}
+	}
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("product not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price: %v", err)
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT id, product_id, variant_id, old_price, new_price, reason,
+               COALESCE(actor_id, ''), effective_from, effective_to
+        FROM price_history
+        WHERE product_id = $1
+        AND variant_id IS NOT DISTINCT FROM $2
+        ORDER BY effective_from DESC
+    `, req.ProductID, variantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price history: %v", err)
+	}
+	defer rows.Close()
+
+	res.History = []*types.PriceHistoryEntry{}
+	for rows.Next() {
+		var e types.PriceHistoryEntry
+		var variant sql.NullString
+		var oldPrice sql.NullFloat64
+		var from time.Time
+		var to sql.NullTime
+		if err := rows.Scan(
+			&e.ID, &e.ProductID, &variant, &oldPrice, &e.NewPrice, &e.Reason,
+			&e.ActorID, &from, &to,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan price history: %v", err)
+		}
+		if variant.Valid {
+			e.VariantID = &variant.String
+		}
+		if oldPrice.Valid {
+			e.OldPrice = &oldPrice.Float64
+		}
+		e.EffectiveFrom = from.Unix()
+		e.EffectiveTo = fromNullTime(to)
+		res.History = append(res.History, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating price history: %v", err)
+	}
+
+	return res, nil
+}
+
+func (s *Database) CreatePriceChange(ctx context.Context, req *request.SchedulePriceChangeRequest) (*types.ScheduledPriceChange, error) {
+	var exists bool
+	err := s.DB.QueryRowContext(ctx, `
+        SELECT EXISTS (
+            SELECT 1 FROM products p
+            LEFT JOIN product_variants v ON v.id = $2 AND v.product_id = p.id AND v.deleted_at IS NULL
+            WHERE p.id = $1 AND p.deleted_at IS NULL
+            AND ($2::text IS NULL OR v.id IS NOT NULL)
+        )
+    `, req.ProductID, req.VariantID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check product: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	// A price change inside a sale window would be undone when the sale
+	// ends and restores its saved price, so overlaps are rejected. Changes
+	// without an end count as a single instant.
+	var overlaps bool
+	err = s.DB.QueryRowContext(ctx, `
+        SELECT EXISTS (
+            SELECT 1 FROM scheduled_price_changes
+            WHERE product_id = $1
+            AND variant_id IS NOT DISTINCT FROM $2
+            AND status IN ('pending', 'active')
+            AND starts_at <= COALESCE($4, $3)
+            AND COALESCE(ends_at, starts_at) >= $3
+        )
+    `, req.ProductID, req.VariantID, time.Unix(req.StartsAt, 0), toNullTime(req.EndsAt)).Scan(&overlaps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check price changes: %v", err)
+	}
+	if overlaps {
+		return nil, fmt.Errorf("overlaps another scheduled price change")
+	}
+
+	change := &types.ScheduledPriceChange{
+		ID:        uuid.New().String(),
+		ProductID: req.ProductID,
+		VariantID: req.VariantID,
+		Price:     req.Price,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		Status:    types.PriceSchedulePending,
+		CreatedBy: req.CreatedBy,
+		CreatedAt: time.Now().Unix(),
+	}
+
+	_, err = s.DB.ExecContext(ctx, `
+        INSERT INTO scheduled_price_changes
+        (id, product_id, variant_id, price, starts_at, ends_at, status, created_by, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+    `, change.ID, change.ProductID, change.VariantID, change.Price,
+		time.Unix(change.StartsAt, 0), toNullTime(change.EndsAt), change.Status, change.CreatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to schedule price change: %v", err)
+	}
+	return change, nil
+}
+
+func (s *Database) ListPriceChanges(ctx context.Context, productID string) (*response.ListPriceChangesResponse, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT id, product_id, variant_id, price, starts_at, ends_at,
+               revert_price, status, created_by, created_at
+        FROM scheduled_price_changes
+        WHERE product_id = $1
+        ORDER BY starts_at DESC
+    `, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price changes: %v", err)
+	}
+	defer rows.Close()
+
+	res := &response.ListPriceChangesResponse{Changes: []*types.ScheduledPriceChange{}}
+	for rows.Next() {
+		change, err := scanPriceChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		res.Changes = append(res.Changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating price changes: %v", err)
+	}
+	return res, nil
+}
+
+func scanPriceChange(row rowScanner) (*types.ScheduledPriceChange, error) {
+	var change types.ScheduledPriceChange
+	var variantID sql.NullString
+	var revertPrice sql.NullFloat64
+	var startsAt, createdAt time.Time
+	var endsAt sql.NullTime
+
+	if err := row.Scan(
+		&change.ID, &change.ProductID, &variantID, &change.Price, &startsAt, &endsAt,
+		&revertPrice, &change.Status, &change.CreatedBy, &createdAt,
+	); err != nil {
+		return nil, fmt.Errorf("failed to scan price change: %v", err)
+	}
+
+	if variantID.Valid {
+		change.VariantID = &variantID.String
+	}
+	if revertPrice.Valid {
+		change.RevertPrice = &revertPrice.Float64
+	}
+	change.StartsAt = startsAt.Unix()
+	change.EndsAt = fromNullTime(endsAt)
+	change.CreatedAt = createdAt.Unix()
+	return &change, nil
+}
+
+func (s *Database) CancelPriceChange(ctx context.Context, req *request.CancelPriceChangeRequest) error {
+	result, err := s.DB.ExecContext(ctx, `
+        UPDATE scheduled_price_changes
+        SET status = $1
+        WHERE id = $2 AND status = $3
+    `, types.PriceScheduleCancelled, req.ID, types.PriceSchedulePending)
+	if err != nil {
+		return fmt.Errorf("failed to cancel price change: %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("pending price change not found")
+	}
+	return nil
+}
+
+// ApplyPriceSchedule ends active price changes whose end has passed,
+// restoring the price they replaced, and starts pending ones that are due.
+func (s *Database) ApplyPriceSchedule(ctx context.Context, now time.Time) (*response.ApplyPriceScheduleResponse, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	res := &response.ApplyPriceScheduleResponse{}
+
+	// Endings run first so that a sale starting as another ends reads the
+	// restored price, not the outgoing sale price, as its revert price.
+	ending, err := lockPriceChanges(ctx, tx, `
+        status = 'active' AND ends_at <= $1
+        ORDER BY ends_at
+    `, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range ending {
+		if err := setPrice(ctx, tx, change.ProductID, change.VariantID, change.RevertPrice, types.PriceChangeRevert, change.CreatedBy); err != nil {
+			return nil, err
+		}
+		_, err = tx.ExecContext(ctx, `
+            UPDATE scheduled_price_changes SET status = $1 WHERE id = $2
+        `, types.PriceScheduleCompleted, change.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update price change: %v", err)
+		}
+		res.Ended++
+	}
+
+	due, err := lockPriceChanges(ctx, tx, `
+        status = 'pending' AND starts_at <= $1
+        ORDER BY starts_at
+    `, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range due {
+		var current sql.NullFloat64
+		if change.VariantID == nil {
+			err = tx.QueryRowContext(ctx, `SELECT price FROM products WHERE id = $1`, change.ProductID).Scan(&current)
+		} else {
+			err = tx.QueryRowContext(ctx, `SELECT price FROM product_variants WHERE id = $1`, *change.VariantID).Scan(&current)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current price: %v", err)
+		}
+
+		price := change.Price
+		if err := setPrice(ctx, tx, change.ProductID, change.VariantID, &price, types.PriceChangeSchedule, change.CreatedBy); err != nil {
+			return nil, err
+		}
+
+		status := types.PriceScheduleCompleted
+		if change.EndsAt != nil {
+			status = types.PriceScheduleActive
+		}
+		_, err = tx.ExecContext(ctx, `
+            UPDATE scheduled_price_changes SET status = $1, revert_price = $2 WHERE id = $3
+        `, status, current, change.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update price change: %v", err)
+		}
+		res.Started++
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return res, nil
+}
+
+func lockPriceChanges(ctx context.Context, tx *sql.Tx, where string, now time.Time) ([]*types.ScheduledPriceChange, error) {
+	rows, err := tx.QueryContext(ctx, `
+        SELECT id, product_id, variant_id, price, starts_at, ends_at,
+               revert_price, status, created_by, created_at
+        FROM scheduled_price_changes
+        WHERE `+where+`
+        FOR UPDATE SKIP LOCKED
+    `, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price changes: %v", err)
+	}
+	defer rows.Close()
+
+	var changes []*types.ScheduledPriceChange
+	for rows.Next() {
+		change, err := scanPriceChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/wafi04/backend/pkg/types"
 	request "github.com/wafi04/backend/pkg/types/req"
 	response "github.com/wafi04/backend/pkg/types/res"
+	"github.com/wafi04/backend/pkg/utils"
 )
 
 type Database struct {
@@ -26,38 +27,63 @@ func NewProductRepository(db *sqlx.DB) ProductRepository {
 }
 
 func (s *Database) CreateProduct(ctx context.Context, req *types.Product) (*types.Product, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	slug, err := utils.GenerateSlug(ctx, tx, "products", types.SlugEntityProduct, req.Name, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 	query := `
     INSERT INTO products  
-    (id, name, sub_title, description, sku, price, category_id, created_at, updated_at)
+    (id, name, slug, sub_title, description, sku, price, category_id, status, publish_at, unpublish_at, created_at, updated_at, product_type, brand_id)
     VALUES 
-    ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-    RETURNING id, name, sub_title, description, sku, price, category_id, created_at, updated_at
+    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+    RETURNING id, name, slug, sub_title, description, sku, price, category_id, status, publish_at, unpublish_at, created_at, updated_at, product_type, brand_id
     `
 
 	var product types.Product
 	var createdAt, updatedAt time.Time
+	var publishAt, unpublishAt sql.NullTime
+	var brandID sql.NullString
 
-	err := s.DB.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		req.ID,
 		req.Name,
+		slug,
 		req.SubTitle,
 		req.Description,
 		req.SKU,
 		req.Price,
 		req.CategoryID,
+		req.Status,
+		toNullTime(req.PublishAt),
+		toNullTime(req.UnpublishAt),
 		now,
 		now,
+		req.Type,
+		req.BrandID,
 	).Scan(
 		&product.ID,
 		&product.Name,
+		&product.Slug,
 		&product.SubTitle,
 		&product.Description,
 		&product.SKU,
 		&product.Price,
 		&product.CategoryID,
+		&product.Status,
+		&publishAt,
+		&unpublishAt,
 		&createdAt,
 		&updatedAt,
+		&product.Type,
+		&brandID,
 	)
 
 	if err != nil {
@@ -65,13 +91,27 @@ func (s *Database) CreateProduct(ctx context.Context, req *types.Product) (*type
 		return nil, fmt.Errorf("failed to insert Product: %v", err)
 	}
 
+	if err := recordPriceChange(ctx, tx, product.ID, nil, nil, product.Price, types.PriceChangeCreated, ""); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	if brandID.Valid {
+		product.BrandID = &brandID.String
+	}
+	product.PublishAt = fromNullTime(publishAt)
+	product.UnpublishAt = fromNullTime(unpublishAt)
 	product.CreatedAt = time.Now().Unix()
 	product.UpdatedAt = time.Now().Unix()
 
 	return &product, nil
 }
+
 func (r *Database) GetProduct(ctx context.Context, req *request.GetProductRequest) (*types.Product, error) {
-	product, err := r.getProductBase(ctx, req.ID)
+	product, err := r.getProductBase(ctx, req.ID, req.PublishedOnly)
 	if err != nil {
 		return nil, err
 	}
@@ -92,26 +132,52 @@ func (r *Database) GetProduct(ctx context.Context, req *request.GetProductReques
 	}
 
 	product.Variants = variants
+
+	if err := r.enrichProductWithMedia(ctx, product); err != nil {
+		return nil, err
+	}
+
+	product.Tags, err = getProductTags(ctx, r.DB, product.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.Type == types.ProductTypeBundle {
+		items, available, err := r.getBundleItems(ctx, product.ID)
+		if err != nil {
+			return nil, err
+		}
+		product.BundleItems = items
+		product.Available = &available
+	}
 	return product, nil
 }
 
-func (r *Database) getProductBase(ctx context.Context, productID string) (*types.Product, error) {
+func (r *Database) getProductBase(ctx context.Context, productID string, publishedOnly bool) (*types.Product, error) {
 	const query = `
         SELECT 
-            id, name, sub_title, description, 
+            id, name, slug, sub_title, description, 
             price, sku, category_id, 
+            status, publish_at, unpublish_at,
+            rating_average, rating_count, product_type, brand_id,
             created_at, updated_at
         FROM products
         WHERE id = $1
+        AND deleted_at IS NULL
+        AND ($2 = false OR (status = 'published'
+            AND (unpublish_at IS NULL OR unpublish_at > NOW())))
     `
 
 	product := &types.Product{}
-	var subTitle sql.NullString
+	var subTitle, brandID sql.NullString
+	var publishAt, unpublishAt sql.NullTime
 	var createdAt, updatedAt time.Time
 
-	err := r.DB.QueryRowContext(ctx, query, productID).Scan(
-		&product.ID, &product.Name, &subTitle, &product.Description,
+	err := r.DB.QueryRowContext(ctx, query, productID, publishedOnly).Scan(
+		&product.ID, &product.Name, &product.Slug, &subTitle, &product.Description,
 		&product.Price, &product.SKU, &product.CategoryID,
+		&product.Status, &publishAt, &unpublishAt,
+		&product.RatingAverage, &product.RatingCount, &product.Type, &brandID,
 		&createdAt, &updatedAt,
 	)
 
@@ -127,6 +193,11 @@ func (r *Database) getProductBase(ctx context.Context, productID string) (*types
 	if subTitle.Valid {
 		product.SubTitle = subTitle.String
 	}
+	if brandID.Valid {
+		product.BrandID = &brandID.String
+	}
+	product.PublishAt = fromNullTime(publishAt)
+	product.UnpublishAt = fromNullTime(unpublishAt)
 	product.CreatedAt = createdAt.Unix()
 	product.UpdatedAt = updatedAt.Unix()
 
@@ -195,6 +266,42 @@ func createVariantMap(variants []*types.ProductVariant) map[string]*types.Produc
 	return variantMap
 }
 
+func toNullTime(unix *int64) sql.NullTime {
+	if unix == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: time.Unix(*unix, 0), Valid: true}
+}
+
+func fromNullTime(t sql.NullTime) *int64 {
+	if !t.Valid {
+		return nil
+	}
+	unix := t.Time.Unix()
+	return &unix
+}
+
+// productSortClauses maps the ListProducts sort keys to ORDER BY clauses.
+// Keys are whitelisted here because the clause is spliced into the query.
+var productSortClauses = map[string]string{
+	types.ProductSortNewest:    "p.created_at DESC",
+	types.ProductSortRating:    "p.rating_average DESC, p.rating_count DESC, p.created_at DESC",
+	types.ProductSortReviews:   "p.rating_count DESC, p.rating_average DESC, p.created_at DESC",
+	types.ProductSortPriceAsc:  "p.price ASC, p.created_at DESC",
+	types.ProductSortPriceDesc: "p.price DESC, p.created_at DESC",
+	types.ProductSortPosition: `(
+        SELECT cp.position FROM collection_products cp
+        WHERE cp.collection_id = $5 AND cp.product_id = p.id
+    ) NULLS LAST, p.created_at DESC`,
+}
+
+func productSortClause(sortBy string) string {
+	if clause, ok := productSortClauses[sortBy]; ok {
+		return clause
+	}
+	return productSortClauses[types.ProductSortNewest]
+}
+
 func (s *Database) ListProducts(ctx context.Context, req *request.ListProductsRequest) (*response.ListProductsResponse, error) {
 	if req.PageToken == "" {
 		req.PageToken = "0"
@@ -204,11 +311,19 @@ func (s *Database) ListProducts(ctx context.Context, req *request.ListProductsRe
         SELECT 
             p.id,
             p.name,
+            p.slug,
             p.sub_title,
             p.description,
             p.price,
             p.sku,
             p.category_id,
+            p.status,
+            p.publish_at,
+            p.unpublish_at,
+            p.rating_average,
+            p.rating_count,
+            p.product_type,
+            p.brand_id,
             p.created_at,
             p.updated_at,
             (
@@ -224,28 +339,89 @@ func (s *Database) ListProducts(ctx context.Context, req *request.ListProductsRe
                                     'id', i.id,
                                     'url', i.url,
                                     'variant_id', i.variant_id,
-                                    'is_main', i.is_main
-                                )
+                                    'is_main', i.is_main,
+                                    'position', i.position,
+                                    'alt_text', i.alt_text
+                                ) ORDER BY i.position
                             ), '[]'::json)
                             FROM product_images i
                             WHERE i.variant_id = v.id
+                            AND i.deleted_at IS NULL
                         )
                     )
                 ), '[]'::json)
                 FROM product_variants v
                 WHERE v.product_id = p.id
-            ) AS variants
+                AND v.deleted_at IS NULL
+            ) AS variants,
+            (
+                SELECT COALESCE(JSON_AGG(
+                    json_build_object('id', t.id, 'name', t.name, 'slug', t.slug)
+                    ORDER BY t.slug
+                ), '[]'::json)
+                FROM product_tags pt
+                JOIN tags t ON t.id = pt.tag_id
+                WHERE pt.product_id = p.id
+            ) AS tags
         FROM 
             products p
-        WHERE 1=1
-        ORDER BY p.created_at DESC
+        WHERE p.deleted_at IS NULL
+        AND ($3 = '' OR p.status = $3)
+        AND ($3 <> 'published' OR p.unpublish_at IS NULL OR p.unpublish_at > NOW())
+        AND ($4 = '' OR p.brand_id = $4)
+        AND ($5 = '' OR EXISTS (
+            SELECT 1 FROM collections c
+            WHERE c.id = $5
+            AND (
+                (c.collection_type = 'manual' AND EXISTS (
+                    SELECT 1 FROM collection_products cp
+                    WHERE cp.collection_id = c.id AND cp.product_id = p.id
+                ))
+                OR (c.collection_type = 'rule'
+                    AND (c.rule_min_price IS NULL OR p.price >= c.rule_min_price)
+                    AND (c.rule_max_price IS NULL OR p.price <= c.rule_max_price)
+                    AND (cardinality(c.rule_category_ids) = 0 OR p.category_id = ANY(c.rule_category_ids))
+                    AND (cardinality(c.rule_tags) = 0 OR EXISTS (
+                        SELECT 1 FROM product_tags pt
+                        JOIN tags t ON t.id = pt.tag_id
+                        WHERE pt.product_id = p.id AND t.slug = ANY(c.rule_tags)
+                    ))
+                )
+            )
+        ))
+        AND (cardinality($6::VARCHAR[]) = 0 OR EXISTS (
+            SELECT 1 FROM product_tags pt
+            JOIN tags t ON t.id = pt.tag_id
+            WHERE pt.product_id = p.id AND t.slug = ANY($6)
+        ))
+        AND ($7 = '' OR p.name ILIKE '%' || $7 || '%'
+            OR p.description ILIKE '%' || $7 || '%'
+            OR EXISTS (
+                SELECT 1 FROM product_tags pt
+                JOIN tags t ON t.id = pt.tag_id
+                WHERE pt.product_id = p.id AND t.name ILIKE '%' || $7 || '%'
+            ))
+        ORDER BY ` + productSortClause(req.SortBy) + `
         LIMIT $1
         OFFSET ($1 * COALESCE(NULLIF($2, ''), '0')::integer)
     `
 
+	// Tag filters match stored slugs, so they are slugified the way
+	// AddProductTags stores them. The list is never nil: a NULL array would
+	// make the filter reject every product instead of being skipped.
+	tags := make([]string, 0, len(req.Tags))
+	for _, tag := range req.Tags {
+		tags = append(tags, utils.Slugify(tag))
+	}
+
 	params := []interface{}{
 		req.PageSize,
 		req.PageToken,
+		req.Status,
+		req.BrandID,
+		req.CollectionID,
+		pq.Array(tags),
+		req.Query,
 	}
 
 	rows, err := s.DB.QueryxContext(ctx, baseQuery, params...)
@@ -259,14 +435,23 @@ func (s *Database) ListProducts(ctx context.Context, req *request.ListProductsRe
 		var product struct {
 			ID          string          `db:"id"`
 			Name        string          `db:"name"`
+			Slug        string          `db:"slug"`
 			SubTitle    sql.NullString  `db:"sub_title"`
 			Description string          `db:"description"`
 			Price       float64         `db:"price"`
 			SKU         string          `db:"sku"`
 			CategoryID  string          `db:"category_id"`
+			Status      string          `db:"status"`
+			PublishAt   sql.NullTime    `db:"publish_at"`
+			UnpublishAt sql.NullTime    `db:"unpublish_at"`
+			RatingAvg   float64         `db:"rating_average"`
+			RatingCount int             `db:"rating_count"`
+			Type        string          `db:"product_type"`
+			BrandID     sql.NullString  `db:"brand_id"`
 			CreatedAt   time.Time       `db:"created_at"`
 			UpdatedAt   time.Time       `db:"updated_at"`
 			Variants    json.RawMessage `db:"variants"`
+			Tags        json.RawMessage `db:"tags"`
 		}
 
 		if err := rows.StructScan(&product); err != nil {
@@ -278,20 +463,36 @@ func (s *Database) ListProducts(ctx context.Context, req *request.ListProductsRe
 			return nil, fmt.Errorf("failed to parse variants: %v", err)
 		}
 
+		var tags []*types.Tag
+		if err := json.Unmarshal(product.Tags, &tags); err != nil {
+			return nil, fmt.Errorf("failed to parse tags: %v", err)
+		}
+
 		pbProduct := &types.Product{
-			ID:          product.ID,
-			Name:        product.Name,
-			Description: product.Description,
-			Price:       product.Price,
-			SKU:         product.SKU,
-			CategoryID:  product.CategoryID,
-			CreatedAt:   product.CreatedAt.Unix(),
-			UpdatedAt:   product.UpdatedAt.Unix(),
-			Variants:    variants,
+			ID:            product.ID,
+			Name:          product.Name,
+			Slug:          product.Slug,
+			Description:   product.Description,
+			Price:         product.Price,
+			SKU:           product.SKU,
+			CategoryID:    product.CategoryID,
+			Type:          product.Type,
+			Status:        product.Status,
+			PublishAt:     fromNullTime(product.PublishAt),
+			UnpublishAt:   fromNullTime(product.UnpublishAt),
+			CreatedAt:     product.CreatedAt.Unix(),
+			UpdatedAt:     product.UpdatedAt.Unix(),
+			Variants:      variants,
+			Tags:          tags,
+			RatingAverage: product.RatingAvg,
+			RatingCount:   product.RatingCount,
 		}
 		if product.SubTitle.Valid {
 			pbProduct.SubTitle = product.SubTitle.String
 		}
+		if product.BrandID.Valid {
+			pbProduct.BrandID = &product.BrandID.String
+		}
 
 		products = append(products, pbProduct)
 	}
@@ -314,6 +515,37 @@ func (s *Database) ListProducts(ctx context.Context, req *request.ListProductsRe
 }
 
 func (s *Database) UpdateProduct(ctx context.Context, req *request.UpdateProductRequest) (*types.Product, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var currentName, currentSlug string
+	var currentPrice float64
+	err = tx.QueryRowContext(ctx, `
+	SELECT name, slug, price FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+	`, req.Product.ID).Scan(&currentName, &currentSlug, &currentPrice)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("product not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %v", err)
+	}
+
+	slug := currentSlug
+	if req.Product.Name != "" && req.Product.Name != currentName {
+		slug, err = utils.GenerateSlug(ctx, tx, "products", types.SlugEntityProduct, req.Product.Name, req.Product.ID)
+		if err != nil {
+			return nil, err
+		}
+		if slug != currentSlug {
+			if err := recordSlugChange(ctx, tx, req.Product.ID, currentSlug, slug); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	var product types.Product
 	query := `
 	UPDATE products
@@ -323,37 +555,54 @@ func (s *Database) UpdateProduct(ctx context.Context, req *request.UpdateProduct
 		description = $3,
 		price = $4,
 		sku = $5,
-		category_id  = $6
-	WHERE id = $7
+		category_id  = $6,
+		slug = $7,
+		brand_id = CASE WHEN $10 THEN NULLIF($9, '') ELSE brand_id END
+	WHERE id = $8 AND deleted_at IS NULL
 	RETURNING 
 		id,
 		name,
+		slug,
 		sub_title,
 		description,
 		price,
 		sku,
 		category_id,
+		status,
+		brand_id,
 		created_at,
 		updated_at
 	`
+	// A nil BrandID keeps the current brand and an empty one clears it.
 	var createdAt, updatedAt time.Time
+	var brandID sql.NullString
+	newBrandID := ""
+	if req.Product.BrandID != nil {
+		newBrandID = *req.Product.BrandID
+	}
 
-	err := s.DB.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		req.Product.Name,
 		req.Product.SubTitle,
 		req.Product.Description,
 		req.Product.Price,
 		req.Product.SKU,
 		req.Product.CategoryID,
+		slug,
 		req.Product.ID,
+		newBrandID,
+		req.Product.BrandID != nil,
 	).Scan(
 		&product.ID,
 		&product.Name,
+		&product.Slug,
 		&product.SubTitle,
 		&product.Description,
 		&product.Price,
 		&product.SKU,
 		&product.CategoryID,
+		&product.Status,
+		&brandID,
 		&createdAt,
 		&updatedAt,
 	)
@@ -361,23 +610,191 @@ func (s *Database) UpdateProduct(ctx context.Context, req *request.UpdateProduct
 	if err != nil {
 		return nil, fmt.Errorf("failed to delete product: %v", err)
 	}
+	if brandID.Valid {
+		product.BrandID = &brandID.String
+	}
+
+	if product.Price != currentPrice {
+		if err := recordPriceChange(ctx, tx, product.ID, nil, &currentPrice, product.Price, types.PriceChangeManual, req.ActorID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
 
 	product.CreatedAt = createdAt.Unix()
 	product.UpdatedAt = updatedAt.Unix()
 	return &product, nil
 }
 
+// DeleteProduct moves a product to the trash together with its variants and
+// images. All rows share the same deleted_at so RestoreFromTrash can bring
+// back exactly what was removed here.
 func (s *Database) DeleteProduct(ctx context.Context, req *request.DeleteProductRequest) (*response.DeleteProductResponse, error) {
-	query := `
-        DELETE FROM products WHERE id = $1;
-    `
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
 
-	_, err := s.DB.ExecContext(ctx, query, req.ID)
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, `
+        UPDATE products SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL
+    `, now, req.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to delete product: %v", err)
 	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE product_images SET deleted_at = $1
+        WHERE deleted_at IS NULL
+        AND variant_id IN (SELECT id FROM product_variants WHERE product_id = $2 AND deleted_at IS NULL)
+    `, now, req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete product images: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE product_variants SET deleted_at = $1 WHERE product_id = $2 AND deleted_at IS NULL
+    `, now, req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete product variants: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
 
 	return &response.DeleteProductResponse{
 		Success: true,
 	}, nil
 }
+
+func (s *Database) UpdateProductStatus(ctx context.Context, req *request.UpdateProductStatusRequest) (*types.Product, error) {
+	query := `
+	UPDATE products
+	SET 
+		status = $1,
+		publish_at = $2,
+		unpublish_at = $3
+	WHERE id = $4 AND deleted_at IS NULL
+	RETURNING 
+		id,
+		name,
+		slug,
+		sub_title,
+		description,
+		price,
+		sku,
+		category_id,
+		status,
+		publish_at,
+		unpublish_at,
+		created_at,
+		updated_at
+	`
+
+	var product types.Product
+	var subTitle sql.NullString
+	var publishAt, unpublishAt sql.NullTime
+	var createdAt, updatedAt time.Time
+
+	err := s.DB.QueryRowContext(ctx, query,
+		req.Status,
+		toNullTime(req.PublishAt),
+		toNullTime(req.UnpublishAt),
+		req.ID,
+	).Scan(
+		&product.ID,
+		&product.Name,
+		&product.Slug,
+		&subTitle,
+		&product.Description,
+		&product.Price,
+		&product.SKU,
+		&product.CategoryID,
+		&product.Status,
+		&publishAt,
+		&unpublishAt,
+		&createdAt,
+		&updatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("product not found")
+	}
+	if err != nil {
+		s.log.Log(logger.ErrorLevel, "Failed to update product status: %v", err)
+		return nil, fmt.Errorf("failed to update product status: %v", err)
+	}
+
+	if subTitle.Valid {
+		product.SubTitle = subTitle.String
+	}
+	product.PublishAt = fromNullTime(publishAt)
+	product.UnpublishAt = fromNullTime(unpublishAt)
+	product.CreatedAt = createdAt.Unix()
+	product.UpdatedAt = updatedAt.Unix()
+	return &product, nil
+}
+
+// ApplyProductSchedule publishes scheduled products whose publish_at has
+// passed and archives published products whose unpublish_at has passed.
+func (s *Database) ApplyProductSchedule(ctx context.Context, now time.Time) (*response.ApplyProductScheduleResponse, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	publishQuery := `
+        UPDATE products
+        SET status = 'published'
+        WHERE status = 'scheduled'
+        AND deleted_at IS NULL
+        AND publish_at IS NOT NULL
+        AND publish_at <= $1
+    `
+	result, err := tx.ExecContext(ctx, publishQuery, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish scheduled products: %v", err)
+	}
+	published, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %v", err)
+	}
+
+	unpublishQuery := `
+        UPDATE products
+        SET status = 'archived'
+        WHERE status = 'published'
+        AND deleted_at IS NULL
+        AND unpublish_at IS NOT NULL
+        AND unpublish_at <= $1
+    `
+	result, err = tx.ExecContext(ctx, unpublishQuery, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpublish expired products: %v", err)
+	}
+	unpublished, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return &response.ApplyProductScheduleResponse{
+		Published:   published,
+		Unpublished: unpublished,
+	}, nil
+}
@@ -0,0 +1,400 @@
+package productRepository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+)
+
+const answerColumns = `
+    a.id, a.question_id, a.user_id, COALESCE(u.name, ''), a.body,
+    a.is_admin, a.verified_buyer, a.status, a.upvotes, a.created_at, a.updated_at
+`
+
+func scanAnswer(row rowScanner) (*types.Answer, error) {
+	var answer types.Answer
+	var createdAt, updatedAt time.Time
+
+	if err := row.Scan(
+		&answer.ID, &answer.QuestionID, &answer.UserID, &answer.UserName, &answer.Body,
+		&answer.IsAdmin, &answer.VerifiedBuyer, &answer.Status, &answer.Upvotes, &createdAt, &updatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	answer.CreatedAt = createdAt.Unix()
+	answer.UpdatedAt = updatedAt.Unix()
+	return &answer, nil
+}
+
+func (s *Database) CreateQuestion(ctx context.Context, req *request.CreateQuestionRequest) (*types.Question, error) {
+	var exists bool
+	err := s.DB.QueryRowContext(ctx, `
+        SELECT EXISTS (SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)
+    `, req.ProductID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check product: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	question := &types.Question{
+		ID:        uuid.New().String(),
+		ProductID: req.ProductID,
+		UserID:    req.UserID,
+		Body:      req.Body,
+		Status:    types.QAStatusPending,
+	}
+
+	var createdAt time.Time
+	err = s.DB.QueryRowContext(ctx, `
+        INSERT INTO product_questions (id, product_id, user_id, body, status, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+        RETURNING created_at
+    `, question.ID, question.ProductID, question.UserID, question.Body, question.Status).Scan(&createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create question: %v", err)
+	}
+
+	question.CreatedAt = createdAt.Unix()
+	question.UpdatedAt = createdAt.Unix()
+	return question, nil
+}
+
+// ListQuestions lists questions newest first, each with its approved answers
+// ordered by upvotes.
+func (s *Database) ListQuestions(ctx context.Context, req *request.ListQuestionsRequest) (*response.ListQuestionsResponse, error) {
+	if req.PageToken == "" {
+		req.PageToken = "0"
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT q.id, q.product_id, q.user_id, COALESCE(u.name, ''), q.body,
+               q.status, q.created_at, q.updated_at
+        FROM product_questions q
+        LEFT JOIN users u ON u.user_id = q.user_id
+        WHERE ($3 = '' OR q.product_id = $3)
+        AND ($4 = '' OR q.status = $4)
+        ORDER BY q.created_at DESC
+        LIMIT $1
+        OFFSET ($1 * COALESCE(NULLIF($2, ''), '0')::integer)
+    `, req.PageSize, req.PageToken, req.ProductID, req.Status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query questions: %v", err)
+	}
+	defer rows.Close()
+
+	questions := []*types.Question{}
+	questionMap := make(map[string]*types.Question)
+	var questionIDs []string
+	for rows.Next() {
+		var q types.Question
+		var createdAt, updatedAt time.Time
+		if err := rows.Scan(
+			&q.ID, &q.ProductID, &q.UserID, &q.UserName, &q.Body,
+			&q.Status, &createdAt, &updatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan question: %v", err)
+		}
+		q.CreatedAt = createdAt.Unix()
+		q.UpdatedAt = updatedAt.Unix()
+
+		questions = append(questions, &q)
+		questionMap[q.ID] = &q
+		questionIDs = append(questionIDs, q.ID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating questions: %v", err)
+	}
+
+	if len(questionIDs) > 0 {
+		answerRows, err := s.DB.QueryContext(ctx, `
+            SELECT `+answerColumns+`
+            FROM product_answers a
+            LEFT JOIN users u ON u.user_id = a.user_id
+            WHERE a.question_id = ANY($1)
+            AND a.status = 'approved'
+            ORDER BY a.upvotes DESC, a.is_admin DESC, a.created_at
+        `, pq.Array(questionIDs))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query answers: %v", err)
+		}
+		defer answerRows.Close()
+
+		for answerRows.Next() {
+			answer, err := scanAnswer(answerRows)
+			if err != nil {
+				return nil, fmt.Errorf("failed to scan answer: %v", err)
+			}
+			if q, ok := questionMap[answer.QuestionID]; ok {
+				q.Answers = append(q.Answers, answer)
+			}
+		}
+		if err = answerRows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating answers: %v", err)
+		}
+	}
+
+	nextPageToken := ""
+	if len(questions) == int(req.PageSize) {
+		currentPage, _ := strconv.Atoi(req.PageToken)
+		nextPageToken = strconv.Itoa(currentPage + 1)
+	}
+
+	return &response.ListQuestionsResponse{
+		Questions:     questions,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+func (s *Database) ModerateQuestion(ctx context.Context, req *request.ModerateQARequest) error {
+	result, err := s.DB.ExecContext(ctx, `
+        UPDATE product_questions SET status = $1, updated_at = NOW() WHERE id = $2
+    `, req.Status, req.ID)
+	if err != nil {
+		return fmt.Errorf("failed to moderate question: %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("question not found")
+	}
+	return nil
+}
+
+// CreateAnswer stores an answer to an approved question. Only admins and
+// verified buyers of the product may answer; admin answers are approved
+// straight away and notify the asker, buyer answers wait for moderation.
+func (s *Database) CreateAnswer(ctx context.Context, req *request.CreateAnswerRequest) (*types.Answer, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var verifiedBuyer bool
+	err = tx.QueryRowContext(ctx, `
+        SELECT EXISTS (
+            SELECT 1 FROM product_reviews r
+            WHERE r.product_id = q.product_id
+            AND r.user_id = $2
+            AND r.verified_buyer
+        )
+        FROM product_questions q
+        WHERE q.id = $1 AND q.status = 'approved'
+    `, req.QuestionID, req.UserID).Scan(&verifiedBuyer)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("question not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get question: %v", err)
+	}
+	if !req.IsAdmin && !verifiedBuyer {
+		return nil, fmt.Errorf("only admins and verified buyers can answer")
+	}
+
+	answer := &types.Answer{
+		ID:            uuid.New().String(),
+		QuestionID:    req.QuestionID,
+		UserID:        req.UserID,
+		Body:          req.Body,
+		IsAdmin:       req.IsAdmin,
+		VerifiedBuyer: verifiedBuyer,
+		Status:        types.QAStatusPending,
+	}
+	if req.IsAdmin {
+		answer.Status = types.QAStatusApproved
+	}
+
+	var createdAt time.Time
+	err = tx.QueryRowContext(ctx, `
+        INSERT INTO product_answers
+        (id, question_id, user_id, body, is_admin, verified_buyer, status, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+        RETURNING created_at
+    `, answer.ID, answer.QuestionID, answer.UserID, answer.Body,
+		answer.IsAdmin, answer.VerifiedBuyer, answer.Status).Scan(&createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create answer: %v", err)
+	}
+	answer.CreatedAt = createdAt.Unix()
+	answer.UpdatedAt = createdAt.Unix()
+
+	if answer.Status == types.QAStatusApproved {
+		if err := notifyQuestionAnswered(ctx, tx, answer.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return answer, nil
+}
+
+func (s *Database) ListAnswers(ctx context.Context, req *request.ListAnswersRequest) (*response.ListAnswersResponse, error) {
+	if req.PageToken == "" {
+		req.PageToken = "0"
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT `+answerColumns+`
+        FROM product_answers a
+        LEFT JOIN users u ON u.user_id = a.user_id
+        WHERE ($3 = '' OR a.status = $3)
+        ORDER BY a.created_at
+        LIMIT $1
+        OFFSET ($1 * COALESCE(NULLIF($2, ''), '0')::integer)
+    `, req.PageSize, req.PageToken, req.Status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query answers: %v", err)
+	}
+	defer rows.Close()
+
+	answers := []*types.Answer{}
+	for rows.Next() {
+		answer, err := scanAnswer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan answer: %v", err)
+		}
+		answers = append(answers, answer)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating answers: %v", err)
+	}
+
+	nextPageToken := ""
+	if len(answers) == int(req.PageSize) {
+		currentPage, _ := strconv.Atoi(req.PageToken)
+		nextPageToken = strconv.Itoa(currentPage + 1)
+	}
+
+	return &response.ListAnswersResponse{
+		Answers:       answers,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+// ModerateAnswer sets an answer's status. The asker is notified the first
+// time an answer to their question is approved.
+func (s *Database) ModerateAnswer(ctx context.Context, req *request.ModerateQARequest) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var previous string
+	err = tx.QueryRowContext(ctx, `
+        SELECT status FROM product_answers WHERE id = $1 FOR UPDATE
+    `, req.ID).Scan(&previous)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("answer not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get answer: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE product_answers SET status = $1, updated_at = NOW() WHERE id = $2
+    `, req.Status, req.ID)
+	if err != nil {
+		return fmt.Errorf("failed to moderate answer: %v", err)
+	}
+
+	if req.Status == types.QAStatusApproved && previous != types.QAStatusApproved {
+		if err := notifyQuestionAnswered(ctx, tx, req.ID); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// notifyQuestionAnswered leaves a notification for the asker of the
+// answered question, unless they answered it themselves or were already
+// notified about this answer.
+func notifyQuestionAnswered(ctx context.Context, tx *sql.Tx, answerID string) error {
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO notifications (id, user_id, type, title, message, entity_id, created_at)
+        SELECT $1, q.user_id, $2, 'Your question was answered', LEFT(a.body, 200), q.id, NOW()
+        FROM product_answers a
+        JOIN product_questions q ON q.id = a.question_id
+        WHERE a.id = $3
+        AND a.user_id <> q.user_id
+        AND NOT a.asker_notified
+    `, uuid.New().String(), types.NotificationQuestionAnswered, answerID)
+	if err != nil {
+		return fmt.Errorf("failed to notify asker: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE product_answers SET asker_notified = true WHERE id = $1
+    `, answerID)
+	if err != nil {
+		return fmt.Errorf("failed to notify asker: %v", err)
+	}
+	return nil
+}
+
+// UpvoteAnswer records one upvote per user on an approved answer.
+func (s *Database) UpvoteAnswer(ctx context.Context, req *request.UpvoteAnswerRequest) (*response.UpvoteAnswerResponse, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, `
+        SELECT EXISTS (SELECT 1 FROM product_answers WHERE id = $1 AND status = 'approved')
+    `, req.AnswerID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get answer: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("answer not found")
+	}
+
+	result, err := tx.ExecContext(ctx, `
+        INSERT INTO answer_upvotes (answer_id, user_id)
+        VALUES ($1, $2)
+        ON CONFLICT DO NOTHING
+    `, req.AnswerID, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record upvote: %v", err)
+	}
+
+	var upvotes int
+	if rows, _ := result.RowsAffected(); rows > 0 {
+		err = tx.QueryRowContext(ctx, `
+            UPDATE product_answers SET upvotes = upvotes + 1 WHERE id = $1 RETURNING upvotes
+        `, req.AnswerID).Scan(&upvotes)
+	} else {
+		err = tx.QueryRowContext(ctx, `
+            SELECT upvotes FROM product_answers WHERE id = $1
+        `, req.AnswerID).Scan(&upvotes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update upvotes: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return &response.UpvoteAnswerResponse{
+		AnswerID: req.AnswerID,
+		Upvotes:  upvotes,
+	}, nil
+}
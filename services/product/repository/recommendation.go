@@ -0,0 +1,309 @@
+package productRepository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+)
+
+// maxSimilarPerProduct caps how many similar products are stored per product.
+const maxSimilarPerProduct = 20
+
+// relatedProductsQuery turns a "candidates" CTE (related_id, score, pinned,
+// position) into product cards. Only live, published products are returned;
+// pinned products come first in merchandiser order, then the rest by score.
+const relatedProductsQuery = `
+    SELECT p.id, p.name, p.slug, p.price, COALESCE(img.url, ''),
+           p.rating_average, c.score, c.pinned
+    FROM (
+        SELECT DISTINCT ON (related_id) related_id, score, pinned, position
+        FROM candidates
+        ORDER BY related_id, pinned DESC
+    ) c
+    JOIN products p ON p.id = c.related_id
+    LEFT JOIN LATERAL (
+        SELECT i.url
+        FROM product_variants v
+        JOIN product_images i ON i.variant_id = v.id AND i.deleted_at IS NULL
+        WHERE v.product_id = p.id AND v.deleted_at IS NULL
+        ORDER BY i.is_main DESC, i.position
+        LIMIT 1
+    ) img ON true
+    WHERE p.deleted_at IS NULL
+    AND p.status = 'published'
+    AND (p.unpublish_at IS NULL OR p.unpublish_at > NOW())
+    ORDER BY c.pinned DESC, c.position, c.score DESC
+    LIMIT $2
+`
+
+func (s *Database) queryRelatedProducts(ctx context.Context, candidates string, args ...interface{}) ([]*types.RelatedProduct, error) {
+	rows, err := s.DB.QueryContext(ctx, "WITH "+candidates+relatedProductsQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query related products: %v", err)
+	}
+	defer rows.Close()
+
+	products := []*types.RelatedProduct{}
+	for rows.Next() {
+		var p types.RelatedProduct
+		if err := rows.Scan(
+			&p.ProductID, &p.Name, &p.Slug, &p.Price, &p.ImageURL,
+			&p.RatingAverage, &p.Score, &p.Pinned,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan related product: %v", err)
+		}
+		products = append(products, &p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating related products: %v", err)
+	}
+	return products, nil
+}
+
+// GetRelatedProducts returns pinned products followed by the precomputed
+// similar products, leaving out anything a merchandiser excluded.
+func (s *Database) GetRelatedProducts(ctx context.Context, req *request.GetRelatedProductsRequest) (*response.RelatedProductsResponse, error) {
+	products, err := s.queryRelatedProducts(ctx, `
+        candidates AS (
+            SELECT related_id, 0::float8 AS score, true AS pinned, position
+            FROM product_recommendation_overrides
+            WHERE product_id = $1 AND type = 'pinned'
+
+            UNION ALL
+
+            SELECT s.related_id, s.score, false, 0
+            FROM product_similarities s
+            WHERE s.product_id = $1
+            AND NOT EXISTS (
+                SELECT 1 FROM product_recommendation_overrides o
+                WHERE o.product_id = s.product_id AND o.related_id = s.related_id
+            )
+        )
+    `, req.ProductID, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.RelatedProductsResponse{Products: products}, nil
+}
+
+// GetCartRecommendations suggests products for the user's cart, weighting
+// products often carted together with the cart contents above merely similar
+// ones. Products already in the cart and products excluded for any cart
+// product are left out.
+func (s *Database) GetCartRecommendations(ctx context.Context, req *request.GetCartRecommendationsRequest) (*response.RelatedProductsResponse, error) {
+	products, err := s.queryRelatedProducts(ctx, `
+        cart_products AS (
+            SELECT DISTINCT pv.product_id
+            FROM carts c
+            JOIN cart_items ci ON ci.cart_id = c.cart_id
+            JOIN product_variants pv ON pv.id = ci.product_variant_id
+            WHERE c.user_id = $1
+        ),
+        scored AS (
+            SELECT related_id, carts * 2.0 AS score
+            FROM product_co_occurrences
+            WHERE product_id IN (SELECT product_id FROM cart_products)
+
+            UNION ALL
+
+            SELECT related_id, score
+            FROM product_similarities
+            WHERE product_id IN (SELECT product_id FROM cart_products)
+        ),
+        all_candidates AS (
+            SELECT related_id, 0::float8 AS score, true AS pinned, MIN(position) AS position
+            FROM product_recommendation_overrides
+            WHERE product_id IN (SELECT product_id FROM cart_products)
+            AND type = 'pinned'
+            GROUP BY related_id
+
+            UNION ALL
+
+            SELECT related_id, SUM(score)::float8, false, 0
+            FROM scored
+            GROUP BY related_id
+        ),
+        candidates AS (
+            SELECT * FROM all_candidates a
+            WHERE a.related_id NOT IN (SELECT product_id FROM cart_products)
+            AND NOT EXISTS (
+                SELECT 1 FROM product_recommendation_overrides o
+                WHERE o.product_id IN (SELECT product_id FROM cart_products)
+                AND o.related_id = a.related_id
+                AND o.type = 'excluded'
+            )
+        )
+    `, req.UserID, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.RelatedProductsResponse{Products: products}, nil
+}
+
+// RecomputeSimilarities rebuilds product_co_occurrences and
+// product_similarities in one transaction, so readers see either the old or
+// the new tables. Carts are the only purchase signal in this schema; pairs
+// score for a shared category, shared colors, similar price and
+// co-occurrence, and only the top maxSimilarPerProduct are kept per product.
+func (s *Database) RecomputeSimilarities(ctx context.Context) (*response.RecomputeSimilaritiesResponse, error) {
+	start := time.Now()
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM product_co_occurrences`); err != nil {
+		return nil, fmt.Errorf("failed to clear co-occurrences: %v", err)
+	}
+	result, err := tx.ExecContext(ctx, `
+        INSERT INTO product_co_occurrences (product_id, related_id, carts)
+        SELECT va.product_id, vb.product_id, COUNT(DISTINCT a.cart_id)
+        FROM cart_items a
+        JOIN cart_items b ON b.cart_id = a.cart_id AND b.cart_item_id <> a.cart_item_id
+        JOIN product_variants va ON va.id = a.product_variant_id
+        JOIN product_variants vb ON vb.id = b.product_variant_id
+        WHERE va.product_id <> vb.product_id
+        GROUP BY va.product_id, vb.product_id
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute co-occurrences: %v", err)
+	}
+	coOccurrences, _ := result.RowsAffected()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM product_similarities`); err != nil {
+		return nil, fmt.Errorf("failed to clear similarities: %v", err)
+	}
+	result, err = tx.ExecContext(ctx, `
+        WITH live AS (
+            SELECT id, category_id, price::float8 AS price
+            FROM products
+            WHERE deleted_at IS NULL AND status = 'published'
+            AND (unpublish_at IS NULL OR unpublish_at > NOW())
+        ),
+        colors AS (
+            SELECT DISTINCT product_id, LOWER(color) AS color
+            FROM product_variants
+            WHERE deleted_at IS NULL
+        ),
+        pairs AS (
+            SELECT
+                a.id AS product_id,
+                b.id AS related_id,
+                CASE WHEN a.category_id = b.category_id THEN 1.0 ELSE 0 END
+                + 0.2 * LEAST((
+                    SELECT COUNT(*) FROM colors ca
+                    JOIN colors cb ON cb.color = ca.color
+                    WHERE ca.product_id = a.id AND cb.product_id = b.id
+                ), 3)
+                + 0.5 * (1 - LEAST(ABS(a.price - b.price) / GREATEST(a.price, b.price, 1), 1))
+                + LN(1 + COALESCE(co.carts, 0)) AS score
+            FROM live a
+            JOIN live b ON b.id <> a.id
+            LEFT JOIN product_co_occurrences co ON co.product_id = a.id AND co.related_id = b.id
+            WHERE a.category_id = b.category_id OR co.carts IS NOT NULL
+        )
+        INSERT INTO product_similarities (product_id, related_id, score, computed_at)
+        SELECT product_id, related_id, score, NOW()
+        FROM (
+            SELECT *, ROW_NUMBER() OVER (PARTITION BY product_id ORDER BY score DESC) AS rank
+            FROM pairs
+        ) ranked
+        WHERE rank <= $1
+    `, maxSimilarPerProduct)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute similarities: %v", err)
+	}
+	similarities, _ := result.RowsAffected()
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return &response.RecomputeSimilaritiesResponse{
+		Similarities:   similarities,
+		CoOccurrences:  coOccurrences,
+		DurationMillis: time.Since(start).Milliseconds(),
+	}, nil
+}
+
+func (s *Database) ListRecommendationOverrides(ctx context.Context, productID string) (*response.ListRecommendationOverridesResponse, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT product_id, related_id, type, position, created_at
+        FROM product_recommendation_overrides
+        WHERE product_id = $1
+        ORDER BY type DESC, position, created_at
+    `, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overrides: %v", err)
+	}
+	defer rows.Close()
+
+	overrides := []*types.RecommendationOverride{}
+	for rows.Next() {
+		var o types.RecommendationOverride
+		var createdAt time.Time
+		if err := rows.Scan(&o.ProductID, &o.RelatedID, &o.Type, &o.Position, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan override: %v", err)
+		}
+		o.CreatedAt = createdAt.Unix()
+		overrides = append(overrides, &o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating overrides: %v", err)
+	}
+
+	return &response.ListRecommendationOverridesResponse{Overrides: overrides}, nil
+}
+
+func (s *Database) SetRecommendationOverride(ctx context.Context, req *request.SetRecommendationOverrideRequest) (*types.RecommendationOverride, error) {
+	var count int
+	err := s.DB.QueryRowContext(ctx, `
+        SELECT COUNT(*) FROM products
+        WHERE id IN ($1, $2) AND deleted_at IS NULL
+    `, req.ProductID, req.RelatedID).Scan(&count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check products: %v", err)
+	}
+	if count != 2 {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	var o types.RecommendationOverride
+	var createdAt time.Time
+	err = s.DB.QueryRowContext(ctx, `
+        INSERT INTO product_recommendation_overrides (product_id, related_id, type, position, created_at)
+        VALUES ($1, $2, $3, $4, NOW())
+        ON CONFLICT (product_id, related_id)
+        DO UPDATE SET type = EXCLUDED.type, position = EXCLUDED.position
+        RETURNING product_id, related_id, type, position, created_at
+    `, req.ProductID, req.RelatedID, req.Type, req.Position).Scan(
+		&o.ProductID, &o.RelatedID, &o.Type, &o.Position, &createdAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set override: %v", err)
+	}
+	o.CreatedAt = createdAt.Unix()
+	return &o, nil
+}
+
+func (s *Database) DeleteRecommendationOverride(ctx context.Context, req *request.DeleteRecommendationOverrideRequest) error {
+	result, err := s.DB.ExecContext(ctx, `
+        DELETE FROM product_recommendation_overrides
+        WHERE product_id = $1 AND related_id = $2
+    `, req.ProductID, req.RelatedID)
+	if err != nil {
+		return fmt.Errorf("failed to delete override: %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("override not found")
+	}
+	return nil
+}
@@ -0,0 +1,285 @@
+package productRepository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+)
+
+const reviewColumns = `
+    r.id, r.product_id, r.user_id, COALESCE(u.name, ''), r.rating,
+    COALESCE(r.title, ''), r.body, r.photos, r.verified_buyer, r.status,
+    COALESCE(r.moderation_note, ''), r.helpful_count, r.created_at, r.updated_at
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanReview(row rowScanner) (*types.Review, error) {
+	var review types.Review
+	var photos pq.StringArray
+	var createdAt, updatedAt time.Time
+
+	if err := row.Scan(
+		&review.ID, &review.ProductID, &review.UserID, &review.UserName, &review.Rating,
+		&review.Title, &review.Body, &photos, &review.VerifiedBuyer, &review.Status,
+		&review.ModerationNote, &review.HelpfulCount, &createdAt, &updatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	review.Photos = photos
+	review.CreatedAt = createdAt.Unix()
+	review.UpdatedAt = updatedAt.Unix()
+	return &review, nil
+}
+
+// CreateReview stores a review in the moderation queue. A user can review a
+// product once; the product rating is only updated when the review is
+// approved.
+func (s *Database) CreateReview(ctx context.Context, req *request.CreateReviewRequest) (*types.Review, error) {
+	if err := s.CheckReviewable(ctx, req.ProductID, req.UserID); err != nil {
+		return nil, err
+	}
+
+	id := uuid.New().String()
+	_, err := s.DB.ExecContext(ctx, `
+        INSERT INTO product_reviews
+        (id, product_id, user_id, rating, title, body, photos, status, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+    `, id, req.ProductID, req.UserID, req.Rating, req.Title, req.Body,
+		pq.Array(req.Photos), types.ReviewStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create review: %v", err)
+	}
+
+	return s.GetReview(ctx, id)
+}
+
+// CheckReviewable reports whether userID may review productID: the product
+// must exist and the user must not have reviewed it yet.
+func (s *Database) CheckReviewable(ctx context.Context, productID, userID string) error {
+	var productExists, reviewed bool
+	err := s.DB.QueryRowContext(ctx, `
+        SELECT
+            EXISTS (SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL),
+            EXISTS (SELECT 1 FROM product_reviews WHERE product_id = $1 AND user_id = $2)
+    `, productID, userID).Scan(&productExists, &reviewed)
+	if err != nil {
+		return fmt.Errorf("failed to check product: %v", err)
+	}
+	if !productExists {
+		return fmt.Errorf("product not found")
+	}
+	if reviewed {
+		return fmt.Errorf("product already reviewed")
+	}
+	return nil
+}
+
+func (s *Database) GetReview(ctx context.Context, id string) (*types.Review, error) {
+	review, err := scanReview(s.DB.QueryRowContext(ctx, `
+        SELECT `+reviewColumns+`
+        FROM product_reviews r
+        LEFT JOIN users u ON u.user_id = r.user_id
+        WHERE r.id = $1
+    `, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("review not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review: %v", err)
+	}
+	return review, nil
+}
+
+// ListReviews lists reviews of a product, or across all products when
+// ProductID is empty, which is how the moderation queue is read.
+func (s *Database) ListReviews(ctx context.Context, req *request.ListReviewsRequest) (*response.ListReviewsResponse, error) {
+	if req.PageToken == "" {
+		req.PageToken = "0"
+	}
+
+	orderBy := "r.created_at DESC"
+	switch req.SortBy {
+	case "helpful":
+		orderBy = "r.helpful_count DESC, r.created_at DESC"
+	case "rating_desc":
+		orderBy = "r.rating DESC, r.created_at DESC"
+	case "rating_asc":
+		orderBy = "r.rating ASC, r.created_at DESC"
+	case "oldest":
+		orderBy = "r.created_at ASC"
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT `+reviewColumns+`
+        FROM product_reviews r
+        LEFT JOIN users u ON u.user_id = r.user_id
+        WHERE ($3 = '' OR r.product_id = $3)
+        AND ($4 = '' OR r.status = $4)
+        ORDER BY `+orderBy+`
+        LIMIT $1
+        OFFSET ($1 * COALESCE(NULLIF($2, ''), '0')::integer)
+    `, req.PageSize, req.PageToken, req.ProductID, req.Status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviews: %v", err)
+	}
+	defer rows.Close()
+
+	reviews := []*types.Review{}
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan review: %v", err)
+		}
+		reviews = append(reviews, review)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviews: %v", err)
+	}
+
+	nextPageToken := ""
+	if len(reviews) == int(req.PageSize) {
+		currentPage, _ := strconv.Atoi(req.PageToken)
+		nextPageToken = strconv.Itoa(currentPage + 1)
+	}
+
+	return &response.ListReviewsResponse{
+		Reviews:       reviews,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+// ModerateReview sets the review status and recomputes the product's
+// denormalized rating from its approved reviews in the same transaction.
+func (s *Database) ModerateReview(ctx context.Context, req *request.ModerateReviewRequest) (*types.Review, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var productID string
+	err = tx.QueryRowContext(ctx, `
+        UPDATE product_reviews
+        SET status = $1,
+            moderation_note = NULLIF($2, ''),
+            verified_buyer = COALESCE($3, verified_buyer),
+            updated_at = NOW()
+        WHERE id = $4
+        RETURNING product_id
+    `, req.Status, req.Note, toNullBool(req.VerifiedBuyer), req.ID).Scan(&productID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("review not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to moderate review: %v", err)
+	}
+
+	if err := refreshProductRating(ctx, tx, productID); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return s.GetReview(ctx, req.ID)
+}
+
+func refreshProductRating(ctx context.Context, tx *sql.Tx, productID string) error {
+	_, err := tx.ExecContext(ctx, `
+        UPDATE products p
+        SET rating_average = agg.average,
+            rating_count = agg.count
+        FROM (
+            SELECT
+                COALESCE(ROUND(AVG(rating)::numeric, 2), 0) AS average,
+                COUNT(*) AS count
+            FROM product_reviews
+            WHERE product_id = $1 AND status = 'approved'
+        ) agg
+        WHERE p.id = $1
+    `, productID)
+	if err != nil {
+		return fmt.Errorf("failed to update product rating: %v", err)
+	}
+	return nil
+}
+
+// MarkReviewHelpful records a helpful vote. Votes are unique per user, so
+// repeating the call leaves the count unchanged.
+func (s *Database) MarkReviewHelpful(ctx context.Context, req *request.MarkReviewHelpfulRequest) (*response.MarkReviewHelpfulResponse, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var authorID string
+	err = tx.QueryRowContext(ctx, `
+        SELECT user_id FROM product_reviews
+        WHERE id = $1 AND status = 'approved'
+    `, req.ReviewID).Scan(&authorID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("review not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review: %v", err)
+	}
+	if authorID == req.UserID {
+		return nil, fmt.Errorf("cannot vote on your own review")
+	}
+
+	result, err := tx.ExecContext(ctx, `
+        INSERT INTO review_helpful_votes (review_id, user_id)
+        VALUES ($1, $2)
+        ON CONFLICT DO NOTHING
+    `, req.ReviewID, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record vote: %v", err)
+	}
+
+	var count int
+	if rows, _ := result.RowsAffected(); rows > 0 {
+		err = tx.QueryRowContext(ctx, `
+            UPDATE product_reviews
+            SET helpful_count = helpful_count + 1
+            WHERE id = $1
+            RETURNING helpful_count
+        `, req.ReviewID).Scan(&count)
+	} else {
+		err = tx.QueryRowContext(ctx, `
+            SELECT helpful_count FROM product_reviews WHERE id = $1
+        `, req.ReviewID).Scan(&count)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update helpful count: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return &response.MarkReviewHelpfulResponse{
+		ReviewID:     req.ReviewID,
+		HelpfulCount: count,
+	}, nil
+}
+
+func toNullBool(b *bool) sql.NullBool {
+	if b == nil {
+		return sql.NullBool{}
+	}
+	return sql.NullBool{Bool: *b, Valid: true}
+}
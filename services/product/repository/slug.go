@@ -0,0 +1,68 @@
+package productRepository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+)
+
+// recordSlugChange keeps oldSlug as a redirect to the product and drops any
+// redirect that newSlug used to be, e.g. when a product is renamed back.
+func recordSlugChange(ctx context.Context, tx *sql.Tx, productID, oldSlug, newSlug string) error {
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO slug_redirects (entity_type, slug, entity_id)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (entity_type, slug) DO UPDATE
+        SET entity_id = EXCLUDED.entity_id, created_at = CURRENT_TIMESTAMP
+    `, types.SlugEntityProduct, oldSlug, productID)
+	if err != nil {
+		return fmt.Errorf("failed to record slug redirect: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        DELETE FROM slug_redirects WHERE entity_type = $1 AND slug = $2
+    `, types.SlugEntityProduct, newSlug)
+	if err != nil {
+		return fmt.Errorf("failed to clear slug redirect: %v", err)
+	}
+	return nil
+}
+
+func (r *Database) GetProductBySlug(ctx context.Context, req *request.GetProductBySlugRequest) (*response.ProductBySlugResponse, error) {
+	var productID string
+	redirected := false
+
+	err := r.DB.QueryRowContext(ctx, `
+        SELECT id FROM products WHERE slug = $1 AND deleted_at IS NULL
+    `, req.Slug).Scan(&productID)
+	if err == sql.ErrNoRows {
+		err = r.DB.QueryRowContext(ctx, `
+            SELECT entity_id FROM slug_redirects WHERE entity_type = $1 AND slug = $2
+        `, types.SlugEntityProduct, req.Slug).Scan(&productID)
+		redirected = true
+	}
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("product not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve slug: %v", err)
+	}
+
+	product, err := r.GetProduct(ctx, &request.GetProductRequest{
+		ID:            productID,
+		PublishedOnly: req.PublishedOnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.ProductBySlugResponse{
+		Product:       product,
+		CanonicalSlug: product.Slug,
+		Redirected:    redirected,
+	}, nil
+}
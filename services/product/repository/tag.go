@@ -0,0 +1,154 @@
+package productRepository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+	"github.com/wafi04/backend/pkg/utils"
+)
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// AddProductTags attaches tags to a product, creating the tag entities that
+// do not exist yet. Tags are matched on their slug, so "Summer" and
+// "summer" are the same tag.
+func (r *Database) AddProductTags(ctx context.Context, req *request.AddProductTagsRequest) (*response.ProductTagsResponse, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, `
+        SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)
+    `, req.ProductID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check product: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	for _, name := range req.Tags {
+		var tagID string
+		err := tx.QueryRowContext(ctx, `
+            INSERT INTO tags (id, name, slug)
+            VALUES ($1, $2, $3)
+            ON CONFLICT (slug) DO UPDATE SET slug = EXCLUDED.slug
+            RETURNING id
+        `, uuid.New().String(), name, utils.Slugify(name)).Scan(&tagID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save tag: %v", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+            INSERT INTO product_tags (product_id, tag_id)
+            VALUES ($1, $2)
+            ON CONFLICT DO NOTHING
+        `, req.ProductID, tagID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to tag product: %v", err)
+		}
+	}
+
+	tags, err := getProductTags(ctx, tx, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return &response.ProductTagsResponse{ProductID: req.ProductID, Tags: tags}, nil
+}
+
+// RemoveProductTag detaches a tag from a product. The tag entity is kept
+// even when no product uses it any more.
+func (r *Database) RemoveProductTag(ctx context.Context, req *request.RemoveProductTagRequest) error {
+	result, err := r.DB.ExecContext(ctx, `
+        DELETE FROM product_tags pt
+        USING tags t
+        WHERE t.id = pt.tag_id AND pt.product_id = $1 AND t.slug = $2
+    `, req.ProductID, req.Slug)
+	if err != nil {
+		return fmt.Errorf("failed to remove tag: %v", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("tag not found on product")
+	}
+	return nil
+}
+
+// ListTags returns every tag in use with the number of products carrying
+// it, most used first.
+func (r *Database) ListTags(ctx context.Context, req *request.ListTagsRequest) (*response.ListTagsResponse, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+        SELECT t.id, t.name, t.slug, COUNT(*) AS product_count
+        FROM tags t
+        JOIN product_tags pt ON pt.tag_id = t.id
+        JOIN products p ON p.id = pt.product_id
+        WHERE p.deleted_at IS NULL
+        AND ($1 = false OR (p.status = 'published'
+            AND (p.unpublish_at IS NULL OR p.unpublish_at > NOW())))
+        GROUP BY t.id, t.name, t.slug
+        ORDER BY product_count DESC, t.slug
+    `, req.PublishedOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %v", err)
+	}
+	defer rows.Close()
+
+	tags := make([]*types.Tag, 0)
+	for rows.Next() {
+		var tag types.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Slug, &tag.ProductCount); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %v", err)
+		}
+		tags = append(tags, &tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %v", err)
+	}
+
+	return &response.ListTagsResponse{Tags: tags}, nil
+}
+
+func getProductTags(ctx context.Context, q queryer, productID string) ([]*types.Tag, error) {
+	rows, err := q.QueryContext(ctx, `
+        SELECT t.id, t.name, t.slug
+        FROM product_tags pt
+        JOIN tags t ON t.id = pt.tag_id
+        WHERE pt.product_id = $1
+        ORDER BY t.slug
+    `, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product tags: %v", err)
+	}
+	defer rows.Close()
+
+	tags := make([]*types.Tag, 0)
+	for rows.Next() {
+		var tag types.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Slug); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %v", err)
+		}
+		tags = append(tags, &tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating product tags: %v", err)
+	}
+	return tags, nil
+}
@@ -0,0 +1,252 @@
+package productRepository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/wafi04/backend/pkg/logger"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+)
+
+func (s *Database) ListTrash(ctx context.Context, req *request.ListTrashRequest) (*response.ListTrashResponse, error) {
+	var query string
+	switch req.Type {
+	case types.TrashTypeProduct:
+		query = `
+        SELECT id, name, NULL, deleted_at
+        FROM products
+        WHERE deleted_at IS NOT NULL
+        ORDER BY deleted_at DESC
+        `
+	case types.TrashTypeVariant:
+		query = `
+        SELECT id, color, product_id, deleted_at
+        FROM product_variants
+        WHERE deleted_at IS NOT NULL
+        ORDER BY deleted_at DESC
+        `
+	case types.TrashTypeImage:
+		query = `
+        SELECT id, url, variant_id, deleted_at
+        FROM product_images
+        WHERE deleted_at IS NOT NULL
+        ORDER BY deleted_at DESC
+        `
+	default:
+		return nil, fmt.Errorf("invalid trash type: %s", req.Type)
+	}
+
+	rows, err := s.DB.QueryContext(ctx, query)
+	if err != nil {
+		s.log.Log(logger.ErrorLevel, "Failed to list trash: %v", err)
+		return nil, fmt.Errorf("failed to list trash: %v", err)
+	}
+	defer rows.Close()
+
+	items := make([]*types.TrashItem, 0)
+	for rows.Next() {
+		item := &types.TrashItem{Type: req.Type}
+		var parentID sql.NullString
+		if err := rows.Scan(&item.ID, &item.Name, &parentID, &item.DeletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan trash item: %v", err)
+		}
+		if parentID.Valid {
+			item.ParentID = &parentID.String
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating trash: %v", err)
+	}
+
+	return &response.ListTrashResponse{
+		Items: items,
+	}, nil
+}
+
+// RestoreFromTrash restores a deleted product, variant or image. Children
+// that were deleted together with the restored row come back with it.
+func (s *Database) RestoreFromTrash(ctx context.Context, req *request.RestoreTrashRequest) (*response.RestoreTrashResponse, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var restored int64
+	switch req.Type {
+	case types.TrashTypeProduct:
+		restored, err = restoreProduct(ctx, tx, req.ID)
+	case types.TrashTypeVariant:
+		restored, err = restoreVariant(ctx, tx, req.ID)
+	case types.TrashTypeImage:
+		restored, err = restoreImage(ctx, tx, req.ID)
+	default:
+		return nil, fmt.Errorf("invalid trash type: %s", req.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return &response.RestoreTrashResponse{
+		Success:       true,
+		RestoredCount: restored,
+	}, nil
+}
+
+func restoreProduct(ctx context.Context, tx *sql.Tx, id string) (int64, error) {
+	var deletedAt time.Time
+	err := tx.QueryRowContext(ctx, `
+        SELECT deleted_at FROM products
+        WHERE id = $1 AND deleted_at IS NOT NULL
+        FOR UPDATE
+    `, id).Scan(&deletedAt)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("product not found in trash")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to check product: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE products SET deleted_at = NULL WHERE id = $1`, id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to restore product: %v", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+        UPDATE product_variants SET deleted_at = NULL
+        WHERE product_id = $1 AND deleted_at = $2
+    `, id, deletedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to restore product variants: %v", err)
+	}
+	variants, _ := result.RowsAffected()
+
+	result, err = tx.ExecContext(ctx, `
+        UPDATE product_images SET deleted_at = NULL
+        WHERE deleted_at = $2
+        AND variant_id IN (SELECT id FROM product_variants WHERE product_id = $1)
+    `, id, deletedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to restore product images: %v", err)
+	}
+	images, _ := result.RowsAffected()
+
+	return 1 + variants + images, nil
+}
+
+func restoreVariant(ctx context.Context, tx *sql.Tx, id string) (int64, error) {
+	var deletedAt time.Time
+	var productDeleted bool
+	err := tx.QueryRowContext(ctx, `
+        SELECT v.deleted_at, p.deleted_at IS NOT NULL
+        FROM product_variants v
+        JOIN products p ON p.id = v.product_id
+        WHERE v.id = $1 AND v.deleted_at IS NOT NULL
+        FOR UPDATE OF v
+    `, id).Scan(&deletedAt, &productDeleted)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("variant not found in trash")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to check variant: %v", err)
+	}
+	if productDeleted {
+		return 0, fmt.Errorf("cannot restore variant of a deleted product, restore the product first")
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE product_variants SET deleted_at = NULL WHERE id = $1`, id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to restore variant: %v", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+        UPDATE product_images SET deleted_at = NULL
+        WHERE variant_id = $1 AND deleted_at = $2
+    `, id, deletedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to restore variant images: %v", err)
+	}
+	images, _ := result.RowsAffected()
+
+	return 1 + images, nil
+}
+
+func restoreImage(ctx context.Context, tx *sql.Tx, id string) (int64, error) {
+	var variantDeleted bool
+	err := tx.QueryRowContext(ctx, `
+        SELECT v.deleted_at IS NOT NULL
+        FROM product_images i
+        JOIN product_variants v ON v.id = i.variant_id
+        WHERE i.id = $1 AND i.deleted_at IS NOT NULL
+    `, id).Scan(&variantDeleted)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("image not found in trash")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to check image: %v", err)
+	}
+	if variantDeleted {
+		return 0, fmt.Errorf("cannot restore image of a deleted variant, restore the variant first")
+	}
+
+	// The restored image goes last and only becomes main when the variant
+	// has none.
+	_, err = tx.ExecContext(ctx, `
+        UPDATE product_images i
+        SET deleted_at = NULL,
+            is_main = NOT EXISTS (
+                SELECT 1 FROM product_images o
+                WHERE o.variant_id = i.variant_id AND o.is_main AND o.deleted_at IS NULL
+            ),
+            position = (
+                SELECT COALESCE(MAX(o.position) + 1, 0) FROM product_images o
+                WHERE o.variant_id = i.variant_id AND o.deleted_at IS NULL
+            )
+        WHERE i.id = $1`, id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to restore image: %v", err)
+	}
+	return 1, nil
+}
+
+// PurgeTrash permanently removes images, variants and products that have
+// been in the trash since before the given time.
+func (s *Database) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var purged int64
+	for _, query := range []string{
+		`DELETE FROM product_images WHERE deleted_at IS NOT NULL AND deleted_at < $1`,
+		`DELETE FROM product_variants WHERE deleted_at IS NOT NULL AND deleted_at < $1`,
+		`DELETE FROM products WHERE deleted_at IS NOT NULL AND deleted_at < $1`,
+	} {
+		result, err := tx.ExecContext(ctx, query, before)
+		if err != nil {
+			return 0, fmt.Errorf("failed to purge trash: %v", err)
+		}
+		count, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get affected rows: %v", err)
+		}
+		purged += count
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return purged, nil
+}
@@ -2,6 +2,7 @@ package productRepository
 
 import (
 	"context"
+	"time"
 
 	"github.com/wafi04/backend/pkg/types"
 	request "github.com/wafi04/backend/pkg/types/req"
@@ -12,9 +13,66 @@ type ProductRepository interface {
 	// product
 	CreateProduct(ctx context.Context, req *types.Product) (*types.Product, error)
 	GetProduct(ctx context.Context, req *request.GetProductRequest) (*types.Product, error)
+	GetProductBySlug(ctx context.Context, req *request.GetProductBySlugRequest) (*response.ProductBySlugResponse, error)
 	ListProducts(ctx context.Context, req *request.ListProductsRequest) (*response.ListProductsResponse, error)
 	UpdateProduct(ctx context.Context, req *request.UpdateProductRequest) (*types.Product, error)
 	DeleteProduct(ctx context.Context, req *request.DeleteProductRequest) (*response.DeleteProductResponse, error)
+	UpdateProductStatus(ctx context.Context, req *request.UpdateProductStatusRequest) (*types.Product, error)
+	ApplyProductSchedule(ctx context.Context, now time.Time) (*response.ApplyProductScheduleResponse, error)
+
+	// trash
+	ListTrash(ctx context.Context, req *request.ListTrashRequest) (*response.ListTrashResponse, error)
+	RestoreFromTrash(ctx context.Context, req *request.RestoreTrashRequest) (*response.RestoreTrashResponse, error)
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+
+	// reviews
+	CreateReview(ctx context.Context, req *request.CreateReviewRequest) (*types.Review, error)
+	CheckReviewable(ctx context.Context, productID, userID string) error
+	GetReview(ctx context.Context, id string) (*types.Review, error)
+	ListReviews(ctx context.Context, req *request.ListReviewsRequest) (*response.ListReviewsResponse, error)
+	ModerateReview(ctx context.Context, req *request.ModerateReviewRequest) (*types.Review, error)
+	MarkReviewHelpful(ctx context.Context, req *request.MarkReviewHelpfulRequest) (*response.MarkReviewHelpfulResponse, error)
+
+	// questions and answers
+	CreateQuestion(ctx context.Context, req *request.CreateQuestionRequest) (*types.Question, error)
+	ListQuestions(ctx context.Context, req *request.ListQuestionsRequest) (*response.ListQuestionsResponse, error)
+	ModerateQuestion(ctx context.Context, req *request.ModerateQARequest) error
+	CreateAnswer(ctx context.Context, req *request.CreateAnswerRequest) (*types.Answer, error)
+	ListAnswers(ctx context.Context, req *request.ListAnswersRequest) (*response.ListAnswersResponse, error)
+	ModerateAnswer(ctx context.Context, req *request.ModerateQARequest) error
+	UpvoteAnswer(ctx context.Context, req *request.UpvoteAnswerRequest) (*response.UpvoteAnswerResponse, error)
+
+	// recommendations
+	GetRelatedProducts(ctx context.Context, req *request.GetRelatedProductsRequest) (*response.RelatedProductsResponse, error)
+	GetCartRecommendations(ctx context.Context, req *request.GetCartRecommendationsRequest) (*response.RelatedProductsResponse, error)
+	RecomputeSimilarities(ctx context.Context) (*response.RecomputeSimilaritiesResponse, error)
+	ListRecommendationOverrides(ctx context.Context, productID string) (*response.ListRecommendationOverridesResponse, error)
+	SetRecommendationOverride(ctx context.Context, req *request.SetRecommendationOverrideRequest) (*types.RecommendationOverride, error)
+	DeleteRecommendationOverride(ctx context.Context, req *request.DeleteRecommendationOverrideRequest) error
+
+	// prices
+	GetPriceHistory(ctx context.Context, req *request.GetPriceHistoryRequest) (*response.PriceHistoryResponse, error)
+	CreatePriceChange(ctx context.Context, req *request.SchedulePriceChangeRequest) (*types.ScheduledPriceChange, error)
+	ListPriceChanges(ctx context.Context, productID string) (*response.ListPriceChangesResponse, error)
+	CancelPriceChange(ctx context.Context, req *request.CancelPriceChangeRequest) error
+	ApplyPriceSchedule(ctx context.Context, now time.Time) (*response.ApplyPriceScheduleResponse, error)
+
+	// tags
+	AddProductTags(ctx context.Context, req *request.AddProductTagsRequest) (*response.ProductTagsResponse, error)
+	RemoveProductTag(ctx context.Context, req *request.RemoveProductTagRequest) error
+	ListTags(ctx context.Context, req *request.ListTagsRequest) (*response.ListTagsResponse, error)
+
+	// bundles
+	SetBundleItems(ctx context.Context, req *request.SetBundleItemsRequest) ([]*types.BundleItem, error)
+
+	// catalog import / export
+	UpsertCatalogProduct(ctx context.Context, p *types.CatalogProduct, dryRun bool) (bool, error)
+	GetCatalogProductStatus(ctx context.Context, sku string) (string, error)
+	ExistingCategoryIDs(ctx context.Context, ids []string) (map[string]bool, error)
+	ExportCatalog(ctx context.Context) ([]*types.CatalogProduct, error)
+	CreateImportJob(ctx context.Context, job *types.ImportJob) error
+	UpdateImportJob(ctx context.Context, job *types.ImportJob) error
+	GetImportJob(ctx context.Context, id string) (*types.ImportJob, error)
 
 	// variant
 	CreateProductVariant(ctx context.Context, req *request.CreateProductVariantRequest) (*types.ProductVariant, error)
@@ -26,4 +84,14 @@ type ProductRepository interface {
 	AddProductImage(ctx context.Context, req *request.AddProductImageRequest) (*types.ProductImage, error)
 	UpdateProductImage(ctx context.Context, req *request.UpdateProductImageRequest) (*types.ProductImage, error)
 	DeleteProductImage(ctx context.Context, req *request.DeleteProductImageRequest) (*response.DeleteProductResponse, error)
+	AddProductImages(ctx context.Context, variantID string, reqs []*request.AddProductImageRequest) ([]*types.ProductImage, error)
+	SetMainProductImage(ctx context.Context, req *request.SetMainProductImageRequest) (*types.ProductImage, error)
+	ReorderProductImages(ctx context.Context, req *request.ReorderProductImagesRequest) error
+	SaveImageDerivatives(ctx context.Context, imageID string, derivatives []*types.ImageDerivative) error
+
+	// videos and documents
+	AddProductVideo(ctx context.Context, req *request.AddProductVideoRequest) (*types.ProductVideo, error)
+	DeleteProductVideo(ctx context.Context, req *request.DeleteProductMediaRequest) error
+	AddProductDocument(ctx context.Context, req *request.AddProductDocumentRequest) (*types.ProductDocument, error)
+	DeleteProductDocument(ctx context.Context, req *request.DeleteProductMediaRequest) error
 }
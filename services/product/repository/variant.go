@@ -2,8 +2,10 @@ package productRepository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -45,7 +47,7 @@ func (pr *Database) UpdateProductVariant(ctx context.Context, req *request.Updat
 	query := `
         UPDATE product_variants
         SET color = $1, sku = $2
-        WHERE id = $3
+        WHERE id = $3 AND deleted_at IS NULL
         RETURNING id, color, sku, product_id
     `
 
@@ -66,16 +68,39 @@ func (pr *Database) UpdateProductVariant(ctx context.Context, req *request.Updat
 }
 
 func (pr *Database) DeleteProductVariant(ctx context.Context, req *request.DeleteProductVariantRequest) (*response.DeleteProductResponse, error) {
-	query := `
-	DELETE FROM product_variants WHERE id = $1
-	`
-
-	_, err := pr.DB.ExecContext(ctx, query, req.ID)
+	tx, err := pr.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
 
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, `
+	UPDATE product_variants SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL
+	`, now, req.ID)
 	if err != nil {
 		pr.log.Error("Failed to Delete Variants : %v", err)
 		return nil, err
 	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("variant not found")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+	UPDATE product_images SET deleted_at = $1 WHERE variant_id = $2 AND deleted_at IS NULL
+	`, now, req.ID)
+	if err != nil {
+		pr.log.Error("Failed to Delete Variant images : %v", err)
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
 
 	return &response.DeleteProductResponse{
 		Success: true,
@@ -89,7 +114,7 @@ func (pr *Database) GetProductsVariant(ctx context.Context, req *request.GetProd
 	query := `
 		SELECT id,color,sku,product_id
 		FROM product_variants
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 	err := pr.DB.QueryRowContext(ctx, query, req.VariantID).Scan(
 		&variants.ID,
@@ -110,7 +135,7 @@ func (pr *Database) GetProductVariants(ctx context.Context, req *request.GetProd
     SELECT 
         id, color, sku, product_id
     FROM product_variants 
-    WHERE product_id = $1
+    WHERE product_id = $1 AND deleted_at IS NULL
     `
 	rows, err := pr.DB.QueryContext(ctx, query, req.ProductID)
 	if err != nil {
@@ -145,9 +170,10 @@ func (pr *Database) GetProductVariants(ctx context.Context, req *request.GetProd
 
 func (r *Database) getProductVariants(ctx context.Context, productID string) ([]*types.ProductVariant, error) {
 	const query = `
-        SELECT id, color, sku
+        SELECT id, color, sku, price
         FROM product_variants
         WHERE product_id = $1
+        AND deleted_at IS NULL
     `
 
 	rows, err := r.DB.QueryContext(ctx, query, productID)
@@ -160,12 +186,16 @@ func (r *Database) getProductVariants(ctx context.Context, productID string) ([]
 	var variants []*types.ProductVariant
 	for rows.Next() {
 		var variant types.ProductVariant
-		err := rows.Scan(&variant.ID, &variant.Color, &variant.SKU)
+		var price sql.NullFloat64
+		err := rows.Scan(&variant.ID, &variant.Color, &variant.SKU, &price)
 		if err != nil {
 			r.log.Log(logger.ErrorLevel, "Failed to scan variant row: %v", err)
 			return nil, fmt.Errorf("failed to scan variant row")
 		}
 
+		if price.Valid {
+			variant.Price = &price.Float64
+		}
 		variant.ProductID = productID
 		variant.Images = make([]*types.ProductImage, 0)
 		variant.Inventory = make([]*types.Inventory, 0)
@@ -0,0 +1,34 @@
+package productservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wafi04/backend/pkg/logger"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+)
+
+func (h *ProductService) SetBundleItems(ctx context.Context, req *request.SetBundleItemsRequest) ([]*types.BundleItem, error) {
+	h.log.Log(logger.InfoLevel, "Incoming Request Set Bundle Items %s", req.BundleID)
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("a bundle needs at least one item")
+	}
+
+	seen := make(map[string]bool, len(req.Items))
+	for _, item := range req.Items {
+		if item.VariantID == "" || item.Size == "" {
+			return nil, fmt.Errorf("variant_id and size are required for every item")
+		}
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("quantity must be greater than zero")
+		}
+		key := item.VariantID + "/" + item.Size
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate bundle item %s", key)
+		}
+		seen[key] = true
+	}
+
+	return h.productrepo.SetBundleItems(ctx, req)
+}
@@ -0,0 +1,432 @@
+package productservice
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wafi04/backend/pkg/logger"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	"github.com/wafi04/backend/pkg/utils"
+)
+
+// catalogColumns is the CSV layout used by both import and export. Each row
+// is one inventory line; product and variant columns repeat across rows and
+// images are separated by "|". publish_at and unpublish_at are Unix seconds.
+var catalogColumns = []string{
+	"product_sku", "name", "sub_title", "description", "price", "category_id", "status",
+	"publish_at", "unpublish_at", "variant_sku", "color", "images", "size", "stock",
+}
+
+// importProgressEvery is how many products are processed between progress
+// updates of an import job.
+const importProgressEvery = 50
+
+// ParseCatalog reads products in the given format. Rows that cannot be parsed
+// are returned as row errors and their product is left out entirely; the
+// error return is reserved for unreadable input.
+func ParseCatalog(format string, r io.Reader) ([]*types.CatalogProduct, []types.ImportRowError, error) {
+	switch format {
+	case types.CatalogFormatCSV:
+		return parseCatalogCSV(r)
+	case types.CatalogFormatJSON:
+		return parseCatalogJSON(r)
+	default:
+		return nil, nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+func parseCatalogJSON(r io.Reader) ([]*types.CatalogProduct, []types.ImportRowError, error) {
+	var products []*types.CatalogProduct
+	if err := json.NewDecoder(r).Decode(&products); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode json: %v", err)
+	}
+	for i, p := range products {
+		p.Row = i + 1
+	}
+	return products, nil, nil
+}
+
+func parseCatalogCSV(r io.Reader) ([]*types.CatalogProduct, []types.ImportRowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read csv header: %v", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"product_sku", "name"} {
+		if _, ok := index[name]; !ok {
+			return nil, nil, fmt.Errorf("missing csv column: %s", name)
+		}
+	}
+
+	var (
+		products []*types.CatalogProduct
+		errs     []types.ImportRowError
+		bySKU    = make(map[string]*types.CatalogProduct)
+		failed   = make(map[string]bool)
+	)
+	fail := func(row int, sku, message string) {
+		errs = append(errs, types.ImportRowError{Row: row, SKU: sku, Message: message})
+		failed[sku] = true
+	}
+
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		// A row that cannot be read may belong to any product, so nothing
+		// in the file can be imported safely.
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read csv row %d: %v", row, err)
+		}
+
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		sku := field("product_sku")
+		if failed[sku] {
+			continue
+		}
+		product, ok := bySKU[sku]
+		if !ok {
+			price, err := strconv.ParseFloat(field("price"), 64)
+			if err != nil {
+				fail(row, sku, "invalid price")
+				continue
+			}
+			publishAt, err := parseCatalogTime(field("publish_at"))
+			if err != nil {
+				fail(row, sku, "invalid publish_at")
+				continue
+			}
+			unpublishAt, err := parseCatalogTime(field("unpublish_at"))
+			if err != nil {
+				fail(row, sku, "invalid unpublish_at")
+				continue
+			}
+			product = &types.CatalogProduct{
+				SKU:         sku,
+				Name:        field("name"),
+				SubTitle:    field("sub_title"),
+				Description: field("description"),
+				Price:       price,
+				CategoryID:  field("category_id"),
+				Status:      field("status"),
+				PublishAt:   publishAt,
+				UnpublishAt: unpublishAt,
+				Row:         row,
+			}
+			bySKU[sku] = product
+			products = append(products, product)
+		}
+
+		variantSKU := field("variant_sku")
+		if variantSKU == "" {
+			continue
+		}
+		var variant *types.CatalogVariant
+		for _, v := range product.Variants {
+			if v.SKU == variantSKU {
+				variant = v
+				break
+			}
+		}
+		if variant == nil {
+			variant = &types.CatalogVariant{SKU: variantSKU, Color: field("color")}
+			product.Variants = append(product.Variants, variant)
+		}
+		for _, url := range strings.Split(field("images"), "|") {
+			if url = strings.TrimSpace(url); url != "" && !containsString(variant.Images, url) {
+				variant.Images = append(variant.Images, url)
+			}
+		}
+
+		if size := field("size"); size != "" {
+			stock, err := strconv.Atoi(field("stock"))
+			if err != nil {
+				fail(row, sku, "invalid stock")
+				continue
+			}
+			variant.Inventory = append(variant.Inventory, &types.CatalogInventory{Size: size, Stock: stock})
+		}
+	}
+
+	// A product is imported from all of its rows or not at all; upserting
+	// the rows that did parse would silently drop sizes or variants.
+	imported := products[:0]
+	for _, p := range products {
+		if !failed[p.SKU] {
+			imported = append(imported, p)
+		}
+	}
+	return imported, errs, nil
+}
+
+// parseCatalogTime parses an optional Unix timestamp column.
+func parseCatalogTime(value string) (*int64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &unix, nil
+}
+
+func formatCatalogTime(unix *int64) string {
+	if unix == nil {
+		return ""
+	}
+	return strconv.FormatInt(*unix, 10)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCatalogProduct checks a product before it reaches the database.
+// An empty status is left for checkCatalogStatus to resolve.
+func validateCatalogProduct(p *types.CatalogProduct, categories map[string]bool) error {
+	if p.SKU == "" {
+		return fmt.Errorf("sku is required")
+	}
+	if p.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if p.Price < 0 {
+		return fmt.Errorf("price must not be negative")
+	}
+	if p.Status != "" && !isValidProductStatus(p.Status) {
+		return fmt.Errorf("invalid product status: %s", p.Status)
+	}
+	if err := validateProductSchedule(p.Status, p.PublishAt, p.UnpublishAt); err != nil {
+		return err
+	}
+	if !categories[p.CategoryID] {
+		return fmt.Errorf("category not found: %s", p.CategoryID)
+	}
+
+	seen := make(map[string]bool)
+	for _, v := range p.Variants {
+		if v.SKU == "" || v.Color == "" {
+			return fmt.Errorf("variant sku and color are required")
+		}
+		if seen[v.SKU] {
+			return fmt.Errorf("duplicate variant sku: %s", v.SKU)
+		}
+		seen[v.SKU] = true
+
+		sizes := make(map[string]bool)
+		for _, inv := range v.Inventory {
+			if inv.Size == "" {
+				return fmt.Errorf("variant %s: size is required", v.SKU)
+			}
+			if inv.Stock < 0 {
+				return fmt.Errorf("variant %s: stock must not be negative", v.SKU)
+			}
+			if sizes[inv.Size] {
+				return fmt.Errorf("variant %s: duplicate size %s", v.SKU, inv.Size)
+			}
+			sizes[inv.Size] = true
+		}
+	}
+	return nil
+}
+
+// checkCatalogStatus applies the status endpoint's transition rules to an
+// imported product. New products without a status start as drafts so they
+// are not published by accident; existing ones keep their current status.
+func (h *ProductService) checkCatalogStatus(ctx context.Context, p *types.CatalogProduct) error {
+	current, err := h.productrepo.GetCatalogProductStatus(ctx, p.SKU)
+	if err != nil {
+		return err
+	}
+	if current == "" {
+		if p.Status == "" {
+			p.Status = types.ProductStatusDraft
+		}
+		return nil
+	}
+	if p.Status != "" && !canTransitionProduct(current, p.Status) {
+		return fmt.Errorf("invalid status transition from %s to %s", current, p.Status)
+	}
+	return nil
+}
+
+// ImportCatalog parses and imports a catalog file. Dry runs and requests with
+// Wait set are processed before returning; otherwise the import continues in
+// the background and the returned job can be polled with GetImportJob.
+func (h *ProductService) ImportCatalog(ctx context.Context, req *request.ImportCatalogRequest, r io.Reader) (*types.ImportJob, error) {
+	h.log.Log(logger.InfoLevel, "Incoming request import catalog")
+
+	products, parseErrs, err := ParseCatalog(req.Format, r)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &types.ImportJob{
+		ID:        utils.GenerateRandomId("IMP"),
+		Format:    req.Format,
+		Status:    types.ImportStatusRunning,
+		DryRun:    req.DryRun,
+		Total:     len(products),
+		Failed:    len(parseErrs),
+		Errors:    append([]types.ImportRowError{}, parseErrs...),
+		CreatedAt: time.Now(),
+	}
+	if err := h.productrepo.CreateImportJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	if req.DryRun || req.Wait {
+		h.runImport(ctx, job, products)
+		return job, nil
+	}
+
+	snapshot := *job
+	go h.runImport(context.Background(), job, products)
+	return &snapshot, nil
+}
+
+func (h *ProductService) runImport(ctx context.Context, job *types.ImportJob, products []*types.CatalogProduct) {
+	categoryIDs := make([]string, 0, len(products))
+	for _, p := range products {
+		categoryIDs = append(categoryIDs, p.CategoryID)
+	}
+
+	categories, err := h.productrepo.ExistingCategoryIDs(ctx, categoryIDs)
+	if err != nil {
+		h.finishImport(ctx, job, err)
+		return
+	}
+
+	for i, p := range products {
+		if err := validateCatalogProduct(p, categories); err != nil {
+			job.Failed++
+			job.Errors = append(job.Errors, types.ImportRowError{Row: p.Row, SKU: p.SKU, Message: err.Error()})
+		} else if err := h.checkCatalogStatus(ctx, p); err != nil {
+			job.Failed++
+			job.Errors = append(job.Errors, types.ImportRowError{Row: p.Row, SKU: p.SKU, Message: err.Error()})
+		} else if created, err := h.productrepo.UpsertCatalogProduct(ctx, p, job.DryRun); err != nil {
+			job.Failed++
+			job.Errors = append(job.Errors, types.ImportRowError{Row: p.Row, SKU: p.SKU, Message: err.Error()})
+		} else if created {
+			job.Created++
+		} else {
+			job.Updated++
+		}
+		job.Processed++
+
+		if (i+1)%importProgressEvery == 0 {
+			if err := h.productrepo.UpdateImportJob(ctx, job); err != nil {
+				h.log.Log(logger.ErrorLevel, "Failed to update import job %s: %v", job.ID, err)
+			}
+		}
+	}
+
+	h.finishImport(ctx, job, nil)
+}
+
+func (h *ProductService) finishImport(ctx context.Context, job *types.ImportJob, err error) {
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = types.ImportStatusCompleted
+	if err != nil {
+		job.Status = types.ImportStatusFailed
+		job.Errors = append(job.Errors, types.ImportRowError{Message: err.Error()})
+	}
+
+	if err := h.productrepo.UpdateImportJob(ctx, job); err != nil {
+		h.log.Log(logger.ErrorLevel, "Failed to update import job %s: %v", job.ID, err)
+	}
+	h.log.Log(logger.InfoLevel, "Import %s %s: %d created, %d updated, %d failed",
+		job.ID, job.Status, job.Created, job.Updated, job.Failed)
+}
+
+func (h *ProductService) GetImportJob(ctx context.Context, id string) (*types.ImportJob, error) {
+	return h.productrepo.GetImportJob(ctx, id)
+}
+
+// ExportCatalog writes every product in the given format, readable by
+// ImportCatalog.
+func (h *ProductService) ExportCatalog(ctx context.Context, format string, w io.Writer) error {
+	h.log.Log(logger.InfoLevel, "Incoming request export catalog")
+
+	products, err := h.productrepo.ExportCatalog(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case types.CatalogFormatCSV:
+		return writeCatalogCSV(w, products)
+	case types.CatalogFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(products)
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+func writeCatalogCSV(w io.Writer, products []*types.CatalogProduct) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(catalogColumns); err != nil {
+		return err
+	}
+
+	for _, p := range products {
+		base := []string{
+			p.SKU, p.Name, p.SubTitle, p.Description,
+			strconv.FormatFloat(p.Price, 'f', 2, 64), p.CategoryID, p.Status,
+			formatCatalogTime(p.PublishAt), formatCatalogTime(p.UnpublishAt),
+		}
+		if len(p.Variants) == 0 {
+			if err := writer.Write(append(base, "", "", "", "", "")); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, v := range p.Variants {
+			variant := append(append([]string{}, base...), v.SKU, v.Color, strings.Join(v.Images, "|"))
+			if len(v.Inventory) == 0 {
+				if err := writer.Write(append(variant, "", "")); err != nil {
+					return err
+				}
+				continue
+			}
+			for _, inv := range v.Inventory {
+				if err := writer.Write(append(append([]string{}, variant...), inv.Size, strconv.Itoa(inv.Stock))); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
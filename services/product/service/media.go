@@ -0,0 +1,66 @@
+package productservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/wafi04/backend/pkg/logger"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+)
+
+// MaxMediaTitleLength bounds video and document titles.
+const MaxMediaTitleLength = 120
+
+var documentKinds = map[string]bool{
+	types.DocumentSizeGuide:        true,
+	types.DocumentCareInstructions: true,
+	types.DocumentOther:            true,
+}
+
+func (h *ProductService) AddProductVideo(ctx context.Context, req *request.AddProductVideoRequest) (*types.ProductVideo, error) {
+	h.log.Log(logger.InfoLevel, "Incoming Request Add Video %s", req.ProductID)
+
+	req.Title = strings.TrimSpace(req.Title)
+	if req.URL == "" {
+		return nil, fmt.Errorf("invalid video: url is required")
+	}
+	if len(req.Title) > MaxMediaTitleLength {
+		return nil, fmt.Errorf("invalid video: title is longer than %d characters", MaxMediaTitleLength)
+	}
+	if req.Duration < 0 || req.Width < 0 || req.Height < 0 {
+		return nil, fmt.Errorf("invalid video: duration and size must not be negative")
+	}
+	return h.productrepo.AddProductVideo(ctx, req)
+}
+
+func (h *ProductService) DeleteProductVideo(ctx context.Context, req *request.DeleteProductMediaRequest) error {
+	return h.productrepo.DeleteProductVideo(ctx, req)
+}
+
+func (h *ProductService) AddProductDocument(ctx context.Context, req *request.AddProductDocumentRequest) (*types.ProductDocument, error) {
+	h.log.Log(logger.InfoLevel, "Incoming Request Add Document %s", req.ProductID)
+
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Kind == "" {
+		req.Kind = types.DocumentOther
+	}
+	if !documentKinds[req.Kind] {
+		return nil, fmt.Errorf("invalid document kind: %s", req.Kind)
+	}
+	if req.URL == "" {
+		return nil, fmt.Errorf("invalid document: url is required")
+	}
+	if req.Title == "" {
+		return nil, fmt.Errorf("invalid document: title is required")
+	}
+	if len(req.Title) > MaxMediaTitleLength {
+		return nil, fmt.Errorf("invalid document: title is longer than %d characters", MaxMediaTitleLength)
+	}
+	return h.productrepo.AddProductDocument(ctx, req)
+}
+
+func (h *ProductService) DeleteProductDocument(ctx context.Context, req *request.DeleteProductMediaRequest) error {
+	return h.productrepo.DeleteProductDocument(ctx, req)
+}
@@ -0,0 +1,95 @@
+package productservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wafi04/backend/pkg/logger"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+)
+
+// referencePriceWindow is the look-back used for the lowest prior price shown
+// next to sale prices.
+const referencePriceWindow = 30 * 24 * time.Hour
+
+// LowestPriorPrice returns the lowest price in effect during the window
+// before the current price took effect, which is the reference a sale price
+// must be compared against. History is newest first with the current price
+// at index 0; with no earlier prices the current price is returned.
+func LowestPriorPrice(history []*types.PriceHistoryEntry, window time.Duration) float64 {
+	if len(history) == 0 {
+		return 0
+	}
+
+	current := history[0]
+	windowStart := current.EffectiveFrom - int64(window.Seconds())
+	lowest := current.NewPrice
+	found := false
+
+	for _, e := range history[1:] {
+		if e.EffectiveTo != nil && *e.EffectiveTo <= windowStart {
+			break
+		}
+		if !found || e.NewPrice < lowest {
+			lowest = e.NewPrice
+			found = true
+		}
+	}
+	return lowest
+}
+
+func (h *ProductService) GetPriceHistory(ctx context.Context, req *request.GetPriceHistoryRequest) (*response.PriceHistoryResponse, error) {
+	res, err := h.productrepo.GetPriceHistory(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	res.LowestPrice30Days = res.CurrentPrice
+	if len(res.History) > 0 {
+		res.LowestPrice30Days = LowestPriorPrice(res.History, referencePriceWindow)
+	}
+	return res, nil
+}
+
+func (h *ProductService) SchedulePriceChange(ctx context.Context, req *request.SchedulePriceChangeRequest) (*types.ScheduledPriceChange, error) {
+	h.log.Log(logger.InfoLevel, "Incoming request schedule price change")
+
+	if req.Price < 0 {
+		return nil, fmt.Errorf("price must not be negative")
+	}
+	if req.StartsAt <= time.Now().Unix() {
+		return nil, fmt.Errorf("starts_at must be in the future")
+	}
+	if req.EndsAt != nil && *req.EndsAt <= req.StartsAt {
+		return nil, fmt.Errorf("ends_at must be after starts_at")
+	}
+	if req.VariantID != nil && *req.VariantID == "" {
+		req.VariantID = nil
+	}
+
+	return h.productrepo.CreatePriceChange(ctx, req)
+}
+
+func (h *ProductService) ListPriceChanges(ctx context.Context, productID string) (*response.ListPriceChangesResponse, error) {
+	return h.productrepo.ListPriceChanges(ctx, productID)
+}
+
+func (h *ProductService) CancelPriceChange(ctx context.Context, req *request.CancelPriceChangeRequest) error {
+	return h.productrepo.CancelPriceChange(ctx, req)
+}
+
+// ApplyPriceSchedule is the scheduler job that starts and ends scheduled
+// price changes.
+func (h *ProductService) ApplyPriceSchedule(ctx context.Context) error {
+	res, err := h.productrepo.ApplyPriceSchedule(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if res.Started > 0 || res.Ended > 0 {
+		h.log.Log(logger.InfoLevel, "Price schedule: %d started, %d ended", res.Started, res.Ended)
+	}
+	return nil
+}
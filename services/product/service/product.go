@@ -2,6 +2,7 @@ package productservice
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/wafi04/backend/pkg/logger"
@@ -23,10 +24,66 @@ func NewProductService(productrepo productRepository.ProductRepository) *Product
 	}
 }
 
+// productStatusTransitions lists the statuses a product may move to from
+// its current status.
+var productStatusTransitions = map[string][]string{
+	types.ProductStatusDraft:     {types.ProductStatusReview, types.ProductStatusScheduled, types.ProductStatusPublished, types.ProductStatusArchived},
+	types.ProductStatusReview:    {types.ProductStatusDraft, types.ProductStatusScheduled, types.ProductStatusPublished, types.ProductStatusArchived},
+	types.ProductStatusScheduled: {types.ProductStatusDraft, types.ProductStatusReview, types.ProductStatusPublished, types.ProductStatusArchived},
+	types.ProductStatusPublished: {types.ProductStatusDraft, types.ProductStatusArchived},
+	types.ProductStatusArchived:  {types.ProductStatusDraft},
+}
+
+func isValidProductStatus(status string) bool {
+	_, ok := productStatusTransitions[status]
+	return ok
+}
+
+func canTransitionProduct(from, to string) bool {
+	if from == to {
+		return true
+	}
+	for _, next := range productStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func validateProductSchedule(status string, publishAt, unpublishAt *int64) error {
+	if status == types.ProductStatusScheduled && publishAt == nil {
+		return fmt.Errorf("publish_at is required for scheduled products")
+	}
+	if publishAt != nil && unpublishAt != nil && *unpublishAt <= *publishAt {
+		return fmt.Errorf("unpublish_at must be after publish_at")
+	}
+	return nil
+}
+
 func (h *ProductService) CreateProduct(ctx context.Context, req *request.CreateProductRequest) (*types.Product, error) {
 	id := utils.GenerateRandomId("PROD")
 	sku := utils.GenerateSku(req.Name)
 
+	status := req.Status
+	if status == "" {
+		status = types.ProductStatusDraft
+	}
+	if !isValidProductStatus(status) {
+		return nil, fmt.Errorf("invalid product status: %s", status)
+	}
+	if err := validateProductSchedule(status, req.PublishAt, req.UnpublishAt); err != nil {
+		return nil, err
+	}
+
+	productType := req.Type
+	if productType == "" {
+		productType = types.ProductTypeStandard
+	}
+	if productType != types.ProductTypeStandard && productType != types.ProductTypeBundle {
+		return nil, fmt.Errorf("invalid product type: %s", productType)
+	}
+
 	h.log.Log(logger.InfoLevel, "incoming request ")
 	return h.productrepo.CreateProduct(ctx, &types.Product{
 		ID:          id,
@@ -36,6 +93,11 @@ func (h *ProductService) CreateProduct(ctx context.Context, req *request.CreateP
 		SKU:         sku,
 		Price:       req.Price,
 		CategoryID:  req.CategoryID,
+		BrandID:     req.BrandID,
+		Type:        productType,
+		Status:      status,
+		PublishAt:   req.PublishAt,
+		UnpublishAt: req.UnpublishAt,
 		CreatedAt:   time.Now().Unix(),
 		UpdatedAt:   time.Now().Unix(),
 	})
@@ -46,8 +108,16 @@ func (h *ProductService) GetProduct(ctx context.Context, req *request.GetProduct
 	return h.productrepo.GetProduct(ctx, req)
 
 }
+func (h *ProductService) GetProductBySlug(ctx context.Context, req *request.GetProductBySlugRequest) (*response.ProductBySlugResponse, error) {
+	h.log.Log(logger.InfoLevel, "incoming request by slug")
+	return h.productrepo.GetProductBySlug(ctx, req)
+}
+
 func (h *ProductService) ListProducts(ctx context.Context, req *request.ListProductsRequest) (*response.ListProductsResponse, error) {
 	h.log.Log(logger.InfoLevel, "incoming request list")
+	if req.CollectionID != "" && req.SortBy == "" {
+		req.SortBy = types.ProductSortPosition
+	}
 	return h.productrepo.ListProducts(ctx, req)
 }
 func (h *ProductService) UpdateProduct(ctx context.Context, req *request.UpdateProductRequest) (*types.Product, error) {
@@ -59,6 +129,64 @@ func (h *ProductService) DeleteProduct(ctx context.Context, req *request.DeleteP
 	return h.productrepo.DeleteProduct(ctx, req)
 }
 
+func (h *ProductService) UpdateProductStatus(ctx context.Context, req *request.UpdateProductStatusRequest) (*types.Product, error) {
+	h.log.Log(logger.InfoLevel, "incoming request update status")
+	if !isValidProductStatus(req.Status) {
+		return nil, fmt.Errorf("invalid product status: %s", req.Status)
+	}
+	if err := validateProductSchedule(req.Status, req.PublishAt, req.UnpublishAt); err != nil {
+		return nil, err
+	}
+
+	current, err := h.productrepo.GetProduct(ctx, &request.GetProductRequest{
+		ID: req.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !canTransitionProduct(current.Status, req.Status) {
+		return nil, fmt.Errorf("invalid status transition from %s to %s", current.Status, req.Status)
+	}
+
+	return h.productrepo.UpdateProductStatus(ctx, req)
+}
+
+// ApplySchedule is run periodically by the scheduler to publish and
+// unpublish products whose scheduled times have passed.
+func (h *ProductService) ApplySchedule(ctx context.Context) error {
+	res, err := h.productrepo.ApplyProductSchedule(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if res.Published > 0 || res.Unpublished > 0 {
+		h.log.Log(logger.InfoLevel, "Product schedule applied: %d published, %d unpublished", res.Published, res.Unpublished)
+	}
+	return nil
+}
+
+func (h *ProductService) ListTrash(ctx context.Context, req *request.ListTrashRequest) (*response.ListTrashResponse, error) {
+	h.log.Log(logger.InfoLevel, "Incoming Request List Trash")
+	return h.productrepo.ListTrash(ctx, req)
+}
+
+func (h *ProductService) RestoreFromTrash(ctx context.Context, req *request.RestoreTrashRequest) (*response.RestoreTrashResponse, error) {
+	h.log.Log(logger.InfoLevel, "Incoming Request Restore %s", req.Type)
+	return h.productrepo.RestoreFromTrash(ctx, req)
+}
+
+// PurgeTrash permanently deletes catalog rows that have been in the trash
+// longer than retention.
+func (h *ProductService) PurgeTrash(ctx context.Context, retention time.Duration) error {
+	purged, err := h.productrepo.PurgeTrash(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		h.log.Log(logger.InfoLevel, "Purged %d product rows from trash", purged)
+	}
+	return nil
+}
+
 func (h *ProductService) CreateProductVariant(ctx context.Context, req *request.CreateProductVariantRequest) (*types.ProductVariant, error) {
 	h.log.Log(logger.InfoLevel, "Incoming Request Create Varinat")
 	return h.productrepo.CreateProductVariant(ctx, req)
@@ -92,6 +220,37 @@ func (h *ProductService) UpdateProductImage(ctx context.Context, req *request.Up
 	return h.productrepo.UpdateProductImage(ctx, req)
 }
 
+// AddProductImages adds several images to a variant, in order, as one
+// change.
+func (h *ProductService) AddProductImages(ctx context.Context, variantID string, reqs []*request.AddProductImageRequest) ([]*types.ProductImage, error) {
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("invalid request: no images given")
+	}
+	return h.productrepo.AddProductImages(ctx, variantID, reqs)
+}
+
+func (h *ProductService) SetMainProductImage(ctx context.Context, req *request.SetMainProductImageRequest) (*types.ProductImage, error) {
+	return h.productrepo.SetMainProductImage(ctx, req)
+}
+
+// ReorderProductImages sets the display order of a variant's images.
+func (h *ProductService) ReorderProductImages(ctx context.Context, req *request.ReorderProductImagesRequest) error {
+	seen := make(map[string]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		if seen[id] {
+			return fmt.Errorf("invalid order: %s is listed twice", id)
+		}
+		seen[id] = true
+	}
+	return h.productrepo.ReorderProductImages(ctx, req)
+}
+
+// SaveImageDerivatives stores the resized encodes built for an image. It is
+// called by the derivative workers.
+func (h *ProductService) SaveImageDerivatives(ctx context.Context, imageID string, derivatives []*types.ImageDerivative) error {
+	return h.productrepo.SaveImageDerivatives(ctx, imageID, derivatives)
+}
+
 func (h *ProductService) DeleteProductImage(ctx context.Context, req *request.DeleteProductImageRequest) (*response.DeleteProductResponse, error) {
 	h.log.Log(logger.InfoLevel, "Incoming Request Delete image")
 	return h.productrepo.DeleteProductImage(ctx, req)
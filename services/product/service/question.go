@@ -0,0 +1,82 @@
+package productservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/wafi04/backend/pkg/logger"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+)
+
+const maxQABodyLength = 2000
+
+func validateQABody(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("body is required")
+	}
+	if len(body) > maxQABodyLength {
+		return fmt.Errorf("body must be at most %d characters", maxQABodyLength)
+	}
+	return nil
+}
+
+func validateQAStatus(status string) error {
+	switch status {
+	case types.QAStatusPending, types.QAStatusApproved, types.QAStatusRejected:
+		return nil
+	}
+	return fmt.Errorf("invalid status: %s", status)
+}
+
+func (h *ProductService) CreateQuestion(ctx context.Context, req *request.CreateQuestionRequest) (*types.Question, error) {
+	h.log.Log(logger.InfoLevel, "Incoming request create question")
+	if err := validateQABody(req.Body); err != nil {
+		return nil, err
+	}
+	return h.productrepo.CreateQuestion(ctx, req)
+}
+
+func (h *ProductService) ListQuestions(ctx context.Context, req *request.ListQuestionsRequest) (*response.ListQuestionsResponse, error) {
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+	return h.productrepo.ListQuestions(ctx, req)
+}
+
+func (h *ProductService) ModerateQuestion(ctx context.Context, req *request.ModerateQARequest) error {
+	h.log.Log(logger.InfoLevel, "Incoming request moderate question %s", req.ID)
+	if err := validateQAStatus(req.Status); err != nil {
+		return err
+	}
+	return h.productrepo.ModerateQuestion(ctx, req)
+}
+
+func (h *ProductService) CreateAnswer(ctx context.Context, req *request.CreateAnswerRequest) (*types.Answer, error) {
+	h.log.Log(logger.InfoLevel, "Incoming request create answer")
+	if err := validateQABody(req.Body); err != nil {
+		return nil, err
+	}
+	return h.productrepo.CreateAnswer(ctx, req)
+}
+
+func (h *ProductService) ListAnswers(ctx context.Context, req *request.ListAnswersRequest) (*response.ListAnswersResponse, error) {
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+	return h.productrepo.ListAnswers(ctx, req)
+}
+
+func (h *ProductService) ModerateAnswer(ctx context.Context, req *request.ModerateQARequest) error {
+	h.log.Log(logger.InfoLevel, "Incoming request moderate answer %s", req.ID)
+	if err := validateQAStatus(req.Status); err != nil {
+		return err
+	}
+	return h.productrepo.ModerateAnswer(ctx, req)
+}
+
+func (h *ProductService) UpvoteAnswer(ctx context.Context, req *request.UpvoteAnswerRequest) (*response.UpvoteAnswerResponse, error) {
+	return h.productrepo.UpvoteAnswer(ctx, req)
+}
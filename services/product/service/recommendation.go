@@ -0,0 +1,68 @@
+package productservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wafi04/backend/pkg/logger"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+)
+
+const (
+	defaultRecommendationLimit = 8
+	maxRecommendationLimit     = 50
+)
+
+func recommendationLimit(limit int) int {
+	if limit <= 0 {
+		return defaultRecommendationLimit
+	}
+	if limit > maxRecommendationLimit {
+		return maxRecommendationLimit
+	}
+	return limit
+}
+
+func (h *ProductService) GetRelatedProducts(ctx context.Context, req *request.GetRelatedProductsRequest) (*response.RelatedProductsResponse, error) {
+	req.Limit = recommendationLimit(req.Limit)
+	return h.productrepo.GetRelatedProducts(ctx, req)
+}
+
+func (h *ProductService) GetCartRecommendations(ctx context.Context, req *request.GetCartRecommendationsRequest) (*response.RelatedProductsResponse, error) {
+	req.Limit = recommendationLimit(req.Limit)
+	return h.productrepo.GetCartRecommendations(ctx, req)
+}
+
+// RecomputeSimilarities is the batch job behind related products and cart
+// recommendations.
+func (h *ProductService) RecomputeSimilarities(ctx context.Context) error {
+	res, err := h.productrepo.RecomputeSimilarities(ctx)
+	if err != nil {
+		return err
+	}
+	h.log.Log(logger.InfoLevel, "Recomputed %d similarities and %d co-occurrences in %dms",
+		res.Similarities, res.CoOccurrences, res.DurationMillis)
+	return nil
+}
+
+func (h *ProductService) ListRecommendationOverrides(ctx context.Context, productID string) (*response.ListRecommendationOverridesResponse, error) {
+	return h.productrepo.ListRecommendationOverrides(ctx, productID)
+}
+
+func (h *ProductService) SetRecommendationOverride(ctx context.Context, req *request.SetRecommendationOverrideRequest) (*types.RecommendationOverride, error) {
+	h.log.Log(logger.InfoLevel, "Incoming request set recommendation override")
+
+	if req.Type != types.RecommendationOverridePinned && req.Type != types.RecommendationOverrideExcluded {
+		return nil, fmt.Errorf("invalid override type: %s", req.Type)
+	}
+	if req.RelatedID == "" || req.RelatedID == req.ProductID {
+		return nil, fmt.Errorf("related_id must be another product")
+	}
+	return h.productrepo.SetRecommendationOverride(ctx, req)
+}
+
+func (h *ProductService) DeleteRecommendationOverride(ctx context.Context, req *request.DeleteRecommendationOverrideRequest) error {
+	return h.productrepo.DeleteRecommendationOverride(ctx, req)
+}
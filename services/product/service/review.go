@@ -0,0 +1,80 @@
+package productservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wafi04/backend/pkg/logger"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+)
+
+const (
+	maxReviewBodyLength = 5000
+	MaxReviewPhotos     = 5
+)
+
+func validateReview(req *request.CreateReviewRequest) error {
+	if req.Rating < 1 || req.Rating > 5 {
+		return fmt.Errorf("rating must be between 1 and 5")
+	}
+	if req.Body == "" {
+		return fmt.Errorf("review body is required")
+	}
+	if len(req.Body) > maxReviewBodyLength {
+		return fmt.Errorf("review body must be at most %d characters", maxReviewBodyLength)
+	}
+	if len(req.Photos) > MaxReviewPhotos {
+		return fmt.Errorf("at most %d photos are allowed", MaxReviewPhotos)
+	}
+	return nil
+}
+
+// ValidateReview runs every check CreateReview does without storing
+// anything, so the handler can reject a review before uploading its photos.
+func (h *ProductService) ValidateReview(ctx context.Context, req *request.CreateReviewRequest) error {
+	if err := validateReview(req); err != nil {
+		return err
+	}
+	return h.productrepo.CheckReviewable(ctx, req.ProductID, req.UserID)
+}
+
+func (h *ProductService) CreateReview(ctx context.Context, req *request.CreateReviewRequest) (*types.Review, error) {
+	h.log.Log(logger.InfoLevel, "Incoming request create review")
+
+	if err := validateReview(req); err != nil {
+		return nil, err
+	}
+
+	return h.productrepo.CreateReview(ctx, req)
+}
+
+func (h *ProductService) ListReviews(ctx context.Context, req *request.ListReviewsRequest) (*response.ListReviewsResponse, error) {
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+	return h.productrepo.ListReviews(ctx, req)
+}
+
+func (h *ProductService) ModerateReview(ctx context.Context, req *request.ModerateReviewRequest) (*types.Review, error) {
+	h.log.Log(logger.InfoLevel, "Incoming request moderate review %s", req.ID)
+
+	switch req.Status {
+	case types.ReviewStatusPending, types.ReviewStatusApproved, types.ReviewStatusRejected:
+	default:
+		return nil, fmt.Errorf("invalid review status: %s", req.Status)
+	}
+
+	// There is no order history to check purchases against, so approving
+	// a review needs the moderator's explicit verified_buyer decision.
+	if req.Status == types.ReviewStatusApproved && req.VerifiedBuyer == nil {
+		return nil, fmt.Errorf("invalid moderation: verified_buyer is required when approving a review")
+	}
+
+	return h.productrepo.ModerateReview(ctx, req)
+}
+
+func (h *ProductService) MarkReviewHelpful(ctx context.Context, req *request.MarkReviewHelpfulRequest) (*response.MarkReviewHelpfulResponse, error) {
+	return h.productrepo.MarkReviewHelpful(ctx, req)
+}
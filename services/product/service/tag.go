@@ -0,0 +1,49 @@
+package productservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/wafi04/backend/pkg/logger"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+	"github.com/wafi04/backend/pkg/utils"
+)
+
+// MaxTagLength bounds a single free-form tag.
+const MaxTagLength = 50
+
+func (h *ProductService) AddProductTags(ctx context.Context, req *request.AddProductTagsRequest) (*response.ProductTagsResponse, error) {
+	h.log.Log(logger.InfoLevel, "Incoming Request Add Tags %s", req.ProductID)
+
+	tags := make([]string, 0, len(req.Tags))
+	for _, tag := range req.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if len(tag) > MaxTagLength {
+			return nil, fmt.Errorf("tag %q is longer than %d characters", tag, MaxTagLength)
+		}
+		if utils.Slugify(tag) == "" {
+			return nil, fmt.Errorf("tag %q has no usable characters", tag)
+		}
+		tags = append(tags, tag)
+	}
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("at least one tag is required")
+	}
+	req.Tags = tags
+
+	return h.productrepo.AddProductTags(ctx, req)
+}
+
+func (h *ProductService) RemoveProductTag(ctx context.Context, req *request.RemoveProductTagRequest) error {
+	h.log.Log(logger.InfoLevel, "Incoming Request Remove Tag %s from %s", req.Slug, req.ProductID)
+	return h.productrepo.RemoveProductTag(ctx, req)
+}
+
+func (h *ProductService) ListTags(ctx context.Context, req *request.ListTagsRequest) (*response.ListTagsResponse, error) {
+	return h.productrepo.ListTags(ctx, req)
+}
@@ -0,0 +1,95 @@
+package upload
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/backend/pkg/middleware"
+	httpresponse "github.com/wafi04/backend/pkg/response"
+	request "github.com/wafi04/backend/pkg/types/req"
+)
+
+type UploadHandler struct {
+	uploadService *UploadService
+}
+
+func NewUploadHandler(service *UploadService) *UploadHandler {
+	return &UploadHandler{
+		uploadService: service,
+	}
+}
+
+func uploadErrorStatus(err error) int {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		return http.StatusNotFound
+	case strings.Contains(err.Error(), "not supported"):
+		return http.StatusNotImplemented
+	case strings.Contains(err.Error(), "signature"):
+		return http.StatusForbidden
+	case strings.Contains(err.Error(), "invalid"):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// HandleCreateUploadIntent issues a signed URL for uploading one file
+// directly to storage.
+func (h *UploadHandler) HandleCreateUploadIntent(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		httpresponse.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req request.CreateUploadIntentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	res, err := h.uploadService.CreateIntent(c, user.UserID, &req)
+	if err != nil {
+		httpresponse.SendUploadError(c, err, uploadErrorStatus(err), err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusCreated, "Upload Intent Created", res)
+}
+
+// HandleConfirmUpload verifies the file uploaded for intent :id and returns
+// its URL.
+func (h *UploadHandler) HandleConfirmUpload(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		httpresponse.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	res, err := h.uploadService.ConfirmUpload(c, user.UserID, c.Param("id"))
+	if err != nil {
+		httpresponse.SendUploadError(c, err, uploadErrorStatus(err), err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Upload Confirmed", res)
+}
+
+// HandleLocalUpload receives the body of a PUT to a URL signed by the local
+// storage driver. The signature in the query string is the authorization.
+func (h *UploadHandler) HandleLocalUpload(c *gin.Context) {
+	err := h.uploadService.ReceiveLocalUpload(
+		strings.TrimPrefix(c.Param("key"), "/"),
+		c.Query("expires"),
+		c.Query("max"),
+		c.Query("signature"),
+		c.Request.Body,
+	)
+	if err != nil {
+		httpresponse.SendUploadError(c, err, uploadErrorStatus(err), err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
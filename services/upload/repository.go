@@ -0,0 +1,154 @@
+package upload
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/wafi04/backend/pkg/logger"
+	"github.com/wafi04/backend/pkg/types"
+)
+
+type Database struct {
+	DB  *sqlx.DB
+	log logger.Logger
+}
+
+type UploadRepository interface {
+	CreateIntent(ctx context.Context, intent *types.UploadIntent) error
+	GetIntent(ctx context.Context, id string) (*types.UploadIntent, error)
+	SetIntentStatus(ctx context.Context, id, from, to string) (bool, error)
+	ListStaleIntents(ctx context.Context, before time.Time, limit int) ([]*types.UploadIntent, error)
+	MarkIntentSwept(ctx context.Context, id string) error
+}
+
+func NewUploadRepository(DB *sqlx.DB) UploadRepository {
+	return &Database{DB: DB}
+}
+
+const intentColumns = `id, user_id, file_type, object_key, content_type, max_bytes, status, expires_at, confirmed_at, created_at`
+
+func scanIntent(row interface{ Scan(...interface{}) error }) (*types.UploadIntent, error) {
+	var intent types.UploadIntent
+	var confirmedAt sql.NullTime
+	err := row.Scan(
+		&intent.ID,
+		&intent.UserID,
+		&intent.FileType,
+		&intent.ObjectKey,
+		&intent.ContentType,
+		&intent.MaxBytes,
+		&intent.Status,
+		&intent.ExpiresAt,
+		&confirmedAt,
+		&intent.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if confirmedAt.Valid {
+		intent.ConfirmedAt = &confirmedAt.Time
+	}
+	return &intent, nil
+}
+
+func (r *Database) CreateIntent(ctx context.Context, intent *types.UploadIntent) error {
+	err := r.DB.QueryRowContext(ctx, `
+        INSERT INTO upload_intents (id, user_id, file_type, object_key, content_type, max_bytes, status, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING created_at`,
+		intent.ID, intent.UserID, intent.FileType, intent.ObjectKey, intent.ContentType,
+		intent.MaxBytes, intent.Status, intent.ExpiresAt,
+	).Scan(&intent.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create upload intent: %v", err)
+	}
+	return nil
+}
+
+func (r *Database) GetIntent(ctx context.Context, id string) (*types.UploadIntent, error) {
+	intent, err := scanIntent(r.DB.QueryRowContext(ctx,
+		"SELECT "+intentColumns+" FROM upload_intents WHERE id = $1",
+		id,
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("upload intent not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upload intent: %v", err)
+	}
+	return intent, nil
+}
+
+// SetIntentStatus moves an intent from one status to another and reports
+// whether it was still in the expected status, so two confirmations of the
+// same intent cannot both succeed.
+func (r *Database) SetIntentStatus(ctx context.Context, id, from, to string) (bool, error) {
+	result, err := r.DB.ExecContext(ctx, `
+        UPDATE upload_intents
+        SET status = $3,
+            confirmed_at = CASE WHEN $3 = 'confirmed' THEN NOW() ELSE confirmed_at END
+        WHERE id = $1 AND status = $2`,
+		id, from, to,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to update upload intent: %v", err)
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	return count > 0, nil
+}
+
+// ListStaleIntents returns intents that expired before the given time and
+// whose object may still be in storage without belonging to an asset: ones
+// never confirmed, ones rejected, and confirmed ones whose file was dropped
+// as a duplicate. Their signed URL stayed valid until expiry, so any of
+// them can have had a file put there after the intent was settled.
+func (r *Database) ListStaleIntents(ctx context.Context, before time.Time, limit int) ([]*types.UploadIntent, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+        SELECT `+intentColumns+`
+        FROM upload_intents i
+        WHERE i.swept_at IS NULL AND i.expires_at < $1
+        AND (
+            i.status IN ('pending', 'rejected')
+            OR (
+                i.status = 'confirmed' AND i.confirmed_at < $1
+                AND NOT EXISTS (SELECT 1 FROM media_assets m WHERE m.public_id = i.object_key)
+            )
+        )
+        ORDER BY i.expires_at
+        LIMIT $2`,
+		before, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale upload intents: %v", err)
+	}
+	defer rows.Close()
+
+	var intents []*types.UploadIntent
+	for rows.Next() {
+		intent, err := scanIntent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan upload intent: %v", err)
+		}
+		intents = append(intents, intent)
+	}
+	return intents, rows.Err()
+}
+
+// MarkIntentSwept records that an intent's object has been deleted after
+// expiry so the sweep does not pick it up again.
+func (r *Database) MarkIntentSwept(ctx context.Context, id string) error {
+	_, err := r.DB.ExecContext(ctx,
+		"UPDATE upload_intents SET swept_at = NOW() WHERE id = $1",
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update upload intent: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wafi04/backend/pkg/logger"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+	"github.com/wafi04/backend/services/files"
+	"github.com/wafi04/backend/services/media"
+)
+
+// IntentTTL is how long a signed upload URL stays valid.
+const IntentTTL = 15 * time.Minute
+
+// staleAfter is how long after expiry an unconfirmed upload is deleted.
+const staleAfter = time.Hour
+
+// uploadFolders are the folders clients may upload into directly.
+var uploadFolders = map[string]bool{
+	"products":    true,
+	"categories":  true,
+	"brands":      true,
+	"collections": true,
+	"reviews":     true,
+	"videos":      true,
+	"documents":   true,
+}
+
+type UploadService struct {
+	repo     UploadRepository
+	media    media.MediaRepository
+	storage  files.FileStorage
+	uploader files.DirectUploader
+	local    *files.LocalStorage
+	rules    map[request.FileType]files.UploadRule
+	log      *logger.Logger
+}
+
+// NewUploadService takes the storage driver itself, not the validating
+// wrapper: direct uploads are validated on confirmation instead.
+func NewUploadService(repo UploadRepository, mediaRepo media.MediaRepository, storage files.FileStorage) *UploadService {
+	uploader, _ := storage.(files.DirectUploader)
+	local, _ := storage.(*files.LocalStorage)
+	return &UploadService{
+		repo:     repo,
+		media:    mediaRepo,
+		storage:  storage,
+		uploader: uploader,
+		local:    local,
+		rules:    files.DefaultUploadRules,
+		log:      logger.NewLogger(),
+	}
+}
+
+// CreateIntent checks the declared file against its upload rule and returns
+// a signed URL the client can upload it to.
+func (s *UploadService) CreateIntent(ctx context.Context, userID string, req *request.CreateUploadIntentRequest) (*response.UploadIntentResponse, error) {
+	if s.uploader == nil {
+		return nil, fmt.Errorf("direct uploads are not supported by this storage driver")
+	}
+
+	rule, ok := s.rules[req.FileType]
+	if !ok {
+		return nil, fmt.Errorf("invalid file type: %d", req.FileType)
+	}
+	if !uploadFolders[req.Folder] {
+		return nil, fmt.Errorf("invalid folder: %s", req.Folder)
+	}
+	if req.Size <= 0 || req.Size > rule.MaxBytes {
+		return nil, &response.FileValidationError{
+			Code:    response.FileErrorTooLarge,
+			Message: fmt.Sprintf("file must be between 1 and %d bytes", rule.MaxBytes),
+		}
+	}
+	contentType := strings.ToLower(strings.TrimSpace(req.ContentType))
+	if !allowedType(rule.AllowedTypes, contentType) {
+		return nil, &response.FileValidationError{
+			Code:        response.FileErrorUnsupported,
+			Message:     fmt.Sprintf("%s is not accepted, expected one of %s", contentType, strings.Join(rule.AllowedTypes, ", ")),
+			ContentType: contentType,
+		}
+	}
+
+	id := uuid.New().String()
+	// The extension follows the declared type rather than the client's
+	// file name; confirmation rejects files that do not match it.
+	key, err := files.ObjectKey(&request.FileUploadRequest{
+		FileName: id + files.ExtensionForType(contentType),
+		Folder:   req.Folder,
+		PublicID: id,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	intent := &types.UploadIntent{
+		ID:          id,
+		UserID:      userID,
+		FileType:    int32(req.FileType),
+		ObjectKey:   key,
+		ContentType: contentType,
+		MaxBytes:    req.Size,
+		Status:      types.UploadStatusPending,
+		ExpiresAt:   time.Now().Add(IntentTTL),
+	}
+
+	target, err := s.uploader.PresignUpload(ctx, key, contentType, req.Size, IntentTTL)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateIntent(ctx, intent); err != nil {
+		return nil, err
+	}
+
+	return &response.UploadIntentResponse{Intent: intent, Upload: target}, nil
+}
+
+// ConfirmUpload validates a directly uploaded file and registers it as a
+// media asset. Rejected files are deleted from storage.
+func (s *UploadService) ConfirmUpload(ctx context.Context, userID, intentID string) (*response.ConfirmUploadResponse, error) {
+	if s.uploader == nil {
+		return nil, fmt.Errorf("direct uploads are not supported by this storage driver")
+	}
+
+	intent, err := s.repo.GetIntent(ctx, intentID)
+	if err != nil {
+		return nil, err
+	}
+	if intent.UserID != userID {
+		return nil, fmt.Errorf("upload intent not found")
+	}
+	if intent.Status != types.UploadStatusPending {
+		return nil, fmt.Errorf("invalid upload: intent is %s", intent.Status)
+	}
+
+	rc, err := s.uploader.OpenFile(ctx, intent.ObjectKey)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, fmt.Errorf("invalid upload: the file has not been uploaded yet")
+		}
+		return nil, err
+	}
+	uploaded, err := io.ReadAll(io.LimitReader(rc, intent.MaxBytes+1))
+	rc.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uploaded file: %v", err)
+	}
+
+	claimed, err := s.repo.SetIntentStatus(ctx, intent.ID, types.UploadStatusPending, types.UploadStatusConfirmed)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, fmt.Errorf("invalid upload: intent is no longer pending")
+	}
+
+	rule := s.rules[request.FileType(intent.FileType)]
+	rule.MaxBytes = intent.MaxBytes
+	data, err := files.ValidateUpload(bytes.NewReader(uploaded), rule)
+	if err != nil {
+		s.reject(ctx, intent)
+		return nil, err
+	}
+	// The object key's extension and the type storage serves the file with
+	// follow the declared type, so another accepted type is refused too.
+	contentType := detectContentType(data)
+	if contentType != intent.ContentType {
+		s.reject(ctx, intent)
+		return nil, &response.FileValidationError{
+			Code:        response.FileErrorUnsupported,
+			Message:     fmt.Sprintf("file is %s, but the upload was declared as %s", contentType, intent.ContentType),
+			ContentType: contentType,
+		}
+	}
+
+	// Validation strips metadata; store the cleaned bytes in place of what
+	// the client sent.
+	if !bytes.Equal(data, uploaded) {
+		ext := path.Ext(intent.ObjectKey)
+		_, err := s.storage.UploadFile(ctx, &request.FileUploadRequest{
+			FileData: files.NewMemoryFile(data),
+			FileName: intent.ObjectKey,
+			Folder:   path.Dir(intent.ObjectKey),
+			PublicID: strings.TrimSuffix(path.Base(intent.ObjectKey), ext),
+			// Replaces what the client put there.
+			Overwrite: true,
+		})
+		if err != nil {
+			s.release(ctx, intent)
+			return nil, fmt.Errorf("failed to store cleaned file: %v", err)
+		}
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	existing, err := s.media.FindByHash(ctx, hash)
+	if err != nil {
+		s.release(ctx, intent)
+		return nil, err
+	}
+	if existing == nil {
+		existing, err = s.media.CreateAsset(ctx, &types.MediaAsset{
+			Hash:        hash,
+			PublicID:    intent.ObjectKey,
+			URL:         s.uploader.FileURL(intent.ObjectKey),
+			ContentType: contentType,
+			Size:        int64(len(data)),
+		})
+		if err != nil {
+			s.release(ctx, intent)
+			return nil, err
+		}
+	}
+
+	deduplicated := existing.PublicID != intent.ObjectKey
+	if deduplicated {
+		s.deleteObject(ctx, intent)
+	}
+
+	return &response.ConfirmUploadResponse{
+		URL:          existing.URL,
+		PublicID:     existing.PublicID,
+		Deduplicated: deduplicated,
+	}, nil
+}
+
+// detectContentType sniffs the MIME type of data without parameters.
+func detectContentType(data []byte) string {
+	contentType := http.DetectContentType(data)
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return contentType
+}
+
+func (s *UploadService) reject(ctx context.Context, intent *types.UploadIntent) {
+	s.deleteObject(ctx, intent)
+	if _, err := s.repo.SetIntentStatus(ctx, intent.ID, types.UploadStatusConfirmed, types.UploadStatusRejected); err != nil {
+		s.log.Log(logger.ErrorLevel, "Failed to reject upload intent %s: %v", intent.ID, err)
+	}
+}
+
+// release hands a claimed intent back to pending when confirmation fails
+// for a reason other than the file itself, so the client can retry.
+func (s *UploadService) release(ctx context.Context, intent *types.UploadIntent) {
+	if _, err := s.repo.SetIntentStatus(ctx, intent.ID, types.UploadStatusConfirmed, types.UploadStatusPending); err != nil {
+		s.log.Log(logger.ErrorLevel, "Failed to release upload intent %s: %v", intent.ID, err)
+	}
+}
+
+func (s *UploadService) deleteObject(ctx context.Context, intent *types.UploadIntent) {
+	if err := s.storage.DeleteFile(ctx, intent.ObjectKey, intent.ContentType); err != nil {
+		s.log.Log(logger.ErrorLevel, "Failed to delete uploaded file %s: %v", intent.ObjectKey, err)
+	}
+}
+
+// ReceiveLocalUpload stores a file sent to a URL signed by the local
+// driver.
+func (s *UploadService) ReceiveLocalUpload(key, expires, limit, signature string, body io.Reader) error {
+	if s.local == nil {
+		return fmt.Errorf("local upload not found")
+	}
+	return s.local.ReceiveUpload(key, expires, limit, signature, body)
+}
+
+// ExpireIntents deletes files uploaded for intents that were never
+// confirmed, and deletes again the objects of rejected and deduplicated
+// intents, since their signed URL could still be used until it expired.
+func (s *UploadService) ExpireIntents(ctx context.Context) error {
+	intents, err := s.repo.ListStaleIntents(ctx, time.Now().Add(-staleAfter), 500)
+	if err != nil {
+		return err
+	}
+	for _, intent := range intents {
+		if intent.Status == types.UploadStatusPending {
+			expired, err := s.repo.SetIntentStatus(ctx, intent.ID, types.UploadStatusPending, types.UploadStatusExpired)
+			if err != nil {
+				return err
+			}
+			// Confirmed in the meantime; the next run sees it as such.
+			if !expired {
+				continue
+			}
+		}
+		if err := s.storage.DeleteFile(ctx, intent.ObjectKey, intent.ContentType); err != nil {
+			s.log.Log(logger.ErrorLevel, "Failed to delete uploaded file %s: %v", intent.ObjectKey, err)
+			continue
+		}
+		if err := s.repo.MarkIntentSwept(ctx, intent.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func allowedType(types []string, contentType string) bool {
+	for _, t := range types {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
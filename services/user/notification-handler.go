@@ -0,0 +1,72 @@
+package user
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/backend/pkg/middleware"
+	httpresponse "github.com/wafi04/backend/pkg/response"
+)
+
+type NotificationHandler struct {
+	notificationRepo NotificationRepo
+}
+
+func NewNotificationHandler(notificationRepo NotificationRepo) *NotificationHandler {
+	return &NotificationHandler{
+		notificationRepo: notificationRepo,
+	}
+}
+
+func (h *NotificationHandler) GetAll(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		httpresponse.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	data, err := h.notificationRepo.ListNotifications(c, user.UserID, c.Query("unread") == "true")
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusInternalServerError, "Failed to get notifications", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Get Notifications successfully", data)
+}
+
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		httpresponse.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	data, err := h.notificationRepo.MarkNotificationRead(c, user.UserID, c.Param("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "not found") {
+			status = http.StatusNotFound
+		}
+		httpresponse.SendErrorResponseWithDetails(c, status, "Failed to mark notification", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Notification marked as read", data)
+}
+
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		httpresponse.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	data, err := h.notificationRepo.MarkAllNotificationsRead(c, user.UserID)
+	if err != nil {
+		httpresponse.SendErrorResponseWithDetails(c, http.StatusInternalServerError, "Failed to mark notifications", err.Error())
+		return
+	}
+
+	httpresponse.SendSuccessResponse(c, http.StatusOK, "Notifications marked as read", data)
+}
@@ -0,0 +1,101 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/wafi04/backend/pkg/types"
+)
+
+type NotificationRepo interface {
+	ListNotifications(ctx context.Context, userID string, unreadOnly bool) (*types.ListNotifications, error)
+	MarkNotificationRead(ctx context.Context, userID, notificationID string) (*Success, error)
+	MarkAllNotificationsRead(ctx context.Context, userID string) (*Success, error)
+}
+
+func NewNotificationRepo(db *sqlx.DB) NotificationRepo {
+	return &Database{db: db}
+}
+
+func (d *Database) ListNotifications(ctx context.Context, userID string, unreadOnly bool) (*types.ListNotifications, error) {
+	query := `
+		SELECT
+			id,
+			user_id,
+			type,
+			title,
+			message,
+			COALESCE(entity_id, ''),
+			read_at IS NOT NULL,
+			created_at
+		FROM notifications
+		WHERE user_id = $1
+		AND ($2 = false OR read_at IS NULL)
+		ORDER BY created_at DESC
+		LIMIT 100
+	`
+	rows, err := d.db.DB.QueryContext(ctx, query, userID, unreadOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications : %v", err)
+	}
+	defer rows.Close()
+
+	list := &types.ListNotifications{Notifications: []*types.Notification{}}
+	for rows.Next() {
+		n := &types.Notification{}
+		var createdAt time.Time
+		if err := rows.Scan(
+			&n.ID,
+			&n.UserID,
+			&n.Type,
+			&n.Title,
+			&n.Message,
+			&n.EntityID,
+			&n.IsRead,
+			&createdAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan notification : %v", err)
+		}
+		n.CreatedAt = createdAt.Unix()
+		list.Notifications = append(list.Notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get notifications : %v", err)
+	}
+
+	err = d.db.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL
+	`, userID).Scan(&list.UnreadCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count notifications : %v", err)
+	}
+
+	return list, nil
+}
+
+func (d *Database) MarkNotificationRead(ctx context.Context, userID, notificationID string) (*Success, error) {
+	result, err := d.db.DB.ExecContext(ctx, `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND user_id = $2
+	`, notificationID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark notification : %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, fmt.Errorf("notification not found")
+	}
+	return &Success{Success: true}, nil
+}
+
+func (d *Database) MarkAllNotificationsRead(ctx context.Context, userID string) (*Success, error) {
+	_, err := d.db.DB.ExecContext(ctx, `
+		UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark notifications : %v", err)
+	}
+	return &Success{Success: true}, nil
+}
@@ -0,0 +1,198 @@
+package files_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/backend/services/files"
+)
+
+func TestResize(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+	for x := 0; x < 400; x++ {
+		for y := 0; y < 200; y++ {
+			src.Set(x, y, color.NRGBA{R: 200, A: 255})
+		}
+	}
+
+	tests := []struct {
+		name       string
+		width      int
+		wantWidth  int
+		wantHeight int
+	}{
+		{name: "downscale keeps aspect", width: 100, wantWidth: 100, wantHeight: 50},
+		{name: "never upscales", width: 800, wantWidth: 400, wantHeight: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := files.Resize(src, tt.width)
+			assert.Equal(t, tt.wantWidth, out.Bounds().Dx())
+			assert.Equal(t, tt.wantHeight, out.Bounds().Dy())
+
+			r, _, _, a := out.At(0, 0).RGBA()
+			assert.Equal(t, uint32(200), r>>8)
+			assert.Equal(t, uint32(255), a>>8)
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// Every pixel of the 3x2 source has its own red value, 10*x + y + 1.
+	src := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	for x := 0; x < 3; x++ {
+		for y := 0; y < 2; y++ {
+			src.Set(x, y, color.NRGBA{R: uint8(10*x + y + 1), A: 255})
+		}
+	}
+
+	tests := []struct {
+		name         string
+		orientation  uint16
+		wantWidth    int
+		wantTopLeft  uint8
+		wantTopRight uint8
+	}{
+		{name: "upright", orientation: 1, wantWidth: 3, wantTopLeft: 1, wantTopRight: 21},
+		{name: "mirrored", orientation: 2, wantWidth: 3, wantTopLeft: 21, wantTopRight: 1},
+		{name: "upside down", orientation: 3, wantWidth: 3, wantTopLeft: 22, wantTopRight: 2},
+		{name: "flipped", orientation: 4, wantWidth: 3, wantTopLeft: 2, wantTopRight: 22},
+		{name: "transposed", orientation: 5, wantWidth: 2, wantTopLeft: 1, wantTopRight: 2},
+		{name: "rotate right", orientation: 6, wantWidth: 2, wantTopLeft: 2, wantTopRight: 1},
+		{name: "transversed", orientation: 7, wantWidth: 2, wantTopLeft: 22, wantTopRight: 21},
+		{name: "rotate left", orientation: 8, wantWidth: 2, wantTopLeft: 21, wantTopRight: 22},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := files.Orient(src, tt.orientation)
+			assert.Equal(t, tt.wantWidth, out.Bounds().Dx())
+
+			r, _, _, _ := out.At(0, 0).RGBA()
+			assert.Equal(t, tt.wantTopLeft, uint8(r>>8))
+			r, _, _, _ = out.At(tt.wantWidth-1, 0).RGBA()
+			assert.Equal(t, tt.wantTopRight, uint8(r>>8))
+		})
+	}
+}
+
+func TestDerivativePoolBuildAppliesOrientation(t *testing.T) {
+	storage, err := files.NewLocalStorage(t.TempDir(), "", "secret")
+	require.NoError(t, err)
+
+	pool, err := files.NewDerivativePool(storage, files.DerivativeConfig{
+		Specs:   []files.DerivativeSpec{{Name: "card", Width: 1000}},
+		Formats: []string{"jpeg"},
+	}, nil)
+	require.NoError(t, err)
+
+	// A 120x80 camera JPEG tagged Orientation 6 displays as 80x120.
+	exif := []byte("Exif\x00\x00MM\x00\x2A\x00\x00\x00\x08\x00\x01" +
+		"\x01\x12\x00\x03\x00\x00\x00\x01\x00\x06\x00\x00\x00\x00\x00\x00")
+
+	derivatives, err := pool.Build(context.Background(), files.DerivativeJob{
+		ImageID: "img-1",
+		Folder:  "products",
+		Key:     "000001",
+		Data:    jpegWithApp1(t, 120, 80, exif),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, derivatives, 1)
+	assert.Equal(t, 80, derivatives[0].Width)
+	assert.Equal(t, 120, derivatives[0].Height)
+}
+
+func TestDerivativePoolBuild(t *testing.T) {
+	storage, err := files.NewLocalStorage(t.TempDir(), "", "secret")
+	require.NoError(t, err)
+
+	pool, err := files.NewDerivativePool(storage, files.DerivativeConfig{
+		Specs:   []files.DerivativeSpec{{Name: "thumbnail", Width: 50}, {Name: "zoom", Width: 1000}},
+		Formats: []string{"jpeg"},
+	}, nil)
+	require.NoError(t, err)
+
+	derivatives, err := pool.Build(context.Background(), files.DerivativeJob{
+		ImageID: "img-1",
+		Folder:  "products",
+		Key:     "000001",
+		Data:    encodePNG(t, 200, 100),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, derivatives, 2)
+	assert.Equal(t, "thumbnail", derivatives[0].Name)
+	assert.Equal(t, "jpeg", derivatives[0].Format)
+	assert.Equal(t, 50, derivatives[0].Width)
+	assert.Equal(t, 25, derivatives[0].Height)
+	assert.Equal(t, "/static/products/000001_thumbnail.jpg", derivatives[0].URL)
+	assert.Equal(t, 200, derivatives[1].Width)
+}
+
+func TestDerivativePoolBuildWebP(t *testing.T) {
+	dir := t.TempDir()
+	storage, err := files.NewLocalStorage(dir, "", "secret")
+	require.NoError(t, err)
+
+	pool, err := files.NewDerivativePool(storage, files.DerivativeConfig{
+		Specs:   []files.DerivativeSpec{{Name: "card", Width: 60}},
+		Formats: []string{"webp"},
+	}, nil)
+	require.NoError(t, err)
+
+	src := image.NewNRGBA(image.Rect(0, 0, 120, 80))
+	for x := 0; x < 120; x++ {
+		for y := 0; y < 80; y++ {
+			src.Set(x, y, color.NRGBA{G: 180, A: 255})
+		}
+	}
+	var upload bytes.Buffer
+	require.NoError(t, nativewebp.Encode(&upload, src, nil))
+
+	derivatives, err := pool.Build(context.Background(), files.DerivativeJob{
+		ImageID: "img-1",
+		Folder:  "products",
+		Key:     "000001",
+		Data:    upload.Bytes(),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, derivatives, 1)
+	assert.Equal(t, "webp", derivatives[0].Format)
+	assert.Equal(t, "/static/products/000001_card.webp", derivatives[0].URL)
+
+	data, err := os.ReadFile(filepath.Join(dir, "products", "000001_card.webp"))
+	require.NoError(t, err)
+	out, format, err := image.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "webp", format)
+	assert.Equal(t, 60, out.Bounds().Dx())
+	assert.Equal(t, 40, out.Bounds().Dy())
+
+	_, g, _, _ := out.At(10, 10).RGBA()
+	assert.Equal(t, uint32(180), g>>8)
+}
+
+func TestNewDerivativePoolRejectsUnknownFormats(t *testing.T) {
+	storage, err := files.NewLocalStorage(t.TempDir(), "", "secret")
+	require.NoError(t, err)
+
+	_, err = files.NewDerivativePool(storage, files.DerivativeConfig{
+		Specs:   files.DefaultDerivativeSpecs,
+		Formats: []string{"avif", "jpeg"},
+	}, nil)
+	assert.EqualError(t, err, "unknown derivative format: avif")
+
+	_, err = files.NewDerivativePool(storage, files.DerivativeConfig{Specs: files.DefaultDerivativeSpecs}, nil)
+	assert.EqualError(t, err, "no derivative formats configured")
+}
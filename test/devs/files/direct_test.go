@@ -0,0 +1,102 @@
+package files_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	response "github.com/wafi04/backend/pkg/types/res"
+	"github.com/wafi04/backend/services/files"
+)
+
+func TestLocalStorageSignedUpload(t *testing.T) {
+	data := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	tests := []struct {
+		name     string
+		ttl      time.Duration
+		tamper   func(q url.Values)
+		body     []byte
+		wantErr  string
+		wantCode string
+	}{
+		{
+			name: "valid upload",
+			ttl:  time.Minute,
+			body: data,
+		},
+		{
+			name:    "tampered size limit",
+			ttl:     time.Minute,
+			tamper:  func(q url.Values) { q.Set("max", "99999999") },
+			body:    data,
+			wantErr: "invalid upload signature",
+		},
+		{
+			name:    "expired url",
+			ttl:     -time.Minute,
+			body:    data,
+			wantErr: "invalid upload signature",
+		},
+		{
+			name:     "body larger than declared",
+			ttl:      time.Minute,
+			body:     append(data, make([]byte, 64)...),
+			wantCode: response.FileErrorTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage, err := files.NewLocalStorage(t.TempDir(), "http://localhost:8080", "secret")
+			require.NoError(t, err)
+
+			target, err := storage.PresignUpload(context.Background(), "products/IMG-1.png", "image/png", int64(len(data)), tt.ttl)
+			require.NoError(t, err)
+			assert.Equal(t, "PUT", target.Method)
+
+			u, err := url.Parse(target.URL)
+			require.NoError(t, err)
+			key := strings.TrimPrefix(u.Path, files.LocalUploadPrefix+"/")
+			assert.Equal(t, "products/IMG-1.png", key)
+
+			q := u.Query()
+			if tt.tamper != nil {
+				tt.tamper(q)
+			}
+
+			err = storage.ReceiveUpload(key, q.Get("expires"), q.Get("max"), q.Get("signature"), bytes.NewReader(tt.body))
+			switch {
+			case tt.wantErr != "":
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			case tt.wantCode != "":
+				var verr *response.FileValidationError
+				require.ErrorAs(t, err, &verr)
+				assert.Equal(t, tt.wantCode, verr.Code)
+				return
+			}
+			require.NoError(t, err)
+
+			rc, err := storage.OpenFile(context.Background(), key)
+			require.NoError(t, err)
+			stored, err := io.ReadAll(rc)
+			rc.Close()
+			require.NoError(t, err)
+			assert.Equal(t, data, stored)
+			assert.Equal(t, "http://localhost:8080/static/products/IMG-1.png", storage.FileURL(key))
+
+			// The same URL cannot replace the file once written.
+			err = storage.ReceiveUpload(key, q.Get("expires"), q.Get("max"), q.Get("signature"), bytes.NewReader(data))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "already uploaded")
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package files_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	request "github.com/wafi04/backend/pkg/types/req"
+	"github.com/wafi04/backend/services/files"
+)
+
+func tempFile(t *testing.T, data []byte) *os.File {
+	f, err := os.CreateTemp(t.TempDir(), "upload-*")
+	require.NoError(t, err)
+	_, err = f.Write(data)
+	require.NoError(t, err)
+	_, err = f.Seek(0, 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestLocalStorageUploadFile(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	tests := []struct {
+		name    string
+		req     request.FileUploadRequest
+		wantURL string
+		wantID  string
+	}{
+		{
+			name:    "extension sniffed from content",
+			req:     request.FileUploadRequest{Folder: "categories", PublicID: "CAT-1"},
+			wantURL: "http://localhost:8080/static/categories/CAT-1.png",
+			wantID:  "categories/CAT-1.png",
+		},
+		{
+			name:    "extension from file name",
+			req:     request.FileUploadRequest{Folder: "products", PublicID: "IMG-1", FileName: "photo.jpeg"},
+			wantURL: "http://localhost:8080/static/products/IMG-1.jpeg",
+			wantID:  "products/IMG-1.jpeg",
+		},
+		{
+			name:    "folder cannot escape the storage dir",
+			req:     request.FileUploadRequest{Folder: "../../etc", PublicID: "X"},
+			wantURL: "http://localhost:8080/static/etc/X.png",
+			wantID:  "etc/X.png",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			storage, err := files.NewLocalStorage(dir, "http://localhost:8080/", "secret")
+			require.NoError(t, err)
+
+			tt.req.FileData = tempFile(t, png)
+			res, err := storage.UploadFile(context.Background(), &tt.req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantURL, res.URL)
+			assert.Equal(t, tt.wantID, res.PublicID)
+
+			written, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(tt.wantURL[len("http://localhost:8080/static/"):])))
+			require.NoError(t, err)
+			assert.Equal(t, png, written)
+		})
+	}
+}
+
+func TestLocalStorageWriteOnce(t *testing.T) {
+	dir := t.TempDir()
+	storage, err := files.NewLocalStorage(dir, "http://localhost:8080/", "secret")
+	require.NoError(t, err)
+
+	upload := func(data []byte, overwrite bool) error {
+		_, err := storage.UploadFile(context.Background(), &request.FileUploadRequest{
+			FileData:  tempFile(t, data),
+			FileName:  "a.txt",
+			Folder:    "docs",
+			PublicID:  "DOC-1",
+			Overwrite: overwrite,
+		})
+		return err
+	}
+	stored := func() string {
+		data, err := os.ReadFile(filepath.Join(dir, "docs", "DOC-1.txt"))
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	require.NoError(t, upload([]byte("first"), false))
+
+	assert.ErrorIs(t, upload([]byte("second"), false), files.ErrFileExists)
+	assert.Equal(t, "first", stored())
+
+	require.NoError(t, upload([]byte("third"), true))
+	assert.Equal(t, "third", stored())
+}
@@ -0,0 +1,63 @@
+package files_test
+
+import (
+	"bytes"
+	"context"
+	"image/jpeg"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/backend/services/files"
+)
+
+func TestPosterOffset(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		expected time.Duration
+	}{
+		{name: "unknown duration", duration: 0, expected: time.Second},
+		{name: "short clip", duration: 1200 * time.Millisecond, expected: 600 * time.Millisecond},
+		{name: "long video", duration: time.Minute, expected: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, files.PosterOffset(tt.duration))
+		})
+	}
+}
+
+func TestExtractPosterFrameWithoutFFmpeg(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	_, err := files.ExtractPosterFrame(context.Background(), fakeMP4(3, 640, 360), time.Second)
+	assert.ErrorIs(t, err, files.ErrNoFrameExtractor)
+}
+
+func TestExtractPosterFrame(t *testing.T) {
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		t.Skip("ffmpeg is not installed")
+	}
+
+	video := filepath.Join(t.TempDir(), "clip.mp4")
+	out, err := exec.Command(ffmpeg, "-v", "error", "-f", "lavfi", "-i", "testsrc=size=128x96:duration=2",
+		"-pix_fmt", "yuv420p", video).CombinedOutput()
+	require.NoError(t, err, string(out))
+	data, err := os.ReadFile(video)
+	require.NoError(t, err)
+
+	frame, err := files.ExtractPosterFrame(context.Background(), data, time.Second)
+	require.NoError(t, err)
+
+	img, err := jpeg.Decode(bytes.NewReader(frame))
+	require.NoError(t, err)
+	assert.Equal(t, 128, img.Bounds().Dx())
+	assert.Equal(t, 96, img.Bounds().Dy())
+}
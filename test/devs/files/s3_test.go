@@ -0,0 +1,70 @@
+package files_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	request "github.com/wafi04/backend/pkg/types/req"
+	"github.com/wafi04/backend/services/files"
+)
+
+// fakeBucket honours If-None-Match: * on PUT like S3 does.
+type fakeBucket struct {
+	mu      sync.Mutex
+	objects map[string]string
+	puts    int
+}
+
+func (b *fakeBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.puts++
+	body, _ := io.ReadAll(r.Body)
+	if _, ok := b.objects[r.URL.Path]; ok && r.Header.Get("If-None-Match") == "*" {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	b.objects[r.URL.Path] = string(body)
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestS3StorageWriteOnce(t *testing.T) {
+	bucket := &fakeBucket{objects: map[string]string{}}
+	srv := httptest.NewServer(bucket)
+	defer srv.Close()
+
+	storage, err := files.NewS3Storage(files.Config{
+		S3Endpoint:  srv.URL,
+		S3Bucket:    "media",
+		S3AccessKey: "key",
+		S3SecretKey: "secret",
+	})
+	require.NoError(t, err)
+
+	upload := func(data string, overwrite bool) error {
+		_, err := storage.UploadFile(context.Background(), &request.FileUploadRequest{
+			FileData:  tempFile(t, []byte(data)),
+			FileName:  "a.txt",
+			Folder:    "docs",
+			PublicID:  "DOC-1",
+			Overwrite: overwrite,
+		})
+		return err
+	}
+
+	require.NoError(t, upload("first", false))
+
+	assert.ErrorIs(t, upload("second", false), files.ErrFileExists)
+	assert.Equal(t, "first", bucket.objects["/media/docs/DOC-1.txt"])
+
+	require.NoError(t, upload("third", true))
+	assert.Equal(t, "third", bucket.objects["/media/docs/DOC-1.txt"])
+	assert.Equal(t, 3, bucket.puts)
+}
@@ -0,0 +1,112 @@
+package files_test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+	"github.com/wafi04/backend/services/files"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))))
+	return buf.Bytes()
+}
+
+// jpegWithExif encodes a JPEG and inserts an APP1 Exif segment after SOI.
+func jpegWithExif(t *testing.T, w, h int) []byte {
+	return jpegWithApp1(t, w, h, []byte("Exif\x00\x00GPS-SECRET"))
+}
+
+// jpegWithApp1 encodes a JPEG and inserts an APP1 segment holding payload
+// after SOI.
+func jpegWithApp1(t *testing.T, w, h int, payload []byte) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h)), nil))
+	data := buf.Bytes()
+
+	segment := []byte{0xFF, 0xE1, 0, byte(len(payload) + 2)}
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func TestValidateUpload(t *testing.T) {
+	rule := files.DefaultUploadRules[request.IMAGE]
+
+	tests := []struct {
+		name     string
+		data     []byte
+		rule     files.UploadRule
+		wantCode string
+	}{
+		{name: "valid png", data: encodePNG(t, 200, 100), rule: rule},
+		{name: "text disguised as image", data: []byte("hello, not an image"), rule: rule, wantCode: response.FileErrorUnsupported},
+		{name: "too small", data: encodePNG(t, 10, 10), rule: rule, wantCode: response.FileErrorDimensions},
+		{name: "too wide", data: encodePNG(t, 1000, 100), rule: rule, wantCode: response.FileErrorAspectRatio},
+		{name: "too large", data: encodePNG(t, 200, 200), rule: files.UploadRule{MaxBytes: 10, AllowedTypes: rule.AllowedTypes}, wantCode: response.FileErrorTooLarge},
+		{name: "truncated image", data: encodePNG(t, 200, 200)[:12], rule: rule, wantCode: response.FileErrorUnreadable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := files.ValidateUpload(bytes.NewReader(tt.data), tt.rule)
+			if tt.wantCode == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			var validationErr *response.FileValidationError
+			require.True(t, errors.As(err, &validationErr), "got %v", err)
+			assert.Equal(t, tt.wantCode, validationErr.Code)
+		})
+	}
+}
+
+func TestValidateUploadStripsExif(t *testing.T) {
+	data := jpegWithExif(t, 120, 120)
+	require.True(t, bytes.Contains(data, []byte("GPS-SECRET")))
+
+	cleaned, err := files.ValidateUpload(bytes.NewReader(data), files.DefaultUploadRules[request.IMAGE])
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(cleaned, []byte("GPS-SECRET")))
+
+	img, err := jpeg.Decode(bytes.NewReader(cleaned))
+	require.NoError(t, err)
+	assert.Equal(t, 120, img.Bounds().Dx())
+}
+
+func TestValidateUploadKeepsOrientation(t *testing.T) {
+	// Little-endian TIFF with two IFD0 entries: Orientation = 6 and an
+	// ASCII Artist tag pointing at data after the IFD.
+	tiff := []byte{
+		'I', 'I', 0x2A, 0x00, 0x08, 0x00, 0x00, 0x00,
+		0x02, 0x00,
+		0x12, 0x01, 0x03, 0x00, 0x01, 0x00, 0x00, 0x00, 0x06, 0x00, 0x00, 0x00,
+		0x3B, 0x01, 0x02, 0x00, 0x0B, 0x00, 0x00, 0x00, 0x26, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00,
+	}
+	tiff = append(tiff, "GPS-SECRET\x00"...)
+	data := jpegWithApp1(t, 120, 80, append([]byte("Exif\x00\x00"), tiff...))
+
+	cleaned, err := files.ValidateUpload(bytes.NewReader(data), files.DefaultUploadRules[request.IMAGE])
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(cleaned, []byte("GPS-SECRET")))
+
+	// The replacement block is big-endian and holds only Orientation = 6.
+	assert.True(t, bytes.Contains(cleaned, []byte("Exif\x00\x00MM\x00\x2A\x00\x00\x00\x08\x00\x01\x01\x12\x00\x03\x00\x00\x00\x01\x00\x06")))
+
+	img, err := jpeg.Decode(bytes.NewReader(cleaned))
+	require.NoError(t, err)
+	assert.Equal(t, 120, img.Bounds().Dx())
+}
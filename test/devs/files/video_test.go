@@ -0,0 +1,132 @@
+package files_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+	"github.com/wafi04/backend/services/files"
+)
+
+func mp4Box(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	out := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	out = append(out, typ...)
+	return append(out, body...)
+}
+
+// fakeMP4 builds the boxes ProbeVideo reads: ftyp, and moov with mvhd and
+// one video tkhd.
+func fakeMP4(seconds uint32, w, h uint16) []byte {
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], 1000)
+	binary.BigEndian.PutUint32(mvhd[16:], seconds*1000)
+
+	tkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(tkhd[76:], uint32(w)<<16)
+	binary.BigEndian.PutUint32(tkhd[80:], uint32(h)<<16)
+
+	return bytes.Join([][]byte{
+		mp4Box("ftyp", []byte("mp42\x00\x00\x00\x00mp42isom")),
+		mp4Box("moov", mp4Box("mvhd", mvhd), mp4Box("trak", mp4Box("tkhd", tkhd))),
+		mp4Box("mdat", make([]byte, 32)),
+	}, nil)
+}
+
+func ebml(id []byte, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	out := append([]byte{}, id...)
+	out = append(out, 0x08, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint64(out[len(id):], uint64(len(body))|0x01<<56)
+	return append(out, body...)
+}
+
+func fakeWebM(seconds float64, w, h uint16) []byte {
+	duration := binary.BigEndian.AppendUint64(nil, math.Float64bits(seconds*1000))
+	return bytes.Join([][]byte{
+		ebml([]byte{0x1A, 0x45, 0xDF, 0xA3}, ebml([]byte{0x42, 0x82}, []byte("webm"))),
+		ebml([]byte{0x18, 0x53, 0x80, 0x67},
+			ebml([]byte{0x15, 0x49, 0xA9, 0x66},
+				ebml([]byte{0x2A, 0xD7, 0xB1}, []byte{0x0F, 0x42, 0x40}),
+				ebml([]byte{0x44, 0x89}, duration),
+			),
+			ebml([]byte{0x16, 0x54, 0xAE, 0x6B},
+				ebml([]byte{0xAE}, ebml([]byte{0xE0},
+					ebml([]byte{0xB0}, binary.BigEndian.AppendUint16(nil, w)),
+					ebml([]byte{0xBA}, binary.BigEndian.AppendUint16(nil, h)),
+				)),
+			),
+			ebml([]byte{0x1F, 0x43, 0xB6, 0x75}, make([]byte, 16)),
+		),
+	}, nil)
+}
+
+func TestProbeVideo(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         []byte
+		wantDuration time.Duration
+		wantW, wantH int
+		wantErr      bool
+	}{
+		{name: "mp4", data: fakeMP4(42, 1280, 720), wantDuration: 42 * time.Second, wantW: 1280, wantH: 720},
+		{name: "webm", data: fakeWebM(7.5, 640, 360), wantDuration: 7500 * time.Millisecond, wantW: 640, wantH: 360},
+		{name: "truncated mp4", data: fakeMP4(42, 1280, 720)[:60], wantErr: true},
+		{name: "not a video", data: []byte("%PDF-1.7"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := files.ProbeVideo(tt.data)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDuration, info.Duration)
+			assert.Equal(t, tt.wantW, info.Width)
+			assert.Equal(t, tt.wantH, info.Height)
+		})
+	}
+}
+
+func TestValidateVideoAndDocument(t *testing.T) {
+	video := files.DefaultUploadRules[request.VIDEO]
+	document := files.DefaultUploadRules[request.DOCUMENT]
+
+	tests := []struct {
+		name     string
+		data     []byte
+		rule     files.UploadRule
+		wantCode string
+	}{
+		{name: "valid mp4", data: fakeMP4(30, 1920, 1080), rule: video},
+		{name: "valid webm", data: fakeWebM(12, 640, 360), rule: video},
+		{name: "video too long", data: fakeMP4(3600, 1920, 1080), rule: video, wantCode: response.FileErrorDuration},
+		{name: "video too large", data: fakeMP4(30, 7680, 4320), rule: video, wantCode: response.FileErrorDimensions},
+		{name: "audio only", data: fakeMP4(30, 0, 0), rule: video, wantCode: response.FileErrorVideo},
+		{name: "valid pdf", data: []byte("%PDF-1.7\n1 0 obj\n<<>>\nendobj\n%%EOF\n"), rule: document},
+		{name: "truncated pdf", data: []byte("%PDF-1.7\n1 0 obj\n<<>>\n"), rule: document, wantCode: response.FileErrorDocument},
+		{name: "pdf with script", data: []byte("%PDF-1.7\n1 0 obj\n<< /S /JavaScript /JS (app.alert(1)) >>\nendobj\n%%EOF\n"), rule: document, wantCode: response.FileErrorDocument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := files.ValidateUpload(bytes.NewReader(tt.data), tt.rule)
+			if tt.wantCode == "" {
+				require.NoError(t, err)
+				return
+			}
+			var verr *response.FileValidationError
+			require.True(t, errors.As(err, &verr), "got %v", err)
+			assert.Equal(t, tt.wantCode, verr.Code)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package media_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+	"github.com/wafi04/backend/services/media"
+)
+
+// expiredRepo reports every asset it holds as unreferenced and deletable.
+type expiredRepo struct {
+	memoryRepo
+	expired []*types.MediaAsset
+}
+
+func (r *expiredRepo) SyncReferences(ctx context.Context) (int64, error) {
+	return int64(len(r.expired)), nil
+}
+
+func (r *expiredRepo) ListUnreferenced(ctx context.Context, before time.Time, limit int) ([]*types.MediaAsset, error) {
+	return r.expired, nil
+}
+
+func (r *expiredRepo) DeleteAsset(ctx context.Context, id string, before time.Time) (bool, error) {
+	return true, nil
+}
+
+// recordingStorage remembers the content type each delete was made with.
+type recordingStorage struct {
+	deleted map[string]string
+}
+
+func (s *recordingStorage) UploadFile(ctx context.Context, req *request.FileUploadRequest) (*response.FileUploadResponse, error) {
+	return nil, nil
+}
+
+func (s *recordingStorage) DeleteFile(ctx context.Context, publicID, contentType string) error {
+	s.deleted[publicID] = contentType
+	return nil
+}
+
+func TestCollectorDeletesWithContentType(t *testing.T) {
+	repo := &expiredRepo{expired: []*types.MediaAsset{
+		{ID: "1", PublicID: "products/IMG-1", ContentType: "image/png"},
+		{ID: "2", PublicID: "products/VID-1", ContentType: "video/mp4"},
+		{ID: "3", PublicID: "products/DOC-1", ContentType: "application/pdf"},
+	}}
+	storage := &recordingStorage{deleted: map[string]string{}}
+
+	require.NoError(t, media.NewCollector(repo, storage, time.Hour).Collect(context.Background()))
+
+	assert.Equal(t, map[string]string{
+		"products/IMG-1": "image/png",
+		"products/VID-1": "video/mp4",
+		"products/DOC-1": "application/pdf",
+	}, storage.deleted)
+}
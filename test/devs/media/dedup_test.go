@@ -0,0 +1,80 @@
+package media_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	"github.com/wafi04/backend/services/files"
+	"github.com/wafi04/backend/services/media"
+)
+
+// memoryRepo keeps assets in a map keyed by hash.
+type memoryRepo struct {
+	assets map[string]*types.MediaAsset
+}
+
+func (r *memoryRepo) FindByHash(ctx context.Context, hash string) (*types.MediaAsset, error) {
+	return r.assets[hash], nil
+}
+
+func (r *memoryRepo) CreateAsset(ctx context.Context, asset *types.MediaAsset) (*types.MediaAsset, error) {
+	if existing, ok := r.assets[asset.Hash]; ok {
+		return existing, nil
+	}
+	r.assets[asset.Hash] = asset
+	return asset, nil
+}
+
+func (r *memoryRepo) SyncReferences(ctx context.Context) (int64, error) { return 0, nil }
+
+func (r *memoryRepo) ListUnreferenced(ctx context.Context, before time.Time, limit int) ([]*types.MediaAsset, error) {
+	return nil, nil
+}
+
+func (r *memoryRepo) DeleteAsset(ctx context.Context, id string, before time.Time) (bool, error) {
+	return false, nil
+}
+
+type memFile struct{ *bytes.Reader }
+
+func (memFile) Close() error { return nil }
+
+func TestDedupStorageUploadFile(t *testing.T) {
+	dir := t.TempDir()
+	local, err := files.NewLocalStorage(dir, "", "secret")
+	require.NoError(t, err)
+
+	repo := &memoryRepo{assets: map[string]*types.MediaAsset{}}
+	storage := media.NewDedupStorage(local, repo)
+
+	upload := func(data, publicID string) string {
+		res, err := storage.UploadFile(context.Background(), &request.FileUploadRequest{
+			FileData: memFile{bytes.NewReader([]byte(data))},
+			FileName: "photo.txt",
+			Folder:   "products",
+			PublicID: publicID,
+		})
+		require.NoError(t, err)
+		return res.URL
+	}
+
+	first := upload("same content", "A")
+	second := upload("same content", "B")
+	third := upload("other content", "C")
+
+	assert.Equal(t, "/static/products/A.txt", first)
+	assert.Equal(t, first, second)
+	assert.Equal(t, "/static/products/C.txt", third)
+	assert.Len(t, repo.assets, 2)
+
+	entries, err := os.ReadDir(dir + "/products")
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
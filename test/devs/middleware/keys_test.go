@@ -0,0 +1,177 @@
+package middleware_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/backend/pkg/middleware"
+	"github.com/wafi04/backend/pkg/types"
+)
+
+func pemKey(t *testing.T, typ string, der []byte, err error) []byte {
+	t.Helper()
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+}
+
+func rsaKey(t *testing.T, id string) *middleware.Key {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key, err := middleware.ParseKey(id, pemKey(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(priv), nil))
+	require.NoError(t, err)
+	return key
+}
+
+func edKey(t *testing.T, id string) (*middleware.Key, ed25519.PublicKey) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	key, err := middleware.ParseKey(id, pemKey(t, "PRIVATE KEY", der, err))
+	require.NoError(t, err)
+	return key, pub
+}
+
+func TestKeyRotation(t *testing.T) {
+	user := &types.UserInfo{UserID: "user-1", Email: "a@example.com", Role: "USER"}
+
+	old, oldPub := edKey(t, "2024-01")
+	current := rsaKey(t, "2024-02")
+
+	before, err := middleware.NewKeySet("2024-01", old)
+	require.NoError(t, err)
+	middleware.SetKeySet(before)
+	oldToken, err := middleware.GenerateToken(user, 1)
+	require.NoError(t, err)
+
+	// After rotation the old key is kept for verification only.
+	der, err := x509.MarshalPKIXPublicKey(oldPub)
+	retired, err := middleware.ParseKey("2024-01", pemKey(t, "PUBLIC KEY", der, err))
+	require.NoError(t, err)
+	assert.Nil(t, retired.Private)
+
+	_, err = middleware.NewKeySet("2024-01", retired, current)
+	assert.ErrorContains(t, err, "has no private key")
+
+	after, err := middleware.NewKeySet("2024-02", retired, current)
+	require.NoError(t, err)
+	middleware.SetKeySet(after)
+
+	newToken, err := middleware.GenerateToken(user, 1)
+	require.NoError(t, err)
+	parsed, _, err := new(jwt.Parser).ParseUnverified(newToken, &middleware.JWTClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "RS256", parsed.Method.Alg())
+	assert.Equal(t, "2024-02", parsed.Header["kid"])
+
+	for _, token := range []string{oldToken, newToken} {
+		claims, err := middleware.ValidateToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", claims.UserID)
+	}
+
+	// Once the old key is dropped its tokens stop verifying.
+	dropped, err := middleware.NewKeySet("2024-02", current)
+	require.NoError(t, err)
+	middleware.SetKeySet(dropped)
+	_, err = middleware.ValidateToken(oldToken)
+	assert.ErrorContains(t, err, "unknown signing key")
+	_, err = middleware.ValidateToken(newToken)
+	assert.NoError(t, err)
+}
+
+func TestValidateTokenRejectsForgedTokens(t *testing.T) {
+	key := rsaKey(t, "main")
+	ks, err := middleware.NewKeySet("main", key)
+	require.NoError(t, err)
+	middleware.SetKeySet(ks)
+
+	claims := middleware.JWTClaims{
+		UserID:         "user-1",
+		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Hour).Unix()},
+	}
+
+	tests := []struct {
+		name    string
+		token   func() string
+		wantErr string
+	}{
+		{
+			name: "hmac signed with the public key",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+				token.Header["kid"] = "main"
+				der, err := x509.MarshalPKIXPublicKey(key.Public)
+				signed, err := token.SignedString(pemKey(t, "PUBLIC KEY", der, err))
+				require.NoError(t, err)
+				return signed
+			},
+			wantErr: "unexpected signing method",
+		},
+		{
+			name: "missing kid",
+			token: func() string {
+				signed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(key.Private)
+				require.NoError(t, err)
+				return signed
+			},
+			wantErr: "unknown signing key",
+		},
+		{
+			name: "signed by a key we do not trust",
+			token: func() string {
+				other := rsaKey(t, "main")
+				token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+				token.Header["kid"] = "main"
+				signed, err := token.SignedString(other.Private)
+				require.NoError(t, err)
+				return signed
+			},
+			wantErr: "verification error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := middleware.ValidateToken(tt.token())
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestParseKeyRejectsWeakRSA(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	_, err = middleware.ParseKey("weak", pemKey(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(priv), nil))
+	assert.ErrorContains(t, err, "at least 2048")
+}
+
+func TestJWKS(t *testing.T) {
+	ed, _ := edKey(t, "b-ed")
+	rs := rsaKey(t, "a-rsa")
+	ks, err := middleware.NewKeySet("b-ed", ed, rs)
+	require.NoError(t, err)
+
+	set := ks.JWKS()
+	require.Len(t, set.Keys, 2)
+
+	assert.Equal(t, "a-rsa", set.Keys[0].Kid)
+	assert.Equal(t, "RSA", set.Keys[0].Kty)
+	assert.Equal(t, "RS256", set.Keys[0].Alg)
+	assert.Equal(t, "AQAB", set.Keys[0].E)
+	assert.NotEmpty(t, set.Keys[0].N)
+
+	assert.Equal(t, "b-ed", set.Keys[1].Kid)
+	assert.Equal(t, "OKP", set.Keys[1].Kty)
+	assert.Equal(t, "Ed25519", set.Keys[1].Crv)
+	assert.Equal(t, "EdDSA", set.Keys[1].Alg)
+	assert.Equal(t, "sig", set.Keys[1].Use)
+	assert.Len(t, set.Keys[1].X, 43)
+}
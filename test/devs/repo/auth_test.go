@@ -0,0 +1,165 @@
+package inventoryrepo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	request "github.com/wafi04/backend/pkg/types/req"
+	authrepo "github.com/wafi04/backend/services/auth/repository"
+)
+
+func TestResetPassword(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := &authrepo.AuthRepository{DB: sqlx.NewDb(db, "sqlmock")}
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantSuccess  bool
+	}{
+		{
+			name: "Spends Token And Revokes Sessions",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`UPDATE verification_tokens\s+SET is_used = true\s+WHERE token = \$1`).
+					WithArgs("HASH", authrepo.TokenTypePasswordReset).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("USER-1"))
+				mock.ExpectQuery(`UPDATE users\s+SET password_hash = \$1`).
+					WithArgs("BCRYPT", "USER-1").
+					WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
+				mock.ExpectExec(`DELETE FROM sessions WHERE user_id = \$1`).
+					WithArgs("USER-1").
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectCommit()
+			},
+			wantSuccess: true,
+		},
+		{
+			name: "Used Or Expired Token",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`UPDATE verification_tokens\s+SET is_used = true\s+WHERE token = \$1`).
+					WithArgs("HASH", authrepo.TokenTypePasswordReset).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+				mock.ExpectRollback()
+			},
+			wantSuccess: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			resp, err := repo.ResetPassword(context.Background(), "HASH", "BCRYPT")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantSuccess, resp.Success)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := &authrepo.AuthRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	oldHash := authrepo.HashRefreshToken("OLD")
+	tokenColumns := []string{"family_id", "session_id", "user_id", "expires_at", "rotated", "revoked", "is_active"}
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantErr      error
+		wantInvalid  bool
+	}{
+		{
+			name: "Rotates Into The Same Family",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM refresh_tokens t`).
+					WithArgs(oldHash).
+					WillReturnRows(sqlmock.NewRows(tokenColumns).
+						AddRow("FAM-1", "SES-1", "USER-1", time.Now().Add(time.Hour), false, false, true))
+				mock.ExpectQuery(`SELECT user_id, name, email, role, is_email_verified`).
+					WithArgs("USER-1").
+					WillReturnRows(sqlmock.NewRows([]string{"user_id", "name", "email", "role", "is_email_verified"}).
+						AddRow("USER-1", "Ana", "ana@example.com", "user", true))
+				mock.ExpectExec(`INSERT INTO refresh_tokens`).
+					WithArgs(sqlmock.AnyArg(), "FAM-1", "SES-1", "USER-1", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`SET rotated_at = NOW\(\), replaced_by = \$1`).
+					WithArgs(sqlmock.AnyArg(), oldHash).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE sessions\s+SET access_token = \$1, refresh_token = \$2`).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "SES-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Reused Token Revokes The Family",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM refresh_tokens t`).
+					WithArgs(oldHash).
+					WillReturnRows(sqlmock.NewRows(tokenColumns).
+						AddRow("FAM-1", "SES-1", "USER-1", time.Now().Add(time.Hour), true, false, true))
+				mock.ExpectExec(`UPDATE refresh_tokens\s+SET revoked_at = NOW\(\)\s+WHERE family_id = \$1`).
+					WithArgs("FAM-1").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`UPDATE sessions SET is_active = false WHERE session_id = \$1`).
+					WithArgs("SES-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: authrepo.ErrRefreshTokenReuse,
+		},
+		{
+			name: "Token From A Revoked Family",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM refresh_tokens t`).
+					WithArgs(oldHash).
+					WillReturnRows(sqlmock.NewRows(tokenColumns).
+						AddRow("FAM-1", "SES-1", "USER-1", time.Now().Add(time.Hour), true, true, false))
+				mock.ExpectRollback()
+			},
+			wantInvalid: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			resp, err := repo.RefreshToken(context.Background(), &request.RefreshTokenRequest{
+				RefreshToken: "OLD",
+				SessionID:    "SES-1",
+			})
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			case tt.wantInvalid:
+				assert.EqualError(t, err, "invalid refresh token")
+			default:
+				assert.NoError(t, err)
+				assert.NotEqual(t, "OLD", resp.RefreshToken)
+				assert.NotEmpty(t, resp.AccessToken)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package inventoryrepo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	"github.com/wafi04/backend/services/cart"
+)
+
+type bundleComponent struct {
+	inventoryID string
+	quantity    int64
+	available   int64
+}
+
+// expectLockBundle expects reserveBundle to lock the given component rows of
+// BUN-1, which has itemCount bundle items.
+func expectLockBundle(mock sqlmock.Sqlmock, quantity int64, itemCount int, components ...bundleComponent) {
+	rows := sqlmock.NewRows([]string{"id", "quantity", "available_stock"})
+	for _, c := range components {
+		rows.AddRow(c.inventoryID, c.quantity, c.available)
+	}
+	mock.ExpectQuery(`FROM bundle_items b JOIN inventory i .* FOR UPDATE OF i`).
+		WithArgs("BUN-1", quantity).
+		WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM bundle_items WHERE bundle_id = \$1`).
+		WithArgs("BUN-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(itemCount))
+}
+
+func expectReserveComponents(mock sqlmock.Sqlmock, cartItemID interface{}, components ...bundleComponent) {
+	for _, c := range components {
+		mock.ExpectExec(`UPDATE inventory SET reserved_stock = COALESCE\(reserved_stock, 0\) \+ \$1`).
+			WithArgs(c.quantity, c.inventoryID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO inventory_reservations`).
+			WithArgs(sqlmock.AnyArg(), cartItemID, c.inventoryID, c.quantity).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+}
+
+func expectRelease(mock sqlmock.Sqlmock, cartItemIDs string) {
+	mock.ExpectExec(`UPDATE inventory i SET reserved_stock = i.reserved_stock - r.quantity`).
+		WithArgs(cartItemIDs).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`DELETE FROM inventory_reservations WHERE cart_item_id = ANY\(\$1\)`).
+		WithArgs(cartItemIDs).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+}
+
+func TestAddBundleToCart(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := cart.NewCartRepository(sqlx.NewDb(db, "sqlmock"))
+
+	expectNewBundleLine := func() {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT cart_id FROM carts WHERE user_id = \$1`).
+			WithArgs("USER-1").
+			WillReturnRows(sqlmock.NewRows([]string{"cart_id"}).AddRow("CART-1"))
+		mock.ExpectQuery(`SELECT price FROM products WHERE id = \$1 AND product_type = \$2 AND deleted_at IS NULL AND status = 'published'`).
+			WithArgs("BUN-1", types.ProductTypeBundle).
+			WillReturnRows(sqlmock.NewRows([]string{"price"}).AddRow(90.0))
+		mock.ExpectQuery(`SELECT cart_item_id, quantity FROM cart_items WHERE cart_id = \$1 AND bundle_id = \$2`).
+			WithArgs("CART-1", "BUN-1").
+			WillReturnRows(sqlmock.NewRows([]string{"cart_item_id", "quantity"}))
+		mock.ExpectExec(`INSERT INTO cart_items`).
+			WithArgs(sqlmock.AnyArg(), "CART-1", "BUN-1", int64(2), 180.0).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	shirt := bundleComponent{inventoryID: "INV-1", quantity: 2, available: 10}
+	shorts := bundleComponent{inventoryID: "INV-2", quantity: 4, available: 4}
+
+	tests := []struct {
+		name          string
+		mockBehavior  func()
+		expectedError string
+	}{
+		{
+			name: "Reserves Every Component",
+			mockBehavior: func() {
+				expectNewBundleLine()
+				expectLockBundle(mock, 2, 2, shirt, shorts)
+				expectReserveComponents(mock, sqlmock.AnyArg(), shirt, shorts)
+				mock.ExpectExec(`UPDATE carts`).
+					WithArgs("CART-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Fails When A Component Has No Inventory Row",
+			mockBehavior: func() {
+				expectNewBundleLine()
+				expectLockBundle(mock, 2, 2, shirt)
+				mock.ExpectRollback()
+			},
+			expectedError: "1 of 2 components have no inventory",
+		},
+		{
+			name: "Fails When A Component Is Short",
+			mockBehavior: func() {
+				expectNewBundleLine()
+				expectLockBundle(mock, 2, 2, shirt, bundleComponent{inventoryID: "INV-2", quantity: 4, available: 3})
+				mock.ExpectRollback()
+			},
+			expectedError: "not enough stock for bundle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			_, err := repo.AddCart(context.Background(), &request.CartRequest{UserID: "USER-1", BundleID: "BUN-1", Quantity: 2})
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpdateBundleQuantity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := cart.NewCartRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM cart_items WHERE cart_item_id = \$1`).
+		WithArgs("CI-1").
+		WillReturnRows(sqlmock.NewRows([]string{"cart_item_id", "cart_id", "product_variant_id", "bundle_id", "quantity", "size", "sub_total", "created_at", "updated_at"}).
+			AddRow("CI-1", "CART-1", "", "BUN-1", 1, "", 90.0, now, now))
+	mock.ExpectQuery(`SELECT price FROM products`).
+		WithArgs("BUN-1", types.ProductTypeBundle).
+		WillReturnRows(sqlmock.NewRows([]string{"price"}).AddRow(90.0))
+	expectRelease(mock, "{\"CI-1\"}")
+	component := bundleComponent{inventoryID: "INV-1", quantity: 3, available: 5}
+	expectLockBundle(mock, 3, 1, component)
+	expectReserveComponents(mock, "CI-1", component)
+	mock.ExpectQuery(`UPDATE cart_items SET quantity = \$1`).
+		WithArgs(int64(3), 270.0, "", sqlmock.AnyArg(), "CI-1").
+		WillReturnRows(sqlmock.NewRows([]string{"cart_item_id", "cart_id", "product_variant_id", "quantity", "size", "sub_total", "created_at", "updated_at"}).
+			AddRow("CI-1", "CART-1", "", 3, "", 270.0, now, now))
+	mock.ExpectExec(`UPDATE carts`).
+		WithArgs("CART-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	item, err := repo.UpdateQuantity(context.Background(), &request.UpdateQuantity{CartItemID: "CI-1", Quantity: 3})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), item.Quantity)
+	assert.Equal(t, 270.0, item.SubTotal)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRemoveBundleFromCart(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := cart.NewCartRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT cart_id FROM cart_items WHERE cart_item_id = \$1`).
+		WithArgs("CI-1").
+		WillReturnRows(sqlmock.NewRows([]string{"cart_id"}).AddRow("CART-1"))
+	expectRelease(mock, "{\"CI-1\"}")
+	mock.ExpectExec(`DELETE FROM cart_items WHERE cart_item_id = \$1`).
+		WithArgs("CI-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE carts`).
+		WithArgs("CART-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, err = repo.RemoveFromCart(context.Background(), &request.ReqRemoveCartByID{CartItemID: "CI-1", UserID: "USER-1"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClearCartReleasesReservations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := cart.NewCartRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT ci.cart_item_id FROM cart_items ci`).
+		WithArgs("USER-1").
+		WillReturnRows(sqlmock.NewRows([]string{"cart_item_id"}).AddRow("CI-1").AddRow("CI-2"))
+	expectRelease(mock, "{\"CI-1\",\"CI-2\"}")
+	mock.ExpectExec(`DELETE FROM carts WHERE user_id = \$1`).
+		WithArgs("USER-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, err = repo.ClearCart(context.Background(), &request.ClearCart{UserID: "USER-1"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,85 @@
+package inventoryrepo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	request "github.com/wafi04/backend/pkg/types/req"
+	"github.com/wafi04/backend/services/cart"
+)
+
+func TestAddCart(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := cart.NewCartRepository(sqlx.NewDb(db, "sqlmock"))
+
+	tests := []struct {
+		name          string
+		req           *request.CartRequest
+		mockBehavior  func()
+		expectedError string
+	}{
+		{
+			name: "Rejects Trashed Variant",
+			req:  &request.CartRequest{UserID: "USER-1", VariantID: "VAR-1", Size: "M", Quantity: 1, Price: 1},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT cart_id FROM carts WHERE user_id = \$1`).
+					WithArgs("USER-1").
+					WillReturnRows(sqlmock.NewRows([]string{"cart_id"}).AddRow("CART-1"))
+				mock.ExpectQuery(`WHERE v.id = \$1 AND v.deleted_at IS NULL AND p.deleted_at IS NULL AND p.status = 'published' AND \(p.unpublish_at IS NULL OR p.unpublish_at > NOW\(\)\)`).
+					WithArgs("VAR-1").
+					WillReturnRows(sqlmock.NewRows([]string{"price"}))
+				mock.ExpectRollback()
+			},
+			expectedError: "product variant not found",
+		},
+		{
+			name: "Prices Line From Catalog",
+			req:  &request.CartRequest{UserID: "USER-1", VariantID: "VAR-2", Size: "M", Quantity: 2, Price: 1},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT cart_id FROM carts WHERE user_id = \$1`).
+					WithArgs("USER-1").
+					WillReturnRows(sqlmock.NewRows([]string{"cart_id"}).AddRow("CART-1"))
+				mock.ExpectQuery(`WHERE v.id = \$1 AND v.deleted_at IS NULL AND p.deleted_at IS NULL AND p.status = 'published' AND \(p.unpublish_at IS NULL OR p.unpublish_at > NOW\(\)\)`).
+					WithArgs("VAR-2").
+					WillReturnRows(sqlmock.NewRows([]string{"price"}).AddRow(25.0))
+				mock.ExpectQuery(`SELECT cart_item_id, quantity FROM cart_items`).
+					WithArgs("CART-1", "VAR-2", "M").
+					WillReturnRows(sqlmock.NewRows([]string{"cart_item_id", "quantity"}))
+				mock.ExpectExec(`INSERT INTO cart_items`).
+					WithArgs(sqlmock.AnyArg(), "CART-1", "VAR-2", "M", int64(2), 50.0).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE carts`).
+					WithArgs("CART-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			result, err := repo.AddCart(context.Background(), tt.req)
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "VAR-2", result.VariantID)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
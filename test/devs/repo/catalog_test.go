@@ -0,0 +1,160 @@
+package inventoryrepo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/wafi04/backend/pkg/types"
+	productRepository "github.com/wafi04/backend/services/product/repository"
+)
+
+func TestUpsertCatalogProduct(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := &productRepository.Database{DB: sqlx.NewDb(db, "sqlmock")}
+
+	product := func() *types.CatalogProduct {
+		return &types.CatalogProduct{
+			SKU:         "TEE-1",
+			Name:        "Basic Tee",
+			Description: "Cotton tee",
+			Price:       20,
+			CategoryID:  "CAT-1",
+			Status:      types.ProductStatusDraft,
+			Variants: []*types.CatalogVariant{{
+				SKU:       "TEE-1-BLK",
+				Color:     "black",
+				Images:    []string{"https://cdn.example.com/tee.jpg"},
+				Inventory: []*types.CatalogInventory{{Size: "M", Stock: 5}},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		dryRun          bool
+		keepStatus      bool
+		mockBehavior    func()
+		expectedCreated bool
+	}{
+		{
+			name: "Creates Product, Variant And Inventory",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT id, name, slug, price FROM products WHERE sku = \$1`).
+					WithArgs("TEE-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "price"}))
+				mock.ExpectQuery(`SELECT slug FROM products`).
+					WithArgs("basic-tee", sqlmock.AnyArg(), "product").
+					WillReturnRows(sqlmock.NewRows([]string{"slug"}))
+				mock.ExpectExec(`INSERT INTO products`).
+					WithArgs(sqlmock.AnyArg(), "Basic Tee", "basic-tee", "", "Cotton tee", "TEE-1", 20.0, "CAT-1", "draft", nil, nil).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE price_history`).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`INSERT INTO price_history`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT id FROM product_variants`).
+					WithArgs(sqlmock.AnyArg(), "TEE-1-BLK").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectExec(`INSERT INTO product_variants`).
+					WithArgs(sqlmock.AnyArg(), "black", "TEE-1-BLK", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO product_images`).
+					WithArgs(sqlmock.AnyArg(), "https://cdn.example.com/tee.jpg", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE inventory`).
+					WithArgs(5, sqlmock.AnyArg(), "M").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`INSERT INTO inventory`).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "M", 5).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedCreated: true,
+		},
+		{
+			name:   "Dry Run Updates Existing Product And Rolls Back",
+			dryRun: true,
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT id, name, slug, price FROM products WHERE sku = \$1`).
+					WithArgs("TEE-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "price"}).
+						AddRow("PROD-1", "Basic Tee", "basic-tee", 20.0))
+				mock.ExpectExec(`UPDATE products SET name = \$1, slug = \$2`).
+					WithArgs("Basic Tee", "basic-tee", "", "Cotton tee", 20.0, "CAT-1", "PROD-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE products SET status = \$1, publish_at = \$2, unpublish_at = \$3 WHERE id = \$4`).
+					WithArgs("draft", nil, nil, "PROD-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT id FROM product_variants`).
+					WithArgs("PROD-1", "TEE-1-BLK").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("VAR-1"))
+				mock.ExpectExec(`UPDATE product_variants SET color = \$1 WHERE id = \$2`).
+					WithArgs("black", "VAR-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO product_images`).
+					WithArgs(sqlmock.AnyArg(), "https://cdn.example.com/tee.jpg", "VAR-1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`UPDATE inventory`).
+					WithArgs(5, "VAR-1", "M").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectRollback()
+			},
+			expectedCreated: false,
+		},
+		{
+			name:       "Update Without Status Keeps Status",
+			keepStatus: true,
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT id, name, slug, price FROM products WHERE sku = \$1`).
+					WithArgs("TEE-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "price"}).
+						AddRow("PROD-1", "Basic Tee", "basic-tee", 20.0))
+				mock.ExpectExec(`UPDATE products SET name = \$1, slug = \$2`).
+					WithArgs("Basic Tee", "basic-tee", "", "Cotton tee", 20.0, "CAT-1", "PROD-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT id FROM product_variants`).
+					WithArgs("PROD-1", "TEE-1-BLK").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("VAR-1"))
+				mock.ExpectExec(`UPDATE product_variants SET color = \$1 WHERE id = \$2`).
+					WithArgs("black", "VAR-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO product_images`).
+					WithArgs(sqlmock.AnyArg(), "https://cdn.example.com/tee.jpg", "VAR-1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`UPDATE inventory`).
+					WithArgs(5, "VAR-1", "M").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedCreated: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			p := product()
+			if tt.keepStatus {
+				p.Status = ""
+			}
+
+			created, err := repo.UpsertCatalogProduct(context.Background(), p, tt.dryRun)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCreated, created)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package inventoryrepo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+	category "github.com/wafi04/backend/services/category/repository"
+)
+
+func expectLockCategoryForDelete(mock sqlmock.Sqlmock, parentID interface{}, productCount int64) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT parent_id FROM categories WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs("CAT-2").
+		WillReturnRows(sqlmock.NewRows([]string{"parent_id"}).AddRow(parentID))
+	mock.ExpectQuery(`SELECT id FROM categories WHERE parent_id = \$1 AND deleted_at IS NULL ORDER BY position`).
+		WithArgs("CAT-2").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM products WHERE category_id = \$1 AND deleted_at IS NULL`).
+		WithArgs("CAT-2").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(productCount))
+}
+
+func TestDeleteCategory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := category.NewCategoryRepository(sqlx.NewDb(db, "sqlmock"))
+
+	tests := []struct {
+		name          string
+		req           *request.DeleteCategoryRequest
+		mockBehavior  func()
+		expected      *response.DeleteCategoryResponse
+		expectedError string
+	}{
+		{
+			name: "Reject With Products",
+			req:  &request.DeleteCategoryRequest{ID: "CAT-2", Strategy: types.CategoryDeleteReject},
+			mockBehavior: func() {
+				expectLockCategoryForDelete(mock, "CAT-1", 3)
+				mock.ExpectRollback()
+			},
+			expectedError: "invalid delete: category has 0 children and 3 products",
+		},
+		{
+			name: "Cascade Dry Run Rolls Back",
+			req:  &request.DeleteCategoryRequest{ID: "CAT-2", Strategy: types.CategoryDeleteCascade, DryRun: true},
+			mockBehavior: func() {
+				expectLockCategoryForDelete(mock, "CAT-1", 3)
+				mock.ExpectExec(`UPDATE product_images SET deleted_at = \$2`).
+					WithArgs("CAT-2", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 4))
+				mock.ExpectExec(`UPDATE product_variants SET deleted_at = \$2`).
+					WithArgs("CAT-2", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 5))
+				mock.ExpectExec(`UPDATE products SET deleted_at = \$2`).
+					WithArgs("CAT-2", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec(`WITH RECURSIVE category_tree .* UPDATE categories SET deleted_at = \$2`).
+					WithArgs("CAT-2", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectRollback()
+			},
+			expected: &response.DeleteCategoryResponse{
+				Strategy:        types.CategoryDeleteCascade,
+				DryRun:          true,
+				DeletedCount:    2,
+				DeletedProducts: 3,
+			},
+		},
+		{
+			name: "Reassign Products To Target",
+			req:  &request.DeleteCategoryRequest{ID: "CAT-2", Strategy: types.CategoryDeleteReassignTarget, TargetID: "CAT-9"},
+			mockBehavior: func() {
+				expectLockCategoryForDelete(mock, "CAT-1", 3)
+				mock.ExpectQuery(`WITH RECURSIVE category_tree .* EXISTS\(SELECT 1 FROM category_tree WHERE id = \$2\)`).
+					WithArgs("CAT-2", "CAT-9").
+					WillReturnRows(sqlmock.NewRows([]string{"exists", "in_subtree"}).AddRow(true, false))
+				mock.ExpectExec(`UPDATE products SET category_id = \$1 WHERE category_id = \$2 AND deleted_at IS NULL`).
+					WithArgs("CAT-9", "CAT-2").
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec(`WITH RECURSIVE category_tree .* UPDATE categories SET deleted_at = \$2`).
+					WithArgs("CAT-2", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expected: &response.DeleteCategoryResponse{
+				Success:            true,
+				Strategy:           types.CategoryDeleteReassignTarget,
+				DeletedCount:       1,
+				ReassignedProducts: 3,
+			},
+		},
+		{
+			name: "Reassign Into Own Subtree",
+			req:  &request.DeleteCategoryRequest{ID: "CAT-2", Strategy: types.CategoryDeleteReassignTarget, TargetID: "CAT-3"},
+			mockBehavior: func() {
+				expectLockCategoryForDelete(mock, "CAT-1", 3)
+				mock.ExpectQuery(`WITH RECURSIVE category_tree .* EXISTS\(SELECT 1 FROM category_tree WHERE id = \$2\)`).
+					WithArgs("CAT-2", "CAT-3").
+					WillReturnRows(sqlmock.NewRows([]string{"exists", "in_subtree"}).AddRow(true, true))
+				mock.ExpectRollback()
+			},
+			expectedError: "invalid delete: target category is inside the deleted subtree",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			result, err := repo.DeleteCategory(context.Background(), tt.req)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPurgeDeletedCategories(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := category.NewCategoryRepository(sqlx.NewDb(db, "sqlmock"))
+	before := time.Now()
+
+	mock.ExpectQuery(`SELECT id FROM categories WHERE deleted_at IS NOT NULL AND deleted_at < \$1 ORDER BY depth DESC`).
+		WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("CAT-3").AddRow("CAT-1"))
+
+	// A child category hands its products to its parent and is deleted.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT parent_id FROM categories WHERE id = \$1 AND deleted_at IS NOT NULL AND deleted_at < \$2 FOR UPDATE`).
+		WithArgs("CAT-3", before).
+		WillReturnRows(sqlmock.NewRows([]string{"parent_id"}).AddRow("CAT-2"))
+	mock.ExpectExec(`UPDATE products SET category_id = \$1 WHERE category_id = \$2`).
+		WithArgs("CAT-2", "CAT-3").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE categories SET parent_id = NULL WHERE parent_id = \$1`).
+		WithArgs("CAT-3").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM categories WHERE id = \$1`).
+		WithArgs("CAT-3").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// A root category that still holds products stays in the trash.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT parent_id FROM categories WHERE id = \$1 AND deleted_at IS NOT NULL AND deleted_at < \$2 FOR UPDATE`).
+		WithArgs("CAT-1", before).
+		WillReturnRows(sqlmock.NewRows([]string{"parent_id"}).AddRow(nil))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM products WHERE category_id = \$1`).
+		WithArgs("CAT-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectRollback()
+
+	purged, err := repo.PurgeDeletedCategories(context.Background(), before)
+
+	assert.EqualError(t, err, "failed to purge category CAT-1: root category still has 2 products")
+	assert.Equal(t, int64(1), purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,129 @@
+package inventoryrepo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	request "github.com/wafi04/backend/pkg/types/req"
+	category "github.com/wafi04/backend/services/category/repository"
+)
+
+func TestMoveCategory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := category.NewCategoryRepository(sqlx.NewDb(db, "sqlmock"))
+	parent := "CAT-2"
+
+	tests := []struct {
+		name          string
+		req           *request.MoveCategoryRequest
+		mockBehavior  func()
+		expectedError bool
+	}{
+		{
+			name: "Rejects Move Under Own Descendant",
+			req:  &request.MoveCategoryRequest{ID: "CAT-1", ParentID: &parent},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT depth, parent_id FROM categories WHERE id = \$1`).
+					WithArgs("CAT-1").
+					WillReturnRows(sqlmock.NewRows([]string{"depth", "parent_id"}).AddRow(0, nil))
+				mock.ExpectQuery(`SELECT depth FROM categories WHERE id = \$1`).
+					WithArgs("CAT-2").
+					WillReturnRows(sqlmock.NewRows([]string{"depth"}).AddRow(1))
+				mock.ExpectQuery(`WITH RECURSIVE ancestors`).
+					WithArgs("CAT-2", "CAT-1").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			expectedError: true,
+		},
+		{
+			name: "Moves Subtree To Root",
+			req:  &request.MoveCategoryRequest{ID: "CAT-3"},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT depth, parent_id FROM categories WHERE id = \$1`).
+					WithArgs("CAT-3").
+					WillReturnRows(sqlmock.NewRows([]string{"depth", "parent_id"}).AddRow(2, "CAT-2"))
+				mock.ExpectQuery(`SELECT COALESCE\(MAX\(position\) \+ 1, 0\)`).
+					WithArgs(nil, "CAT-3").
+					WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(5))
+				mock.ExpectExec(`UPDATE categories SET parent_id = \$1, position = \$3 WHERE id = \$2`).
+					WithArgs(nil, "CAT-3", 5).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`WITH RECURSIVE subtree`).
+					WithArgs("CAT-3", -2).
+					WillReturnResult(sqlmock.NewResult(0, 4))
+				mock.ExpectQuery(`SELECT id, name, slug, description, image, depth, position, parent_id, created_at`).
+					WithArgs("CAT-3").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "description", "image", "depth", "position", "parent_id", "created_at"}).
+						AddRow("CAT-3", "Shoes", "shoes", "", nil, 0, 5, nil, nil))
+				mock.ExpectCommit()
+			},
+			expectedError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			result, err := repo.MoveCategory(context.Background(), tt.req)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(4), result.UpdatedCount)
+				assert.Equal(t, int32(0), result.Category.Depth)
+				assert.Equal(t, int32(5), result.Category.Position)
+				assert.Nil(t, result.Category.ParentID)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpdateCategoryKeepsPosition(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := category.NewCategoryRepository(sqlx.NewDb(db, "sqlmock"))
+	parent := "CAT-2"
+	description := "Running shoes"
+
+	// The edit form resends the current parent; no sibling position is
+	// looked up and the category stays where it was.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT depth, parent_id FROM categories WHERE id = \$1`).
+		WithArgs("CAT-3").
+		WillReturnRows(sqlmock.NewRows([]string{"depth", "parent_id"}).AddRow(1, "CAT-2"))
+	mock.ExpectQuery(`UPDATE categories SET description = \$1 WHERE id = \$2 AND deleted_at IS NULL`).
+		WithArgs(description, "CAT-3").
+		WillReturnRows(sqlmock.NewRows(categoryColumns).
+			AddRow("CAT-3", "Shoes", "shoes", description, nil, 1, 2, "CAT-2", nil))
+	mock.ExpectCommit()
+
+	result, err := repo.UpdateCategory(context.Background(), &request.UpdateCategoryRequest{
+		ID:          "CAT-3",
+		Description: &description,
+		ParentID:    &parent,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), result.Position)
+	assert.Equal(t, &parent, result.ParentID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
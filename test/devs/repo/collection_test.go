@@ -0,0 +1,183 @@
+package inventoryrepo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	collection "github.com/wafi04/backend/services/collection/repository"
+	productRepository "github.com/wafi04/backend/services/product/repository"
+)
+
+var collectionRowColumns = []string{"id", "name", "slug", "description", "image", "collection_type",
+	"rule_tags", "rule_category_ids", "rule_min_price", "rule_max_price", "created_at", "updated_at"}
+
+func TestSetCollectionProducts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := collection.NewCollectionRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	expectCollection := func(collectionType string) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`FROM collections WHERE id = \$1 FOR UPDATE`).
+			WithArgs("COL-1").
+			WillReturnRows(sqlmock.NewRows(collectionRowColumns).
+				AddRow("COL-1", "Summer", "summer", "", nil, collectionType, "{summer}", "{}", nil, 50.0, now, now))
+	}
+
+	tests := []struct {
+		name          string
+		productIDs    []string
+		mockBehavior  func()
+		expectedError string
+	}{
+		{
+			name:       "Rejects Rule Collection",
+			productIDs: []string{"PROD-1"},
+			mockBehavior: func() {
+				expectCollection(types.CollectionTypeRule)
+				mock.ExpectRollback()
+			},
+			expectedError: "products can only be set on manual collections",
+		},
+		{
+			name:       "Rejects Unknown Or Trashed Product",
+			productIDs: []string{"PROD-1", "PROD-2"},
+			mockBehavior: func() {
+				expectCollection(types.CollectionTypeManual)
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM products WHERE id = ANY\(\$1\) AND deleted_at IS NULL`).
+					WithArgs(`{"PROD-1","PROD-2"}`).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			},
+			expectedError: "product not found",
+		},
+		{
+			name:       "Replaces Members In Order",
+			productIDs: []string{"PROD-2", "PROD-1"},
+			mockBehavior: func() {
+				expectCollection(types.CollectionTypeManual)
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM products`).
+					WithArgs(`{"PROD-2","PROD-1"}`).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectExec(`DELETE FROM collection_products WHERE collection_id = \$1`).
+					WithArgs("COL-1").
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec(`INSERT INTO collection_products .* WITH ORDINALITY`).
+					WithArgs("COL-1", `{"PROD-2","PROD-1"}`).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectQuery(`SELECT cp.product_id FROM collection_products cp`).
+					WithArgs("COL-1").
+					WillReturnRows(sqlmock.NewRows([]string{"product_id"}).AddRow("PROD-2").AddRow("PROD-1"))
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			result, err := repo.SetProducts(context.Background(), &request.SetCollectionProductsRequest{
+				CollectionID: "COL-1",
+				ProductIDs:   tt.productIDs,
+			})
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.productIDs, result.ProductIDs)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListProductsByRuleCollection(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := &productRepository.Database{DB: sqlx.NewDb(db, "sqlmock")}
+
+	// Rule membership is resolved in SQL: manual members come from
+	// collection_products, rule members from the rule_* columns.
+	mock.ExpectQuery(`c.collection_type = 'manual' AND EXISTS.*c.collection_type = 'rule'.*t.slug = ANY\(c.rule_tags\)`).
+		WithArgs(int32(10), "0", types.ProductStatusPublished, "", "COL-1", "{}", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	res, err := repo.ListProducts(context.Background(), &request.ListProductsRequest{
+		PageSize:     10,
+		Status:       types.ProductStatusPublished,
+		CollectionID: "COL-1",
+		Tags:         []string{},
+	})
+
+	assert.NoError(t, err)
+	assert.Empty(t, res.Products)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateProductBrand(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := &productRepository.Database{DB: sqlx.NewDb(db, "sqlmock")}
+	empty, brand := "", "BRAND-2"
+
+	tests := []struct {
+		name          string
+		brandID       *string
+		expectedArg   string
+		expectedSet   bool
+		returnedBrand interface{}
+	}{
+		{name: "Omitted Brand Is Kept", brandID: nil, expectedArg: "", expectedSet: false, returnedBrand: "BRAND-1"},
+		{name: "Empty Brand Clears It", brandID: &empty, expectedArg: "", expectedSet: true, returnedBrand: nil},
+		{name: "Brand Is Replaced", brandID: &brand, expectedArg: "BRAND-2", expectedSet: true, returnedBrand: "BRAND-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT name, slug, price FROM products WHERE id = \$1`).
+				WithArgs("PROD-1").
+				WillReturnRows(sqlmock.NewRows([]string{"name", "slug", "price"}).AddRow("Tee", "tee", 20.0))
+			mock.ExpectQuery(`brand_id = CASE WHEN \$10 THEN NULLIF\(\$9, ''\) ELSE brand_id END`).
+				WithArgs("Tee", "", "", 20.0, "", "CAT-1", "tee", "PROD-1", tt.expectedArg, tt.expectedSet).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "sub_title", "description", "price", "sku", "category_id", "status", "brand_id", "created_at", "updated_at"}).
+					AddRow("PROD-1", "Tee", "tee", "", "", 20.0, "", "CAT-1", "published", tt.returnedBrand, now, now))
+			mock.ExpectCommit()
+
+			product, err := repo.UpdateProduct(context.Background(), &request.UpdateProductRequest{
+				Product: &types.Product{ID: "PROD-1", Name: "Tee", Price: 20, CategoryID: "CAT-1", BrandID: tt.brandID},
+			})
+
+			assert.NoError(t, err)
+			if tt.returnedBrand == nil {
+				assert.Nil(t, product.BrandID)
+			} else {
+				assert.Equal(t, tt.returnedBrand, *product.BrandID)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
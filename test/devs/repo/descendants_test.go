@@ -0,0 +1,136 @@
+package inventoryrepo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	request "github.com/wafi04/backend/pkg/types/req"
+	category "github.com/wafi04/backend/services/category/repository"
+)
+
+func TestGetBreadcrumb(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := category.NewCategoryRepository(sqlx.NewDb(db, "sqlmock"))
+	columns := []string{"id", "name", "slug", "description", "image", "parent_id", "depth", "position", "created_at"}
+
+	tests := []struct {
+		name          string
+		rows          *sqlmock.Rows
+		expectedIDs   []string
+		expectedError bool
+	}{
+		{
+			name: "Root First",
+			rows: sqlmock.NewRows(columns).
+				AddRow("CAT-1", "Men", "men", "", nil, nil, 0, 0, nil).
+				AddRow("CAT-2", "Shoes", "shoes", "", nil, "CAT-1", 1, 0, nil).
+				AddRow("CAT-3", "Running", "running", "", nil, "CAT-2", 2, 0, nil),
+			expectedIDs: []string{"CAT-1", "CAT-2", "CAT-3"},
+		},
+		{
+			name:          "Unknown Or Trashed Category",
+			rows:          sqlmock.NewRows(columns),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectQuery(`WITH RECURSIVE ancestors .* ORDER BY level DESC`).
+				WithArgs("CAT-3").
+				WillReturnRows(tt.rows)
+
+			result, err := repo.GetBreadcrumb(context.Background(), "CAT-3")
+
+			if tt.expectedError {
+				assert.EqualError(t, err, "category not found")
+			} else {
+				assert.NoError(t, err)
+				ids := make([]string, 0, len(result.Items))
+				for _, item := range result.Items {
+					ids = append(ids, item.ID)
+				}
+				assert.Equal(t, tt.expectedIDs, ids)
+				assert.Nil(t, result.Items[0].ParentID)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListCategoryProducts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := category.NewCategoryRepository(sqlx.NewDb(db, "sqlmock"))
+	columns := []string{"id", "name", "slug", "sub_title", "description", "price", "sku", "category_id", "status",
+		"rating_average", "rating_count", "created_at", "updated_at"}
+	now := time.Now()
+
+	tests := []struct {
+		name              string
+		exists            bool
+		rows              *sqlmock.Rows
+		expectedCount     int
+		expectedNextToken string
+		expectedError     bool
+	}{
+		{
+			name:   "Includes Products Of Descendants",
+			exists: true,
+			rows: sqlmock.NewRows(columns).
+				AddRow("PROD-1", "Tee", "tee", nil, "", 20.0, "T-1", "CAT-1", "published", 0.0, 0, now, now).
+				AddRow("PROD-2", "Trail Shoe", "trail-shoe", "Grip", "", 90.0, "S-1", "CAT-3", "published", 4.5, 2, now, now),
+			expectedCount:     2,
+			expectedNextToken: "1",
+		},
+		{
+			name:          "Unknown Category",
+			exists:        false,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM categories WHERE id = \$1 AND deleted_at IS NULL\)`).
+				WithArgs("CAT-1").
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tt.exists))
+			if tt.exists {
+				mock.ExpectQuery(`WITH RECURSIVE category_tree .* WHERE p.category_id IN \(SELECT id FROM category_tree\)`).
+					WithArgs("CAT-1", int32(2), "0").
+					WillReturnRows(tt.rows)
+			}
+
+			result, err := repo.ListCategoryProducts(context.Background(), &request.ListCategoryProductsRequest{
+				ID:        "CAT-1",
+				PageSize:  2,
+				PageToken: "0",
+			})
+
+			if tt.expectedError {
+				assert.EqualError(t, err, "category not found")
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, result.Products, tt.expectedCount)
+				assert.Equal(t, tt.expectedNextToken, result.NextPageToken)
+				assert.Equal(t, "CAT-3", result.Products[1].CategoryID)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
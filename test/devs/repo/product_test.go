@@ -0,0 +1,165 @@
+package inventoryrepo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	request "github.com/wafi04/backend/pkg/types/req"
+	productRepository "github.com/wafi04/backend/services/product/repository"
+)
+
+func TestDeleteProduct(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := &productRepository.Database{DB: sqlxDB}
+
+	tests := []struct {
+		name          string
+		req           *request.DeleteProductRequest
+		mockBehavior  func()
+		expectedError bool
+	}{
+		{
+			name: "Soft Deletes Product With Variants And Images",
+			req:  &request.DeleteProductRequest{ID: "PROD-1"},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE products SET deleted_at = \$1 WHERE id = \$2 AND deleted_at IS NULL`).
+					WithArgs(sqlmock.AnyArg(), "PROD-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE product_images SET deleted_at = \$1`).
+					WithArgs(sqlmock.AnyArg(), "PROD-1").
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec(`UPDATE product_variants SET deleted_at = \$1 WHERE product_id = \$2 AND deleted_at IS NULL`).
+					WithArgs(sqlmock.AnyArg(), "PROD-1").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+			expectedError: false,
+		},
+		{
+			name: "Product Not Found",
+			req:  &request.DeleteProductRequest{ID: "PROD-2"},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE products SET deleted_at = \$1 WHERE id = \$2 AND deleted_at IS NULL`).
+					WithArgs(sqlmock.AnyArg(), "PROD-2").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedError: true,
+		},
+		{
+			name: "Database Error",
+			req:  &request.DeleteProductRequest{ID: "PROD-3"},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE products SET deleted_at = \$1 WHERE id = \$2 AND deleted_at IS NULL`).
+					WithArgs(sqlmock.AnyArg(), "PROD-3").
+					WillReturnError(errors.New("database error"))
+				mock.ExpectRollback()
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			result, err := repo.DeleteProduct(context.Background(), tt.req)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, result.Success)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSetMainProductImage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := &productRepository.Database{DB: sqlxDB}
+
+	imageColumns := []string{"id", "url", "variant_id", "is_main", "position", "alt_text"}
+
+	tests := []struct {
+		name          string
+		req           *request.SetMainProductImageRequest
+		mockBehavior  func()
+		expectedError string
+	}{
+		{
+			name: "Unsets Previous Main Image",
+			req:  &request.SetMainProductImageRequest{VariantID: "VAR-1", ImageID: "IMG-2"},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT id FROM product_variants WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs("VAR-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("VAR-1"))
+				mock.ExpectExec(`UPDATE product_images SET is_main = false WHERE variant_id = \$1 AND is_main`).
+					WithArgs("VAR-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`UPDATE product_images\s+SET is_main = true`).
+					WithArgs("IMG-2", "VAR-1").
+					WillReturnRows(sqlmock.NewRows(imageColumns).AddRow("IMG-2", "/static/products/2.jpg", "VAR-1", true, 1, ""))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Image Of Another Variant",
+			req:  &request.SetMainProductImageRequest{VariantID: "VAR-1", ImageID: "IMG-9"},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT id FROM product_variants WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs("VAR-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("VAR-1"))
+				mock.ExpectExec(`UPDATE product_images SET is_main = false WHERE variant_id = \$1 AND is_main`).
+					WithArgs("VAR-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`UPDATE product_images\s+SET is_main = true`).
+					WithArgs("IMG-9", "VAR-1").
+					WillReturnRows(sqlmock.NewRows(imageColumns))
+				mock.ExpectRollback()
+			},
+			expectedError: "image not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			image, err := repo.SetMainProductImage(context.Background(), tt.req)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, image.IsMain)
+				assert.Equal(t, tt.req.ImageID, image.ID)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package inventoryrepo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	productRepository "github.com/wafi04/backend/services/product/repository"
+	productservice "github.com/wafi04/backend/services/product/service"
+)
+
+var productBaseColumns = []string{"id", "name", "slug", "sub_title", "description", "price", "sku", "category_id",
+	"status", "publish_at", "unpublish_at", "rating_average", "rating_count", "product_type", "brand_id",
+	"created_at", "updated_at"}
+
+// expectGetProduct expects the queries GetProduct runs for a standard
+// product with no variants, media or tags.
+func expectGetProduct(mock sqlmock.Sqlmock, id, status string) {
+	now := time.Now()
+	mock.ExpectQuery(`SELECT .* FROM products WHERE id = \$1 AND deleted_at IS NULL`).
+		WithArgs(id, false).
+		WillReturnRows(sqlmock.NewRows(productBaseColumns).AddRow(
+			id, "Tee", "tee", nil, "", 20.0, "TEE-1", "CAT-1",
+			status, nil, nil, 0.0, 0, types.ProductTypeStandard, nil, now, now,
+		))
+	mock.ExpectQuery(`FROM product_variants WHERE product_id = \$1`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "color", "sku", "price"}))
+	mock.ExpectQuery(`FROM product_videos`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`FROM product_documents`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`FROM product_tags pt JOIN tags t`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug"}))
+}
+
+func TestUpdateProductStatusTransitions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := &productRepository.Database{DB: sqlx.NewDb(db, "sqlmock")}
+	service := productservice.NewProductService(repo)
+	publishAt := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name          string
+		req           *request.UpdateProductStatusRequest
+		mockBehavior  func()
+		expectedError string
+	}{
+		{
+			name: "Draft To Published",
+			req:  &request.UpdateProductStatusRequest{ID: "PROD-1", Status: types.ProductStatusPublished},
+			mockBehavior: func() {
+				expectGetProduct(mock, "PROD-1", types.ProductStatusDraft)
+				now := time.Now()
+				mock.ExpectQuery(`UPDATE products SET status = \$1, publish_at = \$2, unpublish_at = \$3 WHERE id = \$4 AND deleted_at IS NULL`).
+					WithArgs(types.ProductStatusPublished, nil, nil, "PROD-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "sub_title", "description", "price", "sku",
+						"category_id", "status", "publish_at", "unpublish_at", "created_at", "updated_at"}).
+						AddRow("PROD-1", "Tee", "tee", nil, "", 20.0, "TEE-1", "CAT-1", types.ProductStatusPublished, nil, nil, now, now))
+			},
+		},
+		{
+			name: "Published Cannot Be Scheduled",
+			req:  &request.UpdateProductStatusRequest{ID: "PROD-1", Status: types.ProductStatusScheduled, PublishAt: &publishAt},
+			mockBehavior: func() {
+				expectGetProduct(mock, "PROD-1", types.ProductStatusPublished)
+			},
+			expectedError: "invalid status transition from published to scheduled",
+		},
+		{
+			name: "Archived Must Go Back To Draft",
+			req:  &request.UpdateProductStatusRequest{ID: "PROD-1", Status: types.ProductStatusPublished},
+			mockBehavior: func() {
+				expectGetProduct(mock, "PROD-1", types.ProductStatusArchived)
+			},
+			expectedError: "invalid status transition from archived to published",
+		},
+		{
+			name:          "Scheduled Needs Publish At",
+			req:           &request.UpdateProductStatusRequest{ID: "PROD-1", Status: types.ProductStatusScheduled},
+			mockBehavior:  func() {},
+			expectedError: "publish_at is required for scheduled products",
+		},
+		{
+			name:          "Unknown Status",
+			req:           &request.UpdateProductStatusRequest{ID: "PROD-1", Status: "live"},
+			mockBehavior:  func() {},
+			expectedError: "invalid product status: live",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			result, err := service.UpdateProductStatus(context.Background(), tt.req)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.req.Status, result.Status)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestApplyProductSchedule(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := &productRepository.Database{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE products SET status = 'published' WHERE status = 'scheduled' AND deleted_at IS NULL AND publish_at IS NOT NULL AND publish_at <= \$1`).
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`UPDATE products SET status = 'archived' WHERE status = 'published' AND deleted_at IS NULL AND unpublish_at IS NOT NULL AND unpublish_at <= \$1`).
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	result, err := repo.ApplyProductSchedule(context.Background(), now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result.Published)
+	assert.Equal(t, int64(1), result.Unpublished)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPublishedOnlyFilters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := &productRepository.Database{DB: sqlx.NewDb(db, "sqlmock")}
+
+	t.Run("Public Product Page", func(t *testing.T) {
+		mock.ExpectQuery(`FROM products WHERE id = \$1 AND deleted_at IS NULL AND \(\$2 = false OR \(status = 'published' AND \(unpublish_at IS NULL OR unpublish_at > NOW\(\)\)\)\)`).
+			WithArgs("PROD-1", true).
+			WillReturnRows(sqlmock.NewRows(productBaseColumns))
+
+		_, err := repo.GetProduct(context.Background(), &request.GetProductRequest{ID: "PROD-1", PublishedOnly: true})
+
+		assert.EqualError(t, err, "product not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Public Listing", func(t *testing.T) {
+		mock.ExpectQuery(`AND \(\$3 = '' OR p.status = \$3\) AND \(\$3 <> 'published' OR p.unpublish_at IS NULL OR p.unpublish_at > NOW\(\)\)`).
+			WithArgs(int32(10), "0", types.ProductStatusPublished, "", "", pq.Array([]string{}), "").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		result, err := repo.ListProducts(context.Background(), &request.ListProductsRequest{
+			PageSize: 10,
+			Status:   types.ProductStatusPublished,
+		})
+
+		assert.NoError(t, err)
+		assert.Empty(t, result.Products)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
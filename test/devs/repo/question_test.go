@@ -0,0 +1,156 @@
+package inventoryrepo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	productRepository "github.com/wafi04/backend/services/product/repository"
+)
+
+func TestCreateAnswer(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := &productRepository.Database{DB: sqlx.NewDb(db, "sqlmock")}
+
+	tests := []struct {
+		name           string
+		req            *request.CreateAnswerRequest
+		mockBehavior   func()
+		expectedError  string
+		expectedStatus string
+	}{
+		{
+			name: "Rejects Shopper Without Verified Purchase",
+			req:  &request.CreateAnswerRequest{QuestionID: "Q-1", UserID: "USER-2", Body: "Fits well"},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM product_questions q WHERE q.id = \$1 AND q.status = 'approved'`).
+					WithArgs("Q-1", "USER-2").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectRollback()
+			},
+			expectedError: "only admins and verified buyers can answer",
+		},
+		{
+			name: "Rejects Unapproved Question",
+			req:  &request.CreateAnswerRequest{QuestionID: "Q-2", UserID: "ADMIN-1", IsAdmin: true, Body: "Yes"},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM product_questions q WHERE q.id = \$1 AND q.status = 'approved'`).
+					WithArgs("Q-2", "ADMIN-1").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}))
+				mock.ExpectRollback()
+			},
+			expectedError: "question not found",
+		},
+		{
+			name: "Admin Answer Is Approved And Notifies Asker",
+			req:  &request.CreateAnswerRequest{QuestionID: "Q-1", UserID: "ADMIN-1", IsAdmin: true, Body: "Runs small"},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM product_questions q WHERE q.id = \$1 AND q.status = 'approved'`).
+					WithArgs("Q-1", "ADMIN-1").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery(`INSERT INTO product_answers`).
+					WithArgs(sqlmock.AnyArg(), "Q-1", "ADMIN-1", "Runs small", true, false, types.QAStatusApproved).
+					WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+				mock.ExpectExec(`INSERT INTO notifications`).
+					WithArgs(sqlmock.AnyArg(), types.NotificationQuestionAnswered, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE product_answers SET asker_notified = true WHERE id = \$1`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedStatus: types.QAStatusApproved,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			answer, err := repo.CreateAnswer(context.Background(), tt.req)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, answer.Status)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestModerateAnswer(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := &productRepository.Database{DB: sqlx.NewDb(db, "sqlmock")}
+
+	tests := []struct {
+		name          string
+		previous      string
+		status        string
+		notify        bool
+		expectedError bool
+	}{
+		{name: "First Approval Notifies Asker", previous: types.QAStatusPending, status: types.QAStatusApproved, notify: true},
+		{name: "Re-approval Does Not Notify Again", previous: types.QAStatusApproved, status: types.QAStatusApproved},
+		{name: "Rejection Does Not Notify", previous: types.QAStatusPending, status: types.QAStatusRejected},
+		{name: "Unknown Answer", status: types.QAStatusApproved, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			rows := sqlmock.NewRows([]string{"status"})
+			if tt.previous != "" {
+				rows.AddRow(tt.previous)
+			}
+			mock.ExpectQuery(`SELECT status FROM product_answers WHERE id = \$1 FOR UPDATE`).
+				WithArgs("A-1").
+				WillReturnRows(rows)
+			if !tt.expectedError {
+				mock.ExpectExec(`UPDATE product_answers SET status = \$1`).
+					WithArgs(tt.status, "A-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				if tt.notify {
+					mock.ExpectExec(`INSERT INTO notifications`).
+						WithArgs(sqlmock.AnyArg(), types.NotificationQuestionAnswered, "A-1").
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(`UPDATE product_answers SET asker_notified = true WHERE id = \$1`).
+						WithArgs("A-1").
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			err := repo.ModerateAnswer(context.Background(), &request.ModerateQARequest{ID: "A-1", Status: tt.status})
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
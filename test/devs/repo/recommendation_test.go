@@ -0,0 +1,111 @@
+package inventoryrepo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	request "github.com/wafi04/backend/pkg/types/req"
+	productRepository "github.com/wafi04/backend/services/product/repository"
+	productservice "github.com/wafi04/backend/services/product/service"
+)
+
+var relatedProductColumns = []string{"id", "name", "slug", "price", "image", "rating_average", "score", "pinned"}
+
+// publishedRelated is the filter every recommendation query applies to the
+// products it returns.
+const publishedRelated = `WHERE p.deleted_at IS NULL AND p.status = 'published' AND \(p.unpublish_at IS NULL OR p.unpublish_at > NOW\(\)\) ORDER BY c.pinned DESC, c.position, c.score DESC LIMIT \$2`
+
+func TestRecomputeSimilarities(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := &productRepository.Database{DB: sqlx.NewDb(db, "sqlmock")}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM product_co_occurrences`).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`INSERT INTO product_co_occurrences \(product_id, related_id, carts\) SELECT va.product_id, vb.product_id, COUNT\(DISTINCT a.cart_id\)`).
+		WillReturnResult(sqlmock.NewResult(0, 6))
+	mock.ExpectExec(`DELETE FROM product_similarities`).
+		WillReturnResult(sqlmock.NewResult(0, 10))
+	mock.ExpectExec(`WITH live AS \( SELECT id, category_id, price::float8 AS price FROM products WHERE deleted_at IS NULL AND status = 'published' AND \(unpublish_at IS NULL OR unpublish_at > NOW\(\)\) \)` +
+		`.*INSERT INTO product_similarities \(product_id, related_id, score, computed_at\)` +
+		`.*ROW_NUMBER\(\) OVER \(PARTITION BY product_id ORDER BY score DESC\) AS rank .* WHERE rank <= \$1`).
+		WithArgs(20).
+		WillReturnResult(sqlmock.NewResult(0, 12))
+	mock.ExpectCommit()
+
+	result, err := repo.RecomputeSimilarities(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), result.Similarities)
+	assert.Equal(t, int64(6), result.CoOccurrences)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRelatedProducts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	service := productservice.NewProductService(&productRepository.Database{DB: sqlx.NewDb(db, "sqlmock")})
+
+	// Pinned overrides come first; similarities with any override, which
+	// covers excluded products, are left out.
+	mock.ExpectQuery(`WITH candidates AS \( SELECT related_id, 0::float8 AS score, true AS pinned, position FROM product_recommendation_overrides WHERE product_id = \$1 AND type = 'pinned'`+
+		` UNION ALL SELECT s.related_id, s.score, false, 0 FROM product_similarities s WHERE s.product_id = \$1`+
+		` AND NOT EXISTS \( SELECT 1 FROM product_recommendation_overrides o WHERE o.product_id = s.product_id AND o.related_id = s.related_id \) \)`+
+		`.*`+publishedRelated).
+		WithArgs("PROD-1", 8).
+		WillReturnRows(sqlmock.NewRows(relatedProductColumns).
+			AddRow("PROD-4", "Socks", "socks", 5.0, "", 0.0, 0.0, true).
+			AddRow("PROD-2", "Tee", "tee", 20.0, "https://cdn.example.com/tee.jpg", 4.5, 2.7, false))
+
+	result, err := service.GetRelatedProducts(context.Background(), &request.GetRelatedProductsRequest{ProductID: "PROD-1"})
+
+	assert.NoError(t, err)
+	if assert.Len(t, result.Products, 2) {
+		assert.Equal(t, "PROD-4", result.Products[0].ProductID)
+		assert.True(t, result.Products[0].Pinned)
+		assert.Equal(t, "PROD-2", result.Products[1].ProductID)
+		assert.False(t, result.Products[1].Pinned)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetCartRecommendations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	service := productservice.NewProductService(&productRepository.Database{DB: sqlx.NewDb(db, "sqlmock")})
+
+	mock.ExpectQuery(`WITH cart_products AS \( SELECT DISTINCT pv.product_id FROM carts c JOIN cart_items ci ON ci.cart_id = c.cart_id JOIN product_variants pv ON pv.id = ci.product_variant_id WHERE c.user_id = \$1 \)`+
+		`.*candidates AS \( SELECT \* FROM all_candidates a WHERE a.related_id NOT IN \(SELECT product_id FROM cart_products\)`+
+		` AND NOT EXISTS \( SELECT 1 FROM product_recommendation_overrides o WHERE o.product_id IN \(SELECT product_id FROM cart_products\) AND o.related_id = a.related_id AND o.type = 'excluded' \) \)`+
+		`.*`+publishedRelated).
+		WithArgs("USR-1", 50).
+		WillReturnRows(sqlmock.NewRows(relatedProductColumns).
+			AddRow("PROD-3", "Cap", "cap", 10.0, "", 4.0, 3.4, false))
+
+	result, err := service.GetCartRecommendations(context.Background(), &request.GetCartRecommendationsRequest{
+		UserID: "USR-1",
+		Limit:  500,
+	})
+
+	assert.NoError(t, err)
+	if assert.Len(t, result.Products, 1) {
+		assert.Equal(t, "PROD-3", result.Products[0].ProductID)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,276 @@
+package inventoryrepo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	productRepository "github.com/wafi04/backend/services/product/repository"
+	productservice "github.com/wafi04/backend/services/product/service"
+)
+
+var reviewRowColumns = []string{"id", "product_id", "user_id", "user_name", "rating", "title", "body", "photos",
+	"verified_buyer", "status", "moderation_note", "helpful_count", "created_at", "updated_at"}
+
+func TestCheckReviewable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := &productRepository.Database{DB: sqlx.NewDb(db, "sqlmock")}
+
+	tests := []struct {
+		name          string
+		exists        bool
+		reviewed      bool
+		expectedError string
+	}{
+		{name: "First Review", exists: true},
+		{name: "Product Missing", expectedError: "product not found"},
+		{name: "Already Reviewed", exists: true, reviewed: true, expectedError: "product already reviewed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectQuery(`EXISTS \(SELECT 1 FROM product_reviews WHERE product_id = \$1 AND user_id = \$2\)`).
+				WithArgs("PROD-1", "USR-1").
+				WillReturnRows(sqlmock.NewRows([]string{"exists", "reviewed"}).AddRow(tt.exists, tt.reviewed))
+
+			err := repo.CheckReviewable(context.Background(), "PROD-1", "USR-1")
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestModerateReview(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	service := productservice.NewProductService(&productRepository.Database{DB: sqlx.NewDb(db, "sqlmock")})
+	verified := true
+	now := time.Now()
+
+	expectRefresh := func() {
+		mock.ExpectExec(`UPDATE products p SET rating_average = agg.average, rating_count = agg.count FROM \( SELECT COALESCE\(ROUND\(AVG\(rating\)::numeric, 2\), 0\) AS average, COUNT\(\*\) AS count FROM product_reviews WHERE product_id = \$1 AND status = 'approved' \) agg WHERE p.id = \$1`).
+			WithArgs("PROD-1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	expectGetReview := func(status string, verifiedBuyer bool, note string) {
+		mock.ExpectQuery(`FROM product_reviews r LEFT JOIN users u ON u.user_id = r.user_id WHERE r.id = \$1`).
+			WithArgs("REV-1").
+			WillReturnRows(sqlmock.NewRows(reviewRowColumns).AddRow(
+				"REV-1", "PROD-1", "USR-1", "Ana", 5, "", "Great", pq.StringArray{},
+				verifiedBuyer, status, note, 0, now, now,
+			))
+	}
+
+	tests := []struct {
+		name          string
+		req           *request.ModerateReviewRequest
+		mockBehavior  func()
+		expectedError string
+	}{
+		{
+			name:          "Approve Needs Verified Buyer Decision",
+			req:           &request.ModerateReviewRequest{ID: "REV-1", Status: types.ReviewStatusApproved},
+			mockBehavior:  func() {},
+			expectedError: "invalid moderation: verified_buyer is required when approving a review",
+		},
+		{
+			name: "Approve Refreshes Rating",
+			req:  &request.ModerateReviewRequest{ID: "REV-1", Status: types.ReviewStatusApproved, VerifiedBuyer: &verified},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`UPDATE product_reviews SET status = \$1, moderation_note = NULLIF\(\$2, ''\), verified_buyer = COALESCE\(\$3, verified_buyer\)`).
+					WithArgs(types.ReviewStatusApproved, "", true, "REV-1").
+					WillReturnRows(sqlmock.NewRows([]string{"product_id"}).AddRow("PROD-1"))
+				expectRefresh()
+				mock.ExpectCommit()
+				expectGetReview(types.ReviewStatusApproved, true, "")
+			},
+		},
+		{
+			name: "Reject Refreshes Rating",
+			req:  &request.ModerateReviewRequest{ID: "REV-1", Status: types.ReviewStatusRejected, Note: "off topic"},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`UPDATE product_reviews SET status = \$1`).
+					WithArgs(types.ReviewStatusRejected, "off topic", nil, "REV-1").
+					WillReturnRows(sqlmock.NewRows([]string{"product_id"}).AddRow("PROD-1"))
+				expectRefresh()
+				mock.ExpectCommit()
+				expectGetReview(types.ReviewStatusRejected, false, "off topic")
+			},
+		},
+		{
+			name: "Unknown Review",
+			req:  &request.ModerateReviewRequest{ID: "REV-1", Status: types.ReviewStatusRejected},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`UPDATE product_reviews SET status = \$1`).
+					WithArgs(types.ReviewStatusRejected, "", nil, "REV-1").
+					WillReturnRows(sqlmock.NewRows([]string{"product_id"}))
+				mock.ExpectRollback()
+			},
+			expectedError: "review not found",
+		},
+		{
+			name:          "Unknown Status",
+			req:           &request.ModerateReviewRequest{ID: "REV-1", Status: "hidden"},
+			mockBehavior:  func() {},
+			expectedError: "invalid review status: hidden",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			result, err := service.ModerateReview(context.Background(), tt.req)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.req.Status, result.Status)
+				assert.Equal(t, tt.req.VerifiedBuyer != nil, result.VerifiedBuyer)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMarkReviewHelpful(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := &productRepository.Database{DB: sqlx.NewDb(db, "sqlmock")}
+	req := &request.MarkReviewHelpfulRequest{ReviewID: "REV-1", UserID: "USR-2"}
+
+	expectAuthor := func(author string) {
+		mock.ExpectQuery(`SELECT user_id FROM product_reviews WHERE id = \$1 AND status = 'approved'`).
+			WithArgs("REV-1").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(author))
+	}
+
+	tests := []struct {
+		name          string
+		req           *request.MarkReviewHelpfulRequest
+		mockBehavior  func()
+		expectedCount int
+		expectedError string
+	}{
+		{
+			name: "First Vote Counts",
+			req:  req,
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				expectAuthor("USR-1")
+				mock.ExpectExec(`INSERT INTO review_helpful_votes \(review_id, user_id\) VALUES \(\$1, \$2\) ON CONFLICT DO NOTHING`).
+					WithArgs("REV-1", "USR-2").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`UPDATE product_reviews SET helpful_count = helpful_count \+ 1 WHERE id = \$1 RETURNING helpful_count`).
+					WithArgs("REV-1").
+					WillReturnRows(sqlmock.NewRows([]string{"helpful_count"}).AddRow(4))
+				mock.ExpectCommit()
+			},
+			expectedCount: 4,
+		},
+		{
+			name: "Duplicate Vote Leaves Count",
+			req:  req,
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				expectAuthor("USR-1")
+				mock.ExpectExec(`INSERT INTO review_helpful_votes`).
+					WithArgs("REV-1", "USR-2").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`SELECT helpful_count FROM product_reviews WHERE id = \$1`).
+					WithArgs("REV-1").
+					WillReturnRows(sqlmock.NewRows([]string{"helpful_count"}).AddRow(4))
+				mock.ExpectCommit()
+			},
+			expectedCount: 4,
+		},
+		{
+			name: "Own Review",
+			req:  &request.MarkReviewHelpfulRequest{ReviewID: "REV-1", UserID: "USR-1"},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				expectAuthor("USR-1")
+				mock.ExpectRollback()
+			},
+			expectedError: "cannot vote on your own review",
+		},
+		{
+			name: "Unapproved Review",
+			req:  req,
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT user_id FROM product_reviews`).
+					WithArgs("REV-1").
+					WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+				mock.ExpectRollback()
+			},
+			expectedError: "review not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			result, err := repo.MarkReviewHelpful(context.Background(), tt.req)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedCount, result.HelpfulCount)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListProductsSortsByRating(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := &productRepository.Database{DB: sqlx.NewDb(db, "sqlmock")}
+
+	mock.ExpectQuery(`ORDER BY p.rating_average DESC, p.rating_count DESC, p.created_at DESC`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err = repo.ListProducts(context.Background(), &request.ListProductsRequest{
+		PageSize: 10,
+		Status:   types.ProductStatusPublished,
+		SortBy:   types.ProductSortRating,
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,152 @@
+package inventoryrepo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	request "github.com/wafi04/backend/pkg/types/req"
+	category "github.com/wafi04/backend/services/category/repository"
+)
+
+var categoryColumns = []string{"id", "name", "slug", "description", "image", "depth", "position", "parent_id", "created_at"}
+
+func TestRenameCategorySlug(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := category.NewCategoryRepository(sqlx.NewDb(db, "sqlmock"))
+
+	tests := []struct {
+		name         string
+		newName      string
+		mockBehavior func()
+		expectedSlug string
+	}{
+		{
+			name:    "Skips Taken Slugs And Keeps Old Slug As Redirect",
+			newName: "Running Shoes",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT name, slug FROM categories WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs("CAT-1").
+					WillReturnRows(sqlmock.NewRows([]string{"name", "slug"}).AddRow("Shoes", "shoes"))
+				mock.ExpectQuery(`SELECT slug FROM categories WHERE id <> \$2`).
+					WithArgs("running-shoes", "CAT-1", "category").
+					WillReturnRows(sqlmock.NewRows([]string{"slug"}).AddRow("running-shoes").AddRow("running-shoes-2"))
+				mock.ExpectExec(`INSERT INTO slug_redirects`).
+					WithArgs("category", "shoes", "CAT-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`DELETE FROM slug_redirects WHERE entity_type = \$1 AND slug = \$2`).
+					WithArgs("category", "running-shoes-3").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`UPDATE categories SET name = \$1, slug = \$2 WHERE id = \$3`).
+					WithArgs("Running Shoes", "running-shoes-3", "CAT-1").
+					WillReturnRows(sqlmock.NewRows(categoryColumns).
+						AddRow("CAT-1", "Running Shoes", "running-shoes-3", "", nil, 0, 0, nil, nil))
+				mock.ExpectCommit()
+			},
+			expectedSlug: "running-shoes-3",
+		},
+		{
+			name:    "Renaming Back Reclaims Own Redirect",
+			newName: "Shoes",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT name, slug FROM categories WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs("CAT-1").
+					WillReturnRows(sqlmock.NewRows([]string{"name", "slug"}).AddRow("Running Shoes", "running-shoes-3"))
+				mock.ExpectQuery(`SELECT slug FROM categories WHERE id <> \$2`).
+					WithArgs("shoes", "CAT-1", "category").
+					WillReturnRows(sqlmock.NewRows([]string{"slug"}))
+				mock.ExpectExec(`INSERT INTO slug_redirects`).
+					WithArgs("category", "running-shoes-3", "CAT-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`DELETE FROM slug_redirects WHERE entity_type = \$1 AND slug = \$2`).
+					WithArgs("category", "shoes").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`UPDATE categories SET name = \$1, slug = \$2 WHERE id = \$3`).
+					WithArgs("Shoes", "shoes", "CAT-1").
+					WillReturnRows(sqlmock.NewRows(categoryColumns).
+						AddRow("CAT-1", "Shoes", "shoes", "", nil, 0, 0, nil, nil))
+				mock.ExpectCommit()
+			},
+			expectedSlug: "shoes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			name := tt.newName
+			result, err := repo.UpdateCategory(context.Background(), &request.UpdateCategoryRequest{ID: "CAT-1", Name: &name})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedSlug, result.Slug)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetCategoryBySlug(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := category.NewCategoryRepository(sqlx.NewDb(db, "sqlmock"))
+
+	tests := []struct {
+		name               string
+		slug               string
+		rows               *sqlmock.Rows
+		expectedError      bool
+		expectedRedirected bool
+	}{
+		{
+			name:               "Current Slug",
+			slug:               "shoes",
+			rows:               sqlmock.NewRows(categoryColumns).AddRow("CAT-1", "Shoes", "shoes", "", nil, 0, 0, nil, nil),
+			expectedRedirected: false,
+		},
+		{
+			name:               "Old Slug Redirects",
+			slug:               "footwear",
+			rows:               sqlmock.NewRows(categoryColumns).AddRow("CAT-1", "Shoes", "shoes", "", nil, 0, 0, nil, nil),
+			expectedRedirected: true,
+		},
+		{
+			name:          "Unknown Slug",
+			slug:          "missing",
+			rows:          sqlmock.NewRows(categoryColumns),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectQuery(`SELECT entity_id FROM slug_redirects WHERE entity_type = \$2 AND slug = \$1`).
+				WithArgs(tt.slug, "category").
+				WillReturnRows(tt.rows)
+
+			result, err := repo.GetCategoryBySlug(context.Background(), &request.GetCategoryBySlugRequest{Slug: tt.slug})
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "shoes", result.CanonicalSlug)
+				assert.Equal(t, tt.expectedRedirected, result.Redirected)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
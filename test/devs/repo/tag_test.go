@@ -0,0 +1,86 @@
+package inventoryrepo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	productRepository "github.com/wafi04/backend/services/product/repository"
+)
+
+func TestListProductsTagFilter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := &productRepository.Database{DB: sqlx.NewDb(db, "sqlmock")}
+
+	tests := []struct {
+		name        string
+		tags        []string
+		query       string
+		expectedArg string
+	}{
+		{name: "No Tags Skips The Filter", tags: nil, expectedArg: "{}"},
+		{name: "Display Names Match Stored Slugs", tags: []string{"Summer Sale", "NEW"}, expectedArg: `{"summer-sale","new"}`},
+		{name: "Text Search Is Passed Through", tags: nil, query: "linen", expectedArg: "{}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectQuery(`cardinality\(\$6::VARCHAR\[\]\) = 0 OR EXISTS .* t.slug = ANY\(\$6\)`).
+				WithArgs(int32(10), "0", types.ProductStatusPublished, "", "", tt.expectedArg, tt.query).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+			_, err := repo.ListProducts(context.Background(), &request.ListProductsRequest{
+				PageSize: 10,
+				Status:   types.ProductStatusPublished,
+				Tags:     tt.tags,
+				Query:    tt.query,
+			})
+
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAddProductTags(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := &productRepository.Database{DB: sqlx.NewDb(db, "sqlmock")}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM products WHERE id = \$1 AND deleted_at IS NULL\)`).
+		WithArgs("PROD-1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`INSERT INTO tags .* ON CONFLICT \(slug\)`).
+		WithArgs(sqlmock.AnyArg(), "Summer Sale", "summer-sale").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("TAG-1"))
+	mock.ExpectExec(`INSERT INTO product_tags`).
+		WithArgs("PROD-1", "TAG-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`FROM product_tags pt JOIN tags t`).
+		WithArgs("PROD-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug"}).AddRow("TAG-1", "Summer Sale", "summer-sale"))
+	mock.ExpectCommit()
+
+	res, err := repo.AddProductTags(context.Background(), &request.AddProductTagsRequest{
+		ProductID: "PROD-1",
+		Tags:      []string{"Summer Sale"},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, res.Tags, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,152 @@
+package productservice_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	productRepository "github.com/wafi04/backend/services/product/repository"
+	productservice "github.com/wafi04/backend/services/product/service"
+)
+
+func TestImportCatalogStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	service := productservice.NewProductService(&productRepository.Database{DB: sqlx.NewDb(db, "sqlmock")})
+
+	tests := []struct {
+		name          string
+		input         string
+		mockBehavior  func()
+		expectedError string
+	}{
+		{
+			name:          "Scheduled Needs Publish At",
+			input:         `[{"sku":"TEE-1","name":"Tee","price":20,"category_id":"CAT-1","status":"scheduled"}]`,
+			mockBehavior:  func() {},
+			expectedError: "publish_at is required for scheduled products",
+		},
+		{
+			name:  "Published Cannot Be Scheduled",
+			input: `[{"sku":"TEE-1","name":"Tee","price":20,"category_id":"CAT-1","status":"scheduled","publish_at":4102444800}]`,
+			mockBehavior: func() {
+				mock.ExpectQuery(`SELECT status FROM products WHERE sku = \$1`).
+					WithArgs("TEE-1").
+					WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(types.ProductStatusPublished))
+			},
+			expectedError: "invalid status transition from published to scheduled",
+		},
+		{
+			name:  "Archived Cannot Be Published",
+			input: `[{"sku":"TEE-1","name":"Tee","price":20,"category_id":"CAT-1","status":"published"}]`,
+			mockBehavior: func() {
+				mock.ExpectQuery(`SELECT status FROM products WHERE sku = \$1`).
+					WithArgs("TEE-1").
+					WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(types.ProductStatusArchived))
+			},
+			expectedError: "invalid status transition from archived to published",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectExec(`INSERT INTO import_jobs`).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(`SELECT id FROM categories WHERE id = ANY\(\$1\)`).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("CAT-1"))
+			tt.mockBehavior()
+			mock.ExpectExec(`UPDATE import_jobs`).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			job, err := service.ImportCatalog(context.Background(), &request.ImportCatalogRequest{
+				Format: types.CatalogFormatJSON,
+				Wait:   true,
+			}, strings.NewReader(tt.input))
+
+			assert.NoError(t, err)
+			assert.Equal(t, 1, job.Failed)
+			if assert.Len(t, job.Errors, 1) {
+				assert.Equal(t, tt.expectedError, job.Errors[0].Message)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestParseCatalogCSV(t *testing.T) {
+	header := "product_sku,name,price,category_id,variant_sku,color,size,stock\n"
+
+	tests := []struct {
+		name           string
+		input          string
+		expectedSKUs   []string
+		expectedErrors []types.ImportRowError
+		expectedError  string
+	}{
+		{
+			name: "Product Over Several Rows",
+			input: header +
+				"TEE-1,Tee,20,CAT-1,TEE-1-BLK,black,S,3\n" +
+				"TEE-1,Tee,20,CAT-1,TEE-1-BLK,black,M,5\n" +
+				"TEE-1,Tee,20,CAT-1,TEE-1-WHT,white,M,2\n",
+			expectedSKUs: []string{"TEE-1"},
+		},
+		{
+			name: "Bad Row Drops Whole Product",
+			input: header +
+				"TEE-1,Tee,20,CAT-1,TEE-1-BLK,black,S,3\n" +
+				"TEE-1,Tee,20,CAT-1,TEE-1-BLK,black,M,many\n" +
+				"TEE-1,Tee,20,CAT-1,TEE-1-WHT,white,M,2\n" +
+				"CAP-1,Cap,10,CAT-1,CAP-1-BLK,black,OS,4\n",
+			expectedSKUs: []string{"CAP-1"},
+			expectedErrors: []types.ImportRowError{
+				{Row: 3, SKU: "TEE-1", Message: "invalid stock"},
+			},
+		},
+		{
+			name: "Bad First Row Drops Later Rows",
+			input: header +
+				"TEE-1,Tee,free,CAT-1,TEE-1-BLK,black,S,3\n" +
+				"TEE-1,Tee,20,CAT-1,TEE-1-BLK,black,M,5\n",
+			expectedSKUs: []string{},
+			expectedErrors: []types.ImportRowError{
+				{Row: 2, SKU: "TEE-1", Message: "invalid price"},
+			},
+		},
+		{
+			name: "Unreadable Row Fails File",
+			input: header +
+				"TEE-1,Tee,20,CAT-1,TEE-1-BLK,black,S,3\n" +
+				"TEE-1,\"Tee,20,CAT-1,TEE-1-BLK,black,M,5\n",
+			expectedError: "failed to read csv row 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			products, errs, err := productservice.ParseCatalog(types.CatalogFormatCSV, strings.NewReader(tt.input))
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedErrors, errs)
+
+			skus := []string{}
+			for _, p := range products {
+				skus = append(skus, p.SKU)
+			}
+			assert.Equal(t, tt.expectedSKUs, skus)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package productservice_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/wafi04/backend/pkg/types"
+	productRepository "github.com/wafi04/backend/services/product/repository"
+	productservice "github.com/wafi04/backend/services/product/service"
+)
+
+func TestLowestPriorPrice(t *testing.T) {
+	day := int64(24 * time.Hour / time.Second)
+	now := int64(100 * day)
+	at := func(d int64) *int64 {
+		v := now - d*day
+		return &v
+	}
+
+	tests := []struct {
+		name     string
+		history  []*types.PriceHistoryEntry
+		expected float64
+	}{
+		{
+			name: "Only Current Price",
+			history: []*types.PriceHistoryEntry{
+				{NewPrice: 100, EffectiveFrom: now},
+			},
+			expected: 100,
+		},
+		{
+			name: "Sale After Stable Price",
+			history: []*types.PriceHistoryEntry{
+				{NewPrice: 70, EffectiveFrom: now},
+				{NewPrice: 100, EffectiveFrom: *at(90), EffectiveTo: at(0)},
+			},
+			expected: 100,
+		},
+		{
+			name: "Earlier Dip Inside Window",
+			history: []*types.PriceHistoryEntry{
+				{NewPrice: 70, EffectiveFrom: now},
+				{NewPrice: 100, EffectiveFrom: *at(10), EffectiveTo: at(0)},
+				{NewPrice: 80, EffectiveFrom: *at(20), EffectiveTo: at(10)},
+				{NewPrice: 100, EffectiveFrom: *at(90), EffectiveTo: at(20)},
+			},
+			expected: 80,
+		},
+		{
+			name: "Dip Outside Window Ignored",
+			history: []*types.PriceHistoryEntry{
+				{NewPrice: 70, EffectiveFrom: now},
+				{NewPrice: 100, EffectiveFrom: *at(35), EffectiveTo: at(0)},
+				{NewPrice: 50, EffectiveFrom: *at(60), EffectiveTo: at(35)},
+			},
+			expected: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, productservice.LowestPriorPrice(tt.history, 30*24*time.Hour))
+		})
+	}
+}
+
+func TestApplyPriceScheduleBackToBackSales(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	service := productservice.NewProductService(&productRepository.Database{DB: sqlx.NewDb(db, "sqlmock")})
+
+	columns := []string{"id", "product_id", "variant_id", "price", "starts_at", "ends_at",
+		"revert_price", "status", "created_by", "created_at"}
+	boundary := time.Now().Add(-time.Minute)
+	later := boundary.Add(24 * time.Hour)
+	expectPriceUpdate := func(oldPrice, newPrice float64) {
+		mock.ExpectQuery(`SELECT price FROM products WHERE id = \$1 FOR UPDATE`).
+			WithArgs("PROD-1").
+			WillReturnRows(sqlmock.NewRows([]string{"price"}).AddRow(oldPrice))
+		mock.ExpectQuery(`UPDATE products SET price = \$1 WHERE id = \$2 RETURNING price`).
+			WithArgs(newPrice, "PROD-1").
+			WillReturnRows(sqlmock.NewRows([]string{"price"}).AddRow(newPrice))
+		mock.ExpectExec(`UPDATE price_history`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO price_history`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	mock.ExpectBegin()
+
+	// The first sale ends and restores the regular price...
+	mock.ExpectQuery(`FROM scheduled_price_changes WHERE status = 'active' AND ends_at <= \$1`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("SPC-1", "PROD-1", nil, 80.0, boundary.Add(-24*time.Hour), boundary, 100.0, types.PriceScheduleActive, "USR-1", boundary))
+	expectPriceUpdate(80, 100)
+	mock.ExpectExec(`UPDATE scheduled_price_changes SET status = \$1 WHERE id = \$2`).
+		WithArgs(types.PriceScheduleCompleted, "SPC-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// ...before the next one starts, so it reverts to the regular price too.
+	mock.ExpectQuery(`FROM scheduled_price_changes WHERE status = 'pending' AND starts_at <= \$1`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("SPC-2", "PROD-1", nil, 70.0, boundary, later, nil, types.PriceSchedulePending, "USR-1", boundary))
+	mock.ExpectQuery(`SELECT price FROM products WHERE id = \$1`).
+		WithArgs("PROD-1").
+		WillReturnRows(sqlmock.NewRows([]string{"price"}).AddRow(100.0))
+	expectPriceUpdate(100, 70)
+	mock.ExpectExec(`UPDATE scheduled_price_changes SET status = \$1, revert_price = \$2 WHERE id = \$3`).
+		WithArgs(types.PriceScheduleActive, 100.0, "SPC-2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectCommit()
+
+	err = service.ApplyPriceSchedule(context.Background())
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
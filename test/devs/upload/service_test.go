@@ -0,0 +1,204 @@
+package upload_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/backend/pkg/types"
+	request "github.com/wafi04/backend/pkg/types/req"
+	response "github.com/wafi04/backend/pkg/types/res"
+	"github.com/wafi04/backend/services/files"
+	"github.com/wafi04/backend/services/upload"
+)
+
+// memoryIntents keeps intents in a map. stale is what ListStaleIntents
+// returns, as the sweep query would have selected it.
+type memoryIntents struct {
+	intents map[string]*types.UploadIntent
+	stale   []*types.UploadIntent
+	swept   map[string]bool
+}
+
+func (r *memoryIntents) CreateIntent(ctx context.Context, intent *types.UploadIntent) error {
+	r.intents[intent.ID] = intent
+	return nil
+}
+
+func (r *memoryIntents) GetIntent(ctx context.Context, id string) (*types.UploadIntent, error) {
+	intent, ok := r.intents[id]
+	if !ok {
+		return nil, errors.New("upload intent not found")
+	}
+	copied := *intent
+	return &copied, nil
+}
+
+func (r *memoryIntents) SetIntentStatus(ctx context.Context, id, from, to string) (bool, error) {
+	intent, ok := r.intents[id]
+	if !ok || intent.Status != from {
+		return false, nil
+	}
+	intent.Status = to
+	return true, nil
+}
+
+func (r *memoryIntents) ListStaleIntents(ctx context.Context, before time.Time, limit int) ([]*types.UploadIntent, error) {
+	return r.stale, nil
+}
+
+func (r *memoryIntents) MarkIntentSwept(ctx context.Context, id string) error {
+	r.swept[id] = true
+	return nil
+}
+
+// noAssets is a media repository that has never seen any file.
+type noAssets struct{}
+
+func (noAssets) FindByHash(ctx context.Context, hash string) (*types.MediaAsset, error) {
+	return nil, nil
+}
+
+func (noAssets) CreateAsset(ctx context.Context, asset *types.MediaAsset) (*types.MediaAsset, error) {
+	return asset, nil
+}
+
+func (noAssets) SyncReferences(ctx context.Context) (int64, error) { return 0, nil }
+
+func (noAssets) ListUnreferenced(ctx context.Context, before time.Time, limit int) ([]*types.MediaAsset, error) {
+	return nil, nil
+}
+
+func (noAssets) DeleteAsset(ctx context.Context, id string, before time.Time) (bool, error) {
+	return false, nil
+}
+
+// failingUploads serves direct uploads from disk but cannot store files
+// itself.
+type failingUploads struct {
+	*files.LocalStorage
+}
+
+func (failingUploads) UploadFile(ctx context.Context, req *request.FileUploadRequest) (*response.FileUploadResponse, error) {
+	return nil, errors.New("storage unavailable")
+}
+
+func intent(id, status string) *types.UploadIntent {
+	return &types.UploadIntent{
+		ID:          id,
+		UserID:      "user-1",
+		FileType:    int32(request.IMAGE),
+		ObjectKey:   "products/" + id + ".jpg",
+		ContentType: "image/jpeg",
+		MaxBytes:    1 << 20,
+		Status:      status,
+		ExpiresAt:   time.Now().Add(-2 * time.Hour),
+	}
+}
+
+func putObject(t *testing.T, dir, key string, data []byte) {
+	target := filepath.Join(dir, filepath.FromSlash(key))
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0755))
+	require.NoError(t, os.WriteFile(target, data, 0644))
+}
+
+func TestExpireIntents(t *testing.T) {
+	dir := t.TempDir()
+	storage, err := files.NewLocalStorage(dir, "", "secret")
+	require.NoError(t, err)
+
+	abandoned := intent("abandoned", types.UploadStatusPending)
+	rejected := intent("rejected", types.UploadStatusRejected)
+	raced := intent("raced", types.UploadStatusPending)
+	repo := &memoryIntents{
+		intents: map[string]*types.UploadIntent{
+			"abandoned": abandoned,
+			"rejected":  rejected,
+			// Confirmed after the sweep listed it as pending.
+			"raced": intent("raced", types.UploadStatusConfirmed),
+		},
+		stale: []*types.UploadIntent{abandoned, rejected, raced},
+		swept: map[string]bool{},
+	}
+	for _, i := range repo.stale {
+		putObject(t, dir, i.ObjectKey, []byte("data"))
+	}
+
+	service := upload.NewUploadService(repo, noAssets{}, storage)
+	require.NoError(t, service.ExpireIntents(context.Background()))
+
+	assert.Equal(t, types.UploadStatusExpired, repo.intents["abandoned"].Status)
+	assert.Equal(t, map[string]bool{"abandoned": true, "rejected": true}, repo.swept)
+
+	_, err = os.Stat(filepath.Join(dir, "products", "abandoned.jpg"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dir, "products", "rejected.jpg"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dir, "products", "raced.jpg"))
+	assert.NoError(t, err)
+}
+
+func TestConfirmUploadReleasesIntentWhenCleanedFileIsNotStored(t *testing.T) {
+	dir := t.TempDir()
+	local, err := files.NewLocalStorage(dir, "", "secret")
+	require.NoError(t, err)
+
+	// A JPEG with an EXIF block, so validation changes the bytes and the
+	// cleaned file has to be stored again.
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 100, 100)), nil))
+	payload := []byte("Exif\x00\x00GPS-SECRET")
+	data := append([]byte{0xFF, 0xD8, 0xFF, 0xE1, 0, byte(len(payload) + 2)}, payload...)
+	data = append(data, buf.Bytes()[2:]...)
+
+	pending := intent("photo", types.UploadStatusPending)
+	putObject(t, dir, pending.ObjectKey, data)
+	repo := &memoryIntents{
+		intents: map[string]*types.UploadIntent{"photo": pending},
+		swept:   map[string]bool{},
+	}
+
+	service := upload.NewUploadService(repo, noAssets{}, failingUploads{local})
+	_, err = service.ConfirmUpload(context.Background(), "user-1", "photo")
+
+	assert.EqualError(t, err, "failed to store cleaned file: storage unavailable")
+	assert.Equal(t, types.UploadStatusPending, repo.intents["photo"].Status)
+}
+
+func TestConfirmUploadRejectsFileOfAnotherType(t *testing.T) {
+	dir := t.TempDir()
+	storage, err := files.NewLocalStorage(dir, "", "secret")
+	require.NoError(t, err)
+
+	// A valid JPEG confirmed against an intent that declared a PNG.
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 100, 100)), nil))
+
+	pending := intent("photo", types.UploadStatusPending)
+	pending.ObjectKey = "products/photo.png"
+	pending.ContentType = "image/png"
+	putObject(t, dir, pending.ObjectKey, buf.Bytes())
+	repo := &memoryIntents{
+		intents: map[string]*types.UploadIntent{"photo": pending},
+		swept:   map[string]bool{},
+	}
+
+	service := upload.NewUploadService(repo, noAssets{}, storage)
+	_, err = service.ConfirmUpload(context.Background(), "user-1", "photo")
+
+	var validationErr *response.FileValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, response.FileErrorUnsupported, validationErr.Code)
+	assert.Equal(t, "image/jpeg", validationErr.ContentType)
+	assert.Equal(t, types.UploadStatusRejected, repo.intents["photo"].Status)
+	_, err = os.Stat(filepath.Join(dir, "products", "photo.png"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -33,6 +33,7 @@ type ProductImage struct {
 	URL       string `db:"url"`
 	VariantID string `db:"variant_id"`
 	IsMain    bool   `db:"is_main"`
+	Position  int    `db:"position"`
 }
 
 func (d *Database) AddProductImages(variantID string, imageURLs []string) error {
@@ -48,11 +49,12 @@ func (d *Database) AddProductImages(variantID string, imageURLs []string) error
 			URL:       imageURL,
 			VariantID: variantID,
 			IsMain:    i == 0,
+			Position:  i,
 		}
 
 		_, err := tx.NamedExec(`
-            INSERT INTO product_images (id, url, variant_id, is_main)
-            VALUES (:id, :url, :variant_id, :is_main)
+            INSERT INTO product_images (id, url, variant_id, is_main, position)
+            VALUES (:id, :url, :variant_id, :is_main, :position)
         `, image)
 
 		if err != nil {